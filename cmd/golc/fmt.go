@@ -0,0 +1,87 @@
+/*
+ * golc fmt: canonical formatting of source files (see
+ * syntax.FormatSource()), so that shared ones stay consistent.
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mbivert/golc/syntax"
+)
+
+func fmtUsage(fs *flag.FlagSet, stderr io.Writer) {
+	fmt.Fprintf(stderr, "usage: golc fmt [-w] [-width n] [-ascii] [file.lc ...]\n")
+	fs.PrintDefaults()
+}
+
+// Format the given files (stdin if none) on stdout, or
+// in place (-w).
+func runFmt(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("golc fmt", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	write := fs.Bool("w", false, "write the result to the files instead of stdout")
+	width := fs.Int("width", 80, "break lines longer than n columns, where possible (0: no limit)")
+	ascii := fs.Bool("ascii", false, "use the ASCII syntax")
+
+	fs.Usage = func() { fmtUsage(fs, stderr) }
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if *write && fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "golc fmt: -w needs files")
+		fmtUsage(fs, stderr)
+		return exitUsage
+	}
+
+	style := syntax.UnicodeStyle
+	if *ascii {
+		style = syntax.ASCIIStyle
+	}
+
+	fns := fs.Args()
+	if len(fns) == 0 {
+		fns = []string{"-"}
+	}
+
+	// keep going on errors; the last one is reported
+	code := exitOk
+	for _, fn := range fns {
+		var src []byte
+		var err error
+		if fn == "-" {
+			src, err = io.ReadAll(stdin)
+		} else {
+			src, err = os.ReadFile(fn)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, syntax.ToDiagnostics(err, ""))
+			code = exitIO
+			continue
+		}
+
+		s, err := syntax.FormatSource(string(src), fn, style, *width)
+		if err != nil {
+			fmt.Fprintln(stderr, syntax.ToDiagnostics(err, fn))
+			code = exitParse
+			continue
+		}
+
+		if !*write {
+			fmt.Fprint(stdout, s)
+		} else if s != string(src) {
+			if err := os.WriteFile(fn, []byte(s), 0644); err != nil {
+				fmt.Fprintln(stderr, syntax.ToDiagnostics(err, ""))
+				code = exitIO
+			}
+		}
+	}
+
+	return code
+}
@@ -0,0 +1,259 @@
+/*
+ * Command-line entry point: run the whole pipeline (scanning,
+ * parsing, typing, evaluation) on a source file (see
+ * ../../syntax/program.go), or stdin; golc fmt formats source
+ * files instead (see fmt.go).
+ */
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mbivert/golc/eval"
+	"github.com/mbivert/golc/syntax"
+	"github.com/mbivert/golc/types"
+)
+
+// Exit codes; the point is to be able to tell the failing stage
+// from a shell script/Makefile.
+const (
+	exitOk = iota
+	exitUsage
+	exitIO
+	exitParse
+	exitType
+	exitRuntime
+)
+
+func usage(fs *flag.FlagSet, stderr io.Writer) {
+	fmt.Fprintf(stderr, "usage: golc [-tokens] [-ast] [-type] [-eval] [-untyped] [-strict] [-strategy s] [-engine e] [-max-steps n] [-timeout d] [-stats] [-json] [-ascii] [-i] [file.lc|-]\n")
+	fmt.Fprintf(stderr, "       golc fmt [-w] [-width n] [-ascii] [file.lc ...]\n")
+	fs.PrintDefaults()
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "fmt" {
+		return runFmt(args[1:], stdin, stdout, stderr)
+	}
+
+	fs := flag.NewFlagSet("golc", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	tokens := fs.Bool("tokens", false, "dump the scanned tokens")
+	ast := fs.Bool("ast", false, "dump the parsed expression")
+	typ := fs.Bool("type", false, "dump the expression's type")
+	evaluate := fs.Bool("eval", false, "dump the expression's normal form")
+	untyped := fs.Bool("untyped", false, "skip type checking")
+	strict := fs.Bool("strict", false, "strict linearity: variables not of a !-type are used exactly once")
+	interactive := fs.Bool("i", false, "start a REPL, after loading file.lc if any")
+	strategy := fs.String("strategy", "",
+		"reduction strategy: normal, applicative, cbn, cbv, head, whnf\n"+
+			"(default: applicative with -engine cek, normal otherwise)")
+	engine := fs.String("engine", "subst", "evaluation engine: subst, debruijn, cek, krivine, need")
+	stats := fs.Bool("stats", false, "print evaluation statistics on stderr")
+	maxSteps := fs.Int("max-steps", 0, "give up evaluation after n reduction steps (0: no limit, 1000 in the REPL)")
+	timeout := fs.Duration("timeout", 0, "give up evaluation after d (0: no limit)")
+	jsonDiags := fs.Bool("json", false, "report errors as JSON diagnostics, one per line")
+	ascii := fs.Bool("ascii", false, "print expressions and types with the ASCII syntax")
+
+	fs.Usage = func() { usage(fs, stderr) }
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if fs.NArg() > 1 {
+		usage(fs, stderr)
+		return exitUsage
+	}
+
+	g, err := eval.ParseEngine(*engine)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		usage(fs, stderr)
+		return exitUsage
+	}
+
+	// Machine engines only implement one strategy
+	st, fixed := g.Strategy()
+	if *strategy != "" {
+		s, err := eval.ParseStrategy(*strategy)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			usage(fs, stderr)
+			return exitUsage
+		}
+		if fixed && s != st {
+			fmt.Fprintf(stderr, "The %s engine only implements the %s strategy, not %s\n", g, st, s)
+			usage(fs, stderr)
+			return exitUsage
+		}
+		st = s
+	}
+
+	style := syntax.UnicodeStyle
+	if *ascii {
+		style = syntax.ASCIIStyle
+	}
+
+	fn := "-"
+	if fs.NArg() == 1 {
+		fn = fs.Arg(0)
+	}
+
+	// Report err on stderr; errors which aren't diagnostics
+	// are attributed to file (if not empty).
+	report := func(err error, file string) {
+		ds := syntax.ToDiagnostics(err, file)
+		if *jsonDiags {
+			printDiagnostics(stderr, ds)
+		} else {
+			fmt.Fprintln(stderr, ds)
+		}
+	}
+
+	mode := types.ImplicitLinearity
+	if *strict {
+		mode = types.StrictLinearity
+	}
+
+	if *interactive {
+		if fn == "-" {
+			fn = ""
+		}
+		runRepl(stdin, stdout, fn, replOptions{style, *untyped, mode, *maxSteps})
+		return exitOk
+	}
+
+	var src []byte
+	if fn == "-" {
+		src, err = io.ReadAll(stdin)
+	} else {
+		src, err = os.ReadFile(fn)
+	}
+	if err != nil {
+		report(err, "")
+		return exitIO
+	}
+
+	// No stage selected: print the normal form and its type
+	all := !*tokens && !*ast && !*typ && !*evaluate
+	if all {
+		*evaluate = true
+		*typ = !*untyped
+	}
+
+	if *tokens {
+		toks, err := syntax.Scan(string(src), fn)
+		if err != nil {
+			report(err, fn)
+			return exitParse
+		}
+		printTokens(stdout, toks)
+		if !*ast && !*typ && !*evaluate {
+			return exitOk
+		}
+	}
+
+	q, err := syntax.ParseProgram(string(src), fn)
+	if err != nil {
+		report(err, fn)
+		return exitParse
+	}
+
+	if *ast {
+		printProgram(stdout, q, style)
+	}
+
+	var t syntax.Type
+	if !*untyped && (*typ || *evaluate) {
+		if t, err = types.CheckProgramWith(q, mode); err != nil {
+			report(err, fn)
+			return exitType
+		}
+	}
+
+	// Nothing to evaluate (library): dump the declarations' types
+	if q.Main == nil {
+		if *typ && !*untyped {
+			for _, d := range q.Decls {
+				fmt.Fprintf(stdout, "%s : %s\n", d.Name, syntax.FormatType(d.T, style))
+			}
+		}
+		return exitOk
+	}
+
+	if *evaluate {
+		opts := eval.EvalOptions{Strategy: st, Engine: g, MaxSteps: *maxSteps}
+		if *timeout > 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+			defer cancel()
+			opts.Context = ctx
+		}
+		var st eval.EvalStats
+		opts.Stats = &st
+		y, err := eval.EvalWith(q.Bind(q.Main), opts)
+		if *stats {
+			fmt.Fprintf(stderr, "%d steps (%d saved by sharing)\n", st.Steps, st.Saved)
+		}
+		if err != nil {
+			report(runtimeError(err), fn)
+			return exitRuntime
+		}
+		if *typ && t != nil {
+			fmt.Fprintf(stdout, "%s : %s\n", syntax.Format(y, style), syntax.FormatType(t, style))
+		} else {
+			fmt.Fprintln(stdout, syntax.Format(y, style))
+		}
+	} else if *typ && t != nil {
+		fmt.Fprintln(stdout, syntax.FormatType(t, style))
+	}
+
+	return exitOk
+}
+
+// δ-reduction errors already say they're runtime errors
+func runtimeError(err error) error {
+	var le *eval.LimitError
+	if errors.As(err, &le) {
+		return fmt.Errorf("runtime error: %w", err)
+	}
+	return err
+}
+
+func printProgram(w io.Writer, q *syntax.Program, style syntax.Style) {
+	for _, d := range q.Decls {
+		x := syntax.Format(d.X, style)
+		if d.T == nil {
+			fmt.Fprintf(w, "let %s = %s;\n", d.Name, x)
+		} else {
+			fmt.Fprintf(w, "let %s = %s : %s;\n", d.Name, x, syntax.FormatType(d.T, style))
+		}
+	}
+	if q.Main != nil {
+		fmt.Fprintln(w, syntax.Format(q.Main, style))
+	}
+}
+
+func printDiagnostics(w io.Writer, ds syntax.Diagnostics) {
+	enc := json.NewEncoder(w)
+	for _, d := range ds {
+		enc.Encode(d)
+	}
+}
+
+func printTokens(w io.Writer, toks []syntax.Token) {
+	for _, tok := range toks {
+		fmt.Fprintf(w, "%d:%d\t%s\t%q\n", tok.Ln, tok.Cn, tok.Kind, tok.Raw)
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
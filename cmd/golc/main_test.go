@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mbivert/ftests"
+)
+
+// run() wrapper, so that ftests can compare all the outputs
+func runStr(args []string, stdin string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	n := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return n, stdout.String(), stderr.String()
+}
+
+func TestMainRun(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"default: normal form and type",
+			runStr,
+			[]any{[]string{}, "(λx:int. x + 3) 4"},
+			[]any{exitOk, "7 : int\n", ""},
+		},
+		{
+			"stdin as -",
+			runStr,
+			[]any{[]string{"-eval", "-"}, "(λx:int. x + 3) 4"},
+			[]any{exitOk, "7\n", ""},
+		},
+		{
+			"type only",
+			runStr,
+			[]any{[]string{"-type"}, "λx:int. x + 3"},
+			[]any{exitOk, "int → int\n", ""},
+		},
+		{
+			"tokens only",
+			runStr,
+			[]any{[]string{"-tokens"}, "λx. x"},
+			[]any{exitOk, "1:1\tλ\t\"λ\"\n1:2\tname\t\"x\"\n" +
+				"1:3\t.\t\".\"\n1:5\tname\t\"x\"\n1:6\tEOF\t\"\"\n", ""},
+		},
+		{
+			"untyped evaluation",
+			runStr,
+			[]any{[]string{"-untyped"}, "(λx. λy. x) a b"},
+			[]any{exitOk, "a\n", ""},
+		},
+		{
+			"too many arguments",
+			runStr,
+			[]any{[]string{"a", "b"}, ""},
+			[]any{exitUsage, "", "usage: golc [-tokens] [-ast] [-type] [-eval] [-untyped] [-strict] [-strategy s] [-engine e] [-max-steps n] [-timeout d] [-stats] [-json] [-ascii] [-i] [file.lc|-]\n" +
+				"       golc fmt [-w] [-width n] [-ascii] [file.lc ...]\n" +
+				"  -ascii\n    \tprint expressions and types with the ASCII syntax\n" +
+				"  -ast\n    \tdump the parsed expression\n" +
+				"  -engine string\n    \tevaluation engine: subst, debruijn, cek, krivine, need (default \"subst\")\n" +
+				"  -eval\n    \tdump the expression's normal form\n" +
+				"  -i\tstart a REPL, after loading file.lc if any\n" +
+				"  -json\n    \treport errors as JSON diagnostics, one per line\n" +
+				"  -max-steps int\n    \tgive up evaluation after n reduction steps (0: no limit, 1000 in the REPL)\n" +
+				"  -stats\n    \tprint evaluation statistics on stderr\n" +
+				"  -strategy string\n    \treduction strategy: normal, applicative, cbn, cbv, head, whnf\n" +
+				"    \t(default: applicative with -engine cek, normal otherwise)\n" +
+				"  -strict\n    \tstrict linearity: variables not of a !-type are used exactly once\n" +
+				"  -timeout duration\n    \tgive up evaluation after d (0: no limit)\n" +
+				"  -tokens\n    \tdump the scanned tokens\n" +
+				"  -type\n    \tdump the expression's type\n" +
+				"  -untyped\n    \tskip type checking\n"},
+		},
+		{
+			"missing file",
+			runStr,
+			[]any{[]string{"/nonexistent.lc"}, ""},
+			[]any{exitIO, "", "open /nonexistent.lc: no such file or directory\n"},
+		},
+		{
+			"type error",
+			runStr,
+			[]any{[]string{}, "λx:int. x + true"},
+			[]any{exitType, "", "-:1:9: + : (int×int) → int; got (int×bool)\n"},
+		},
+		{
+			"ASCII syntax, ASCII output",
+			runStr,
+			[]any{[]string{"-ascii"}, "(\\f:int -> bool & bool. f 1) (\\x:int. <<x >= 0, true>>)"},
+			[]any{exitOk, "<<true, true>> : bool & bool\n", ""},
+		},
+		{
+			"ASCII output, AST",
+			runStr,
+			[]any{[]string{"-ascii", "-ast", "-untyped"}, "λx:int → int. 〈x, 1 ≤ 2〉"},
+			[]any{exitOk, "\\x:int -> int. <<x, 1 <= 2>>\n", ""},
+		},
+		{
+			"numeric literals",
+			runStr,
+			[]any{[]string{}, "〈0xff + 1_000, 1.5e-3 *. 2.〉"},
+			[]any{exitOk, "〈1255, 0.003〉 : int × float\n", ""},
+		},
+		{
+			"let 〈...〉",
+			runStr,
+			[]any{[]string{}, "let swap = λp:int × bool. let 〈x, y〉 = p in 〈y, x〉;\nswap 〈1, true〉"},
+			[]any{exitOk, "〈true, 1〉 : bool × int\n", ""},
+		},
+		{
+			"if/then/else",
+			runStr,
+			[]any{[]string{}, "let abs = λx:int. if x < 0 then -x else x;\n〈abs (-3), abs 4〉"},
+			[]any{exitOk, "〈3, 4〉 : int × int\n", ""},
+		},
+		{
+			"if, ill-typed condition",
+			runStr,
+			[]any{[]string{}, "if 1 then 2 else 3"},
+			[]any{exitType, "", "-:1:4: if's condition should be 'bool', got 'int'\n"},
+		},
+		{
+			"sums",
+			runStr,
+			[]any{[]string{}, "let f = λs:int ⊕ bool. match s with inl n → n | inr b → if b then 1 else 0;\n〈f (inl 3), f (inr true)〉"},
+			[]any{exitOk, "〈3, 1〉 : int × int\n", ""},
+		},
+		{
+			"fmt",
+			runStr,
+			[]any{[]string{"fmt", "-width", "26"}, "let two=λf. λx. f (f x); # 2\nlet id = λx. x;\n(λf. λg. λx. f (g x)) two two"},
+			[]any{exitOk, "let two = λf. λx. f (f x); # 2\nlet id  = λx. x;\n(λf. λg. λx. f (g x))\n  two\n  two\n", ""},
+		},
+		{
+			"fmt, ASCII, syntax error",
+			runStr,
+			[]any{[]string{"fmt", "-ascii"}, "λx. 〈x, x"},
+			[]any{exitParse, "", "-:1:10: Unexpected token: EOF\n"},
+		},
+		{
+			"out of range literal",
+			runStr,
+			[]any{[]string{}, "1 + 99999999999999999999"},
+			[]any{exitParse, "", "-:1:5: Integer literal out of range: 99999999999999999999\n"},
+		},
+		{
+			"runtime error",
+			runStr,
+			[]any{[]string{"-untyped"}, "(λx. x + 1) true"},
+			[]any{exitRuntime, "", "-:1:6: runtime error: + expects int operands, got 'true'\n"},
+		},
+		{
+			"division by zero",
+			runStr,
+			[]any{[]string{}, "(λx:int. 1 / x) 0"},
+			[]any{exitRuntime, "", "-:1:10: runtime error: division by zero\n"},
+		},
+		{
+			"weak head normal form",
+			runStr,
+			[]any{[]string{"-untyped", "-strategy", "whnf"}, "λx. (λy. y) x"},
+			[]any{exitOk, "λx. (λy. y) x\n", ""},
+		},
+		{
+			"de Bruijn engine",
+			runStr,
+			[]any{[]string{"-untyped", "-engine", "debruijn"}, "(λa. λb. a) b"},
+			[]any{exitOk, "λx. b\n", ""},
+		},
+		{
+			"cek, applicative order by default",
+			runStr,
+			[]any{[]string{"-untyped", "-engine", "cek"}, "(λx. λy. x) y"},
+			[]any{exitOk, "λx0. y\n", ""},
+		},
+		{
+			"krivine, unsupported strategy",
+			func(args []string, s string) (int, string) {
+				n, out, errs := runStr(args, s)
+				return n, out + strings.SplitN(errs, "\n", 2)[0]
+			},
+			[]any{[]string{"-engine", "krivine", "-strategy", "cbv"}, "1"},
+			[]any{exitUsage, "The krivine engine only implements the normal strategy, not cbv"},
+		},
+		{
+			"call-by-need, statistics",
+			runStr,
+			[]any{[]string{"-engine", "need", "-stats"}, "(λx:int. x * x) (2 + 3)"},
+			[]any{exitOk, "25 : int\n", "3 steps (1 saved by sharing)\n"},
+		},
+		{
+			"program",
+			runStr,
+			[]any{[]string{}, "# increment\nlet inc = λx:int. x + 1;\ninc (inc 1)"},
+			[]any{exitOk, "3 : int\n", ""},
+		},
+		{
+			"program, ast",
+			runStr,
+			[]any{[]string{"-ast"}, "let x = 1 : int;\nx"},
+			[]any{exitOk, "let x = 1 : int;\nx\n", ""},
+		},
+		{
+			"library, types",
+			runStr,
+			[]any{[]string{"-type"}, "let one = 1;\nlet inc = λx:int. x + one;\n"},
+			[]any{exitOk, "one : int\ninc : int → int\n", ""},
+		},
+		{
+			"ill-typed declaration",
+			runStr,
+			[]any{[]string{}, "let x = 1 : bool;\nx"},
+			[]any{exitType, "", "-:1:9: Expecting 'bool', got 'int'\n" +
+				"\tnote: in declaration 'x'\n"},
+		},
+		{
+			"strict linearity",
+			runStr,
+			[]any{[]string{"-strict"}, "let two = 2;\nlet sq = λx:!int. x * x;\n(λx:int. x + 1) ((sq two) + two)"},
+			[]any{exitOk, "7 : int\n", ""},
+		},
+		{
+			"strict linearity, duplicated variable",
+			runStr,
+			[]any{[]string{"-strict"}, "let sq = λx:int. x * x;\nsq 2"},
+			[]any{exitType, "", "-:1:22: 'x' is linear ('int'), but used more than once\n" +
+				"\tnote: 1:18: first used here\n" +
+				"\tnote: in declaration 'sq'\n"},
+		},
+		{
+			"several syntax errors",
+			runStr,
+			[]any{[]string{}, "let x = (1 +);\nlet y = λ. y;\nx"},
+			[]any{exitParse, "", "-:1:13: Unexpected token: )\n" +
+				"-:2:10: Expecting variable name after lambda, got: .\n"},
+		},
+		{
+			"syntax errors, JSON",
+			runStr,
+			[]any{[]string{"-json"}, "(1 +)\n〈λ. x〉"},
+			[]any{exitParse, "", `{"severity":"error","file":"-","start":{"line":1,"col":5},` +
+				`"end":{"line":1,"col":6},"message":"Unexpected token: )"}` + "\n" +
+				`{"severity":"error","file":"-","start":{"line":2,"col":3},` +
+				`"end":{"line":2,"col":4},"message":"Expecting variable name after lambda, got: ."}` + "\n"},
+		},
+		{
+			"type error, JSON",
+			runStr,
+			[]any{[]string{"-json"}, "1 + true"},
+			[]any{exitType, "", `{"severity":"error","file":"-","start":{"line":1,"col":1},` +
+				`"end":{"line":1,"col":9},"message":"+ : (int×int) → int; got (int×bool)"}` + "\n"},
+		},
+		{
+			"diverging, max steps",
+			runStr,
+			[]any{[]string{"-untyped", "-max-steps", "5"}, "(λx. x x) (λx. x x)"},
+			[]any{exitRuntime, "", "-: runtime error: evaluation stopped after 5 steps: " +
+				"too many steps\n"},
+		},
+	})
+}
@@ -0,0 +1,389 @@
+/*
+ * Line-oriented REPL, built on top of syntax.Parse(),
+ * types.CheckProgram() and eval.Eval(): inputs are typed as a
+ * program's expression, whose declarations are the definitions
+ * so far.
+ */
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mbivert/golc/eval"
+	"github.com/mbivert/golc/syntax"
+	"github.com/mbivert/golc/types"
+)
+
+const (
+	replPrompt = "λ> "
+	replCont   = ".. "
+
+	// evaluation, :step give up after that many reduction
+	// steps, unless golc -max-steps says otherwise
+	replMaxSteps = 1000
+)
+
+// REPL settings, from golc's flags
+type replOptions struct {
+	style    syntax.Style    // output syntax
+	untyped  bool            // skip type checking
+	mode     types.Linearity // linearity discipline
+	maxSteps int             // 0: replMaxSteps
+}
+
+// a top-level definition: let $name = $x [: $t]; t
+// is nil if unknown.
+type replDef struct {
+	name string
+	x    syntax.Expr
+	t    syntax.Type
+}
+
+type repl struct {
+	replOptions
+
+	defs []replDef
+	out  io.Writer
+
+	// pending (incomplete) input
+	buf strings.Builder
+}
+
+var replHelp = `:type M    print M's type
+:ast M     print M's AST
+:tokens M  print M's tokens
+:step M    print M's reduction, step by step
+:load file load a program file
+:env       list top-level definitions
+:style s   print with the unicode (default) or ascii syntax
+:help      print this help
+:quit      exit
+let x = M  define x as M for later inputs
+M          evaluate M
+`
+
+// Wrap x in the top-level definitions it uses, i.e.
+//
+//	let x0 = M0 in let x1 = M1 in ... x
+//
+// so that it can be evaluated as a regular expression.
+//
+// We work on copies, as evaluation operates in-place.
+func (r *repl) bind(x syntax.Expr) syntax.Expr {
+	fv := syntax.FreeVars(x)
+
+	for i := len(r.defs) - 1; i >= 0; i-- {
+		d := r.defs[i]
+		if !fv[d.name] {
+			continue
+		}
+
+		// d.x refers to previous definitions only
+		delete(fv, d.name)
+		for n := range syntax.FreeVars(d.x) {
+			fv[n] = true
+		}
+
+		var t syntax.Type
+		if d.t != nil {
+			t = syntax.CopyType(d.t)
+		}
+
+		x = syntax.NewLetExpr(d.name, t, syntax.Copy(d.x), x)
+	}
+	return x
+}
+
+// x and t, in the REPL's style
+func (r *repl) expr(x syntax.Expr) string { return syntax.Format(x, r.style) }
+func (r *repl) typ(t syntax.Type) string  { return syntax.FormatType(t, r.style) }
+
+// The top-level definitions, as a program's declarations,
+// followed by x (nil if none). We work on copies, as typing
+// operates in-place.
+func (r *repl) program(x syntax.Expr) *syntax.Program {
+	q := syntax.Program{Main: x}
+	for _, d := range r.defs {
+		var t syntax.Type
+		if d.t != nil {
+			t = syntax.CopyType(d.t)
+		}
+		q.Decls = append(q.Decls, syntax.Decl{d.name, syntax.Copy(d.x), t})
+	}
+	return &q
+}
+
+// type inference on a copy of x, in the context of the
+// definitions
+func (r *repl) typeOf(x syntax.Expr) (syntax.Type, error) {
+	return types.CheckProgramWith(r.program(syntax.Copy(x)), r.mode)
+}
+
+func (r *repl) steps() int {
+	if r.maxSteps > 0 {
+		return r.maxSteps
+	}
+	return replMaxSteps
+}
+
+// input is incomplete if it contains unclosed ( or 〈 (<<)
+func isIncomplete(src string) bool {
+	toks, err := syntax.Scan(src, "")
+	if err != nil {
+		return false
+	}
+
+	n := 0
+	for _, tok := range toks {
+		switch tok.Kind {
+		case syntax.TokenLParen, syntax.TokenLBracket:
+			n++
+		case syntax.TokenRParen, syntax.TokenRBracket:
+			n--
+		}
+	}
+	return n > 0
+}
+
+// Ill-typed definitions are reported, and dropped.
+func (r *repl) def(n string, x syntax.Expr, t syntax.Type) {
+	// x is checked against t, if any; the inferred type
+	// is kept for later inputs.
+	if !r.untyped {
+		q := r.program(nil)
+		if t != nil {
+			t = syntax.CopyType(t)
+		}
+		q.Decls = append(q.Decls, syntax.Decl{n, syntax.Copy(x), t})
+		if _, err := types.CheckProgramWith(q, r.mode); err != nil {
+			fmt.Fprintln(r.out, err)
+			return
+		}
+		t = q.Decls[len(q.Decls)-1].T
+	}
+
+	r.defs = append(r.defs, replDef{n, x, t})
+
+	if t == nil {
+		fmt.Fprintf(r.out, "%s\n", n)
+	} else {
+		fmt.Fprintf(r.out, "%s : %s\n", n, r.typ(t))
+	}
+}
+
+func (r *repl) eval(src string) {
+	n, x, t, err := syntax.ParseDef(src, "")
+	if err != nil {
+		fmt.Fprintln(r.out, err)
+		return
+	}
+	if n != "" {
+		r.def(n, x, t)
+		return
+	}
+
+	if x, err = syntax.Parse(src, ""); err != nil {
+		fmt.Fprintln(r.out, err)
+		return
+	}
+
+	r.evalExpr(x)
+}
+
+// Ill-typed expressions are reported, and not evaluated.
+func (r *repl) evalExpr(x syntax.Expr) {
+	var t syntax.Type
+	if !r.untyped {
+		var err error
+		if t, err = r.typeOf(x); err != nil {
+			fmt.Fprintln(r.out, err)
+			return
+		}
+	}
+
+	y, err := eval.EvalWith(r.bind(x), eval.EvalOptions{MaxSteps: r.steps()})
+	if err != nil {
+		r.runtimeError(err)
+		return
+	}
+
+	if t != nil {
+		fmt.Fprintf(r.out, "%s : %s\n", r.expr(y), r.typ(t))
+	} else {
+		fmt.Fprintln(r.out, r.expr(y))
+	}
+}
+
+func (r *repl) runtimeError(err error) {
+	fmt.Fprintln(r.out, runtimeError(err))
+}
+
+func (r *repl) step(src string) {
+	x, err := syntax.Parse(src, "")
+	if err != nil {
+		fmt.Fprintln(r.out, err)
+		return
+	}
+
+	x = r.bind(x)
+	fmt.Fprintf(r.out, "0: %s\n", r.expr(x))
+
+	stopped := false
+	_, err = eval.EvalTrace(x, func(ev eval.Event) bool {
+		fmt.Fprintln(r.out, ev)
+		stopped = ev.N >= r.steps()
+		return !stopped
+	})
+	if err != nil {
+		r.runtimeError(err)
+	} else if stopped {
+		fmt.Fprintf(r.out, "giving up after %d steps\n", r.steps())
+	}
+}
+
+// Load a program file: its declarations become top-level
+// definitions, and its expression, if any, is evaluated.
+func (r *repl) load(fn string) {
+	src, err := os.ReadFile(fn)
+	if err != nil {
+		fmt.Fprintln(r.out, err)
+		return
+	}
+
+	q, err := syntax.ParseProgram(string(src), fn)
+	if err != nil {
+		fmt.Fprintln(r.out, err)
+		return
+	}
+
+	for _, d := range q.Decls {
+		r.def(d.Name, d.X, d.T)
+	}
+	if q.Main != nil {
+		r.evalExpr(q.Main)
+	}
+}
+
+// Process a meta-command; returns false if we're done.
+func (r *repl) meta(src string) bool {
+	cmd, arg, _ := strings.Cut(src, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch cmd {
+	case ":type":
+		x, err := syntax.Parse(arg, "")
+		if err == nil {
+			var t syntax.Type
+			if t, err = r.typeOf(x); err == nil {
+				fmt.Fprintln(r.out, r.typ(t))
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(r.out, err)
+		}
+
+	case ":ast":
+		x, err := syntax.Parse(arg, "")
+		if err != nil {
+			fmt.Fprintln(r.out, err)
+		} else {
+			fmt.Fprintln(r.out, r.expr(x))
+		}
+
+	case ":tokens":
+		toks, err := syntax.Scan(arg, "")
+		if err != nil {
+			fmt.Fprintln(r.out, err)
+		} else {
+			printTokens(r.out, toks)
+		}
+
+	case ":step":
+		r.step(arg)
+
+	case ":load":
+		r.load(arg)
+
+	case ":env":
+		for _, d := range r.defs {
+			if d.t == nil {
+				fmt.Fprintf(r.out, "%s = %s\n", d.name, r.expr(d.x))
+			} else {
+				fmt.Fprintf(r.out, "%s = %s : %s\n", d.name, r.expr(d.x), r.typ(d.t))
+			}
+		}
+
+	case ":style":
+		switch arg {
+		case "unicode":
+			r.style = syntax.UnicodeStyle
+		case "ascii":
+			r.style = syntax.ASCIIStyle
+		default:
+			fmt.Fprintf(r.out, "unknown style '%s'; try unicode or ascii\n", arg)
+		}
+
+	case ":help":
+		fmt.Fprint(r.out, replHelp)
+
+	case ":quit":
+		return false
+
+	default:
+		fmt.Fprintf(r.out, "unknown command '%s'; try :help\n", cmd)
+	}
+
+	return true
+}
+
+// Process a line of input; returns false if we're done.
+func (r *repl) line(s string) bool {
+	if r.buf.Len() > 0 {
+		r.buf.WriteString("\n")
+	}
+	r.buf.WriteString(s)
+
+	src := r.buf.String()
+	if isIncomplete(src) {
+		return true
+	}
+	r.buf.Reset()
+
+	src = strings.TrimSpace(src)
+
+	switch {
+	case src == "":
+	case strings.HasPrefix(src, ":"):
+		return r.meta(src)
+	default:
+		r.eval(src)
+	}
+
+	return true
+}
+
+// fn, if not empty, is loaded first
+func runRepl(in io.Reader, out io.Writer, fn string, opts replOptions) {
+	r := repl{replOptions: opts, out: out}
+	s := bufio.NewScanner(in)
+
+	if fn != "" {
+		r.load(fn)
+	}
+
+	for {
+		if r.buf.Len() > 0 {
+			fmt.Fprint(out, replCont)
+		} else {
+			fmt.Fprint(out, replPrompt)
+		}
+		if !s.Scan() || !r.line(s.Text()) {
+			break
+		}
+	}
+	fmt.Fprintln(out)
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mbivert/ftests"
+	"github.com/mbivert/golc/syntax"
+	"github.com/mbivert/golc/types"
+)
+
+// runRepl() wrapper, so that ftests can compare the output
+func runReplStr(in string) string {
+	return runReplWith(in, replOptions{syntax.UnicodeStyle, false, types.ImplicitLinearity, 0})
+}
+
+// runReplStr(), with golc -untyped
+func runReplUntyped(in string) string {
+	return runReplWith(in, replOptions{syntax.UnicodeStyle, true, types.ImplicitLinearity, 0})
+}
+
+func runReplWith(in string, opts replOptions) string {
+	var out bytes.Buffer
+	runRepl(strings.NewReader(in), &out, "", opts)
+	return out.String()
+}
+
+func TestReplIsIncomplete(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"empty input",
+			isIncomplete,
+			[]any{""},
+			[]any{false},
+		},
+		{
+			"balanced parentheses",
+			isIncomplete,
+			[]any{"(λx. x) (y)"},
+			[]any{false},
+		},
+		{
+			"unclosed parenthesis",
+			isIncomplete,
+			[]any{"(λx. x"},
+			[]any{true},
+		},
+		{
+			"unclosed bracket",
+			isIncomplete,
+			[]any{"〈1, (2)"},
+			[]any{true},
+		},
+		{
+			"too many closing parenthesis: let the parser complain",
+			isIncomplete,
+			[]any{"x)"},
+			[]any{false},
+		},
+	})
+}
+
+func TestReplRun(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "defs.lc")
+	if err := os.WriteFile(fn, []byte("# numbers\nlet one = 1;\nlet two = (\n\tone + one); /* sum */\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ftests.Run(t, []ftests.Test{
+		{
+			"empty input",
+			runReplStr,
+			[]any{""},
+			[]any{"λ> \n"},
+		},
+		{
+			"evaluation",
+			runReplStr,
+			[]any{"(λx:int. x + 3) 4\n"},
+			[]any{"λ> 7 : int\nλ> \n"},
+		},
+		{
+			"untyped evaluation",
+			runReplUntyped,
+			[]any{"(λx. λy. x) a b\n"},
+			[]any{"λ> a\nλ> \n"},
+		},
+		{
+			"ill-typed expressions aren't evaluated",
+			runReplStr,
+			[]any{"(λx:int. x) true\n"},
+			[]any{"λ> :1:13: Expecting 'int', got 'bool'\n" +
+				"\tnote: applying 'λx:int. x' ('int → int') to 'true'\nλ> \n"},
+		},
+		{
+			"definitions persist",
+			runReplStr,
+			[]any{"let inc = λx:int. x + 1\nlet id = λx:int. x\ninc (id 2)\n"},
+			[]any{"λ> inc : int → int\nλ> id : int → int\nλ> 3 : int\nλ> \n"},
+		},
+		{
+			"ill-typed definitions are dropped",
+			runReplStr,
+			[]any{"let x = 1 + true\nlet y = 1 : bool\n:env\n"},
+			[]any{"λ> :1:9: + : (int×int) → int; got (int×bool)\n\tnote: in declaration 'x'\n" +
+				"λ> :1:9: Expecting 'bool', got 'int'\n\tnote: in declaration 'y'\nλ> λ> \n"},
+		},
+		{
+			"untyped definitions",
+			runReplUntyped,
+			[]any{"let id = λx. x\nid (1 + true)\n"},
+			[]any{"λ> id\nλ> :1:5: runtime error: + expects int operands, got 'true'\nλ> \n"},
+		},
+		{
+			"strict linearity",
+			runReplWith,
+			[]any{"let one = 1\none + one\nλx:int. x + x\n",
+				replOptions{syntax.UnicodeStyle, false, types.StrictLinearity, 0}},
+			[]any{"λ> one : int\nλ> 2 : int\n" +
+				"λ> :1:13: 'x' is linear ('int'), but used more than once\n\tnote: 1:9: first used here\nλ> \n"},
+		},
+		{
+			"redefinitions refer to previous ones",
+			runReplStr,
+			[]any{"let x = 1\nlet x = x + 1\nx\n"},
+			[]any{"λ> x : int\nλ> x : int\nλ> 2 : int\nλ> \n"},
+		},
+		{
+			"let/in isn't a definition",
+			runReplStr,
+			[]any{"let x = 1 in x + 1\n:env\n"},
+			[]any{"λ> 2 : int\nλ> λ> \n"},
+		},
+		{
+			"diverging",
+			runReplUntyped,
+			[]any{"(λx. x x) (λx. x x)\n"},
+			[]any{"λ> runtime error: evaluation stopped after 1000 steps: too many steps\nλ> \n"},
+		},
+		{
+			"diverging, -max-steps",
+			runReplWith,
+			[]any{"(λx. x x) (λx. x x)\n:step (λx. x x) (λx. x x)\n",
+				replOptions{syntax.UnicodeStyle, true, types.ImplicitLinearity, 2}},
+			[]any{"λ> runtime error: evaluation stopped after 2 steps: too many steps\n" +
+				"λ> 0: (λx. x x) (λx. x x)\n" +
+				"1: →β (λx. x x) (λx. x x)  [ε]\n" +
+				"2: →β (λx. x x) (λx. x x)  [ε]\n" +
+				"giving up after 2 steps\nλ> \n"},
+		},
+		{
+			"multi-line input",
+			runReplStr,
+			[]any{"(λx:int.\n\tx + 3) 4\n"},
+			[]any{"λ> .. 7 : int\nλ> \n"},
+		},
+		{
+			":type",
+			runReplStr,
+			[]any{"let inc = λx:int. x + 1\n:type inc\n:type λx. x\n:type 1 + true\n"},
+			[]any{"λ> inc : int → int\nλ> int → int\n" +
+				"λ> :1:1: Can't infer the type of 'x'; annotate it or add an ascription\n" +
+				"λ> :1:1: + : (int×int) → int; got (int×bool)\nλ> \n"},
+		},
+		{
+			":ast, :tokens",
+			runReplStr,
+			[]any{":ast x y\n:tokens x\n"},
+			[]any{"λ> x y\nλ> 1:1\tname\t\"x\"\n1:2\tEOF\t\"\"\nλ> \n"},
+		},
+		{
+			":step",
+			runReplUntyped,
+			[]any{"let id = λx. x\n:step id (id y)\n"},
+			[]any{"λ> id\nλ> 0: let id = λx. x in id (id y)\n" +
+				"1: →β (λx. x) ((λx. x) y)  [ε]\n" +
+				"2: →β (λx. x) y  [ε]\n" +
+				"3: →β y  [ε]\nλ> \n"},
+		},
+		{
+			":load, :env",
+			runReplStr,
+			[]any{":load " + fn + "\n:env\ntwo\n"},
+			[]any{"λ> one : int\ntwo : int\n" +
+				"λ> one = 1 : int\ntwo = one + one : int\n" +
+				"λ> 2 : int\nλ> \n"},
+		},
+		{
+			"errors",
+			runReplStr,
+			[]any{"(λx. x))\nlet x = \n:foo\n"},
+			[]any{"λ> :1:8: Unexpected token: )\n" +
+				"λ> :1:8: Unexpected token: EOF\n" +
+				"λ> unknown command ':foo'; try :help\nλ> \n"},
+		},
+		{
+			":style",
+			runReplStr,
+			[]any{":style ascii\n(\\x:int. <<x, x>=1>>) 1\n:style unicode\n:ast \\x:int->int. x\n:style foo\n"},
+			[]any{"λ> λ> <<1, true>> : int & bool\nλ> λ> λx:int → int. x\n" +
+				"λ> unknown style 'foo'; try unicode or ascii\nλ> \n"},
+		},
+		{
+			":quit",
+			runReplStr,
+			[]any{":quit\n1\n"},
+			[]any{"λ> \n"},
+		},
+	})
+}
@@ -0,0 +1,74 @@
+/*
+ * Public API: evaluation (see eval.go), in place. None of those
+ * panic: runtime errors (e.g. when evaluating an ill-typed,
+ * unchecked expression), and limits being hit, are returned.
+ */
+package eval
+
+import (
+	"fmt"
+
+	"github.com/mbivert/golc/internal/panics"
+	"github.com/mbivert/golc/syntax"
+)
+
+// Reduce x to its normal form
+func Eval(x syntax.Expr) (y syntax.Expr, err error) {
+	defer panics.Catch(&err)
+	return evalExpr(x), nil
+}
+
+// Reduce x to its normal form, calling trace after each
+// rewriting; evaluation stops early if trace returns false,
+// in which case y is the partially reduced term.
+func EvalTrace(x syntax.Expr, trace func(Event) bool) (y syntax.Expr, err error) {
+	return EvalWith(x, EvalOptions{Trace: trace})
+}
+
+// Reduce x to its normal form, within opts' limits. When a
+// limit is hit, err is a *LimitError holding the partially
+// reduced term (also returned as y).
+func EvalWith(x syntax.Expr, opts EvalOptions) (y syntax.Expr, err error) {
+	defer panics.Catch(&err)
+	return newEvaluator(opts).eval(x)
+}
+
+// Perform a single (normal order) reduction step on x; the
+// boolean is false if x is in normal form. As for Eval(), x is
+// first erased (see syntax.Erase()).
+func Step(x syntax.Expr) (y syntax.Expr, b bool, err error) {
+	defer panics.Catch(&err)
+	y, b = reduceExpr(syntax.Erase(x))
+	return y, b, nil
+}
+
+// Strategy by name ("normal", "cbv", etc.; see Strategy.String())
+func ParseStrategy(s string) (Strategy, error) {
+	for st := NormalOrder; st <= WeakHeadOrder; st++ {
+		if st.String() == s {
+			return st, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown strategy '%s'", s)
+}
+
+// Engine by name ("subst", "cek", etc.; see Engine.String())
+func ParseEngine(s string) (Engine, error) {
+	for g := SubstEngine; g <= NeedEngine; g++ {
+		if g.String() == s {
+			return g, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown engine '%s'", s)
+}
+
+// Substitute the declarations x (transitively) uses in x;
+// x is modified in place.
+func Inline(q *syntax.Program, x syntax.Expr) syntax.Expr {
+	for i := len(q.Decls) - 1; i >= 0; i-- {
+		if d := q.Decls[i]; syntax.IsFree(x, d.Name) {
+			x = substituteExpr(x, d.X, d.Name)
+		}
+	}
+	return x
+}
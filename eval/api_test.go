@@ -0,0 +1,234 @@
+package eval_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mbivert/ftests"
+
+	. "github.com/mbivert/golc/eval"
+	"github.com/mbivert/golc/syntax"
+	"github.com/mbivert/golc/types"
+)
+
+// Parse, Check, Eval
+func run(src string) (string, string, error) {
+	x, err := syntax.Parse(src, "")
+	if err != nil {
+		return "", "", err
+	}
+	t, err := types.Check(x)
+	if err != nil {
+		return "", "", diagOf(err)
+	}
+	y, err := Eval(x)
+	if err != nil {
+		return "", "", err
+	}
+	return y.String(), t.String(), nil
+}
+
+// Eval only; runtime errors are compared as strings, as
+// they wrap a runtime.Error
+func runUntyped(src string) (string, string) {
+	x, err := syntax.Parse(src, "")
+	if err != nil {
+		return "", err.Error()
+	}
+	y, err := Eval(x)
+	if err != nil {
+		return "", err.Error()
+	}
+	return y.String(), ""
+}
+
+// Parse, Infer, Eval
+func runInfer(src string) (string, string, error) {
+	x, err := syntax.Parse(src, "")
+	if err != nil {
+		return "", "", err
+	}
+	t, err := types.Infer(x)
+	if err != nil {
+		return "", "", diagOf(err)
+	}
+	y, err := Eval(x)
+	if err != nil {
+		return "", "", err
+	}
+	return y.String(), t.String(), nil
+}
+
+// ParseProgram, CheckProgram, Bind, Eval
+func runProgram(src string) (string, string, error) {
+	q, err := syntax.ParseProgram(src, "")
+	if err != nil {
+		return "", "", err
+	}
+	t, err := types.CheckProgram(q)
+	if err != nil {
+		return "", "", diagOf(err)
+	}
+	y, err := Eval(q.Bind(q.Main))
+	if err != nil {
+		return "", "", err
+	}
+	return y.String(), t.String(), nil
+}
+
+// err's Diagnostic, if any (e.g. a *types.TypeError's)
+func diagOf(err error) error {
+	var d *syntax.Diagnostic
+	if errors.As(err, &d) {
+		return d
+	}
+	return err
+}
+
+// Type error on line ln, from column cn to ecn
+func typeErr(ln, cn, ecn uint, m string, notes ...string) error {
+	return &syntax.Diagnostic{syntax.SeverityError, "", syntax.Pos{ln, cn}, syntax.Pos{ln, ecn}, m, notes}
+}
+
+func TestAPIPipeline(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"well-typed",
+			run,
+			[]any{"(λx:int. x + 3) 4"},
+			[]any{"7", "int", nil},
+		},
+		{
+			"parse error",
+			run,
+			[]any{"(λx:int. x + 3"},
+			[]any{"", "", syntax.Diagnostics{{syntax.SeverityError, "", syntax.Pos{1, 15}, syntax.Pos{1, 15},
+				"Expecting left paren, got: EOF", []string{"1:1: unclosed '('"}}}},
+		},
+		{
+			"type error",
+			run,
+			[]any{"(λx:int. x + 3) true"},
+			[]any{"", "", typeErr(1, 17, 21, "Expecting 'int', got 'bool'",
+				"applying 'λx:int. x + 3' ('int → int') to 'true'")},
+		},
+		{
+			"missing annotations",
+			run,
+			[]any{"λx. x"},
+			[]any{"", "", typeErr(1, 1, 6, "Can't infer the type of 'x'; annotate it or add an ascription")},
+		},
+		{
+			"inferred",
+			runInfer,
+			[]any{"let id = λx. x in id (λy. y + 1) (id 2)"},
+			[]any{"3", "int", nil},
+		},
+		{
+			"inferred, type error",
+			runInfer,
+			[]any{"(λx. x + 3) true"},
+			[]any{"", "", typeErr(1, 1, 17, "Cannot unify 'int' with 'bool'",
+				"while unifying 'int → int' with 'bool → t1'",
+				"applying 'λx. x + 3' ('int → int') to 'true' ('bool')")},
+		},
+		{
+			"program",
+			runProgram,
+			[]any{"let x = 3; /* unused */ let y = 2;\n(λz:int. z + x) 4"},
+			[]any{"7", "int", nil},
+		},
+		{
+			"program, type error",
+			runProgram,
+			[]any{"let f = λx:int. x;\nf true"},
+			[]any{"", "", typeErr(2, 3, 7, "Expecting 'int', got 'bool'",
+				"applying 'f' ('int → int') to 'true'")},
+		},
+		{
+			"untyped",
+			runUntyped,
+			[]any{"(λx. λy. x) a b"},
+			[]any{"a", ""},
+		},
+		{
+			"runtime error",
+			runUntyped,
+			[]any{"(λx. x + 1) true"},
+			[]any{"", ":1:6: runtime error: + expects int operands, got 'true'"},
+		},
+	})
+}
+
+// EvalTrace, as a numbered sequence
+func runTrace(src string) (string, error) {
+	x, err := syntax.Parse(src, "")
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	y, err := EvalTrace(x, func(ev Event) bool {
+		fmt.Fprintf(&b, "%d %s %s %s\n", ev.N, ev.Rule, ev.Path, ev.Result)
+		return true
+	})
+	if err != nil {
+		return "", err
+	}
+	return b.String() + y.String(), nil
+}
+
+func TestAPIEvalTrace(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"β under λ, δ",
+			runTrace,
+			[]any{"λy. (λx. x + 1) 2"},
+			[]any{"1 β right 2 + 1\n2 δ right 3\nλy. 3", nil},
+		},
+	})
+}
+
+// Step, until a normal form is reached
+func runSteps(src string) (string, error) {
+	x, err := syntax.Parse(src, "")
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for {
+		y, ok, err := Step(x)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return b.String() + y.String(), nil
+		}
+		fmt.Fprintf(&b, "%s\n", y)
+		x = y
+	}
+}
+
+func TestAPIStep(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"β, δ",
+			runSteps,
+			[]any{"(λx. x + 1) 2"},
+			[]any{"2 + 1\n3\n3", nil},
+		},
+		{
+			"ascriptions are erased",
+			runSteps,
+			[]any{"((λx:int. x) : int → int) (1 : int)"},
+			[]any{"1\n1", nil},
+		},
+		{
+			"type abstractions and applications are erased",
+			runSteps,
+			[]any{"(Λa. λx:a. x) [int] 1"},
+			[]any{"1\n1", nil},
+		},
+	})
+}
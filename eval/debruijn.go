@@ -0,0 +1,227 @@
+/*
+ * Substitution on the nameless (de Bruijn) representation (see
+ * ../syntax/debruijn.go): as there are no names to clash, no
+ * α-renaming is needed: indices are shifted instead. The
+ * evaluator works on this representation when asked to
+ * (DeBruijnEngine), with the same strategies: beta() dispatches
+ * on the abstraction's representation.
+ */
+package eval
+
+import (
+	"reflect"
+
+	"github.com/mbivert/golc/syntax"
+)
+
+// Copy of x where the indices of the variables bound
+// outside of x, i.e. ≥ c, are shifted by d.
+func shiftDeBruijn(x syntax.Expr, d, c int) syntax.Expr {
+	switch x.(type) {
+	case *syntax.DeBruijnBVarExpr:
+		n := x.(*syntax.DeBruijnBVarExpr).N
+		if n >= c {
+			n += d
+		}
+		return &syntax.DeBruijnBVarExpr{syntax.Node{syntax.CopyType(x.Type()), x.Span()}, n}
+
+	case *syntax.DeBruijnAbsExpr:
+		return &syntax.DeBruijnAbsExpr{
+			syntax.Node{syntax.CopyType(x.Type()), x.Span()},
+			syntax.CopyType(x.(*syntax.DeBruijnAbsExpr).Typ),
+			shiftDeBruijn(x.(*syntax.DeBruijnAbsExpr).Right, d, c+1),
+		}
+
+	case *syntax.AppExpr:
+		return &syntax.AppExpr{
+			syntax.Node{syntax.CopyType(x.Type()), x.Span()},
+			shiftDeBruijn(x.(*syntax.AppExpr).Left, d, c),
+			shiftDeBruijn(x.(*syntax.AppExpr).Right, d, c),
+		}
+
+	case *syntax.ProductExpr:
+		return &syntax.ProductExpr{
+			syntax.Node{syntax.CopyType(x.Type()), x.Span()},
+			shiftDeBruijn(x.(*syntax.ProductExpr).Left, d, c),
+			shiftDeBruijn(x.(*syntax.ProductExpr).Right, d, c),
+		}
+
+	case *syntax.LetProductExpr:
+		return &syntax.LetProductExpr{
+			syntax.Node{syntax.CopyType(x.Type()), x.Span()},
+			x.(*syntax.LetProductExpr).N,
+			shiftDeBruijn(x.(*syntax.LetProductExpr).Left, d, c),
+			shiftDeBruijn(x.(*syntax.LetProductExpr).Right, d, c),
+		}
+
+	case *syntax.LetExpr:
+		return &syntax.LetExpr{
+			syntax.Node{syntax.CopyType(x.Type()), x.Span()},
+			shiftDeBruijn(x.(*syntax.LetExpr).Left, d, c),
+			shiftDeBruijn(x.(*syntax.LetExpr).Right, d, c),
+		}
+
+	case *syntax.LetUnitExpr:
+		return &syntax.LetUnitExpr{
+			syntax.Node{syntax.CopyType(x.Type()), x.Span()},
+			shiftDeBruijn(x.(*syntax.LetUnitExpr).Left, d, c),
+			shiftDeBruijn(x.(*syntax.LetUnitExpr).Right, d, c),
+		}
+
+	case *syntax.IfExpr:
+		return &syntax.IfExpr{
+			syntax.Node{syntax.CopyType(x.Type()), x.Span()},
+			shiftDeBruijn(x.(*syntax.IfExpr).Cond, d, c),
+			shiftDeBruijn(x.(*syntax.IfExpr).Left, d, c),
+			shiftDeBruijn(x.(*syntax.IfExpr).Right, d, c),
+		}
+
+	case *syntax.InjExpr:
+		return &syntax.InjExpr{
+			syntax.Node{syntax.CopyType(x.Type()), x.Span()},
+			x.(*syntax.InjExpr).Inr,
+			shiftDeBruijn(x.(*syntax.InjExpr).Right, d, c),
+		}
+
+	case *syntax.AnnotExpr:
+		return &syntax.AnnotExpr{
+			syntax.Node{syntax.CopyType(x.Type()), x.Span()},
+			shiftDeBruijn(x.(*syntax.AnnotExpr).X, d, c),
+			syntax.CopyType(x.(*syntax.AnnotExpr).Typ),
+		}
+
+	case *syntax.TypeAbsExpr:
+		return &syntax.TypeAbsExpr{
+			syntax.Node{syntax.CopyType(x.Type()), x.Span()},
+			x.(*syntax.TypeAbsExpr).Name,
+			shiftDeBruijn(x.(*syntax.TypeAbsExpr).Right, d, c),
+		}
+
+	case *syntax.TypeAppExpr:
+		return &syntax.TypeAppExpr{
+			syntax.Node{syntax.CopyType(x.Type()), x.Span()},
+			shiftDeBruijn(x.(*syntax.TypeAppExpr).Left, d, c),
+			syntax.CopyType(x.(*syntax.TypeAppExpr).Typ),
+		}
+
+	case *syntax.MatchExpr:
+		return &syntax.MatchExpr{
+			syntax.Node{syntax.CopyType(x.Type()), x.Span()},
+			shiftDeBruijn(x.(*syntax.MatchExpr).X, d, c),
+			shiftDeBruijn(x.(*syntax.MatchExpr).Left, d, c),
+			shiftDeBruijn(x.(*syntax.MatchExpr).Right, d, c),
+		}
+
+	case *syntax.UnaryExpr:
+		return &syntax.UnaryExpr{
+			syntax.Node{syntax.CopyType(x.Type()), x.Span()},
+			x.(*syntax.UnaryExpr).Op,
+			shiftDeBruijn(x.(*syntax.UnaryExpr).Right, d, c),
+		}
+
+	case *syntax.BinaryExpr:
+		return &syntax.BinaryExpr{
+			syntax.Node{syntax.CopyType(x.Type()), x.Span()},
+			x.(*syntax.BinaryExpr).Op,
+			shiftDeBruijn(x.(*syntax.BinaryExpr).Left, d, c),
+			shiftDeBruijn(x.(*syntax.BinaryExpr).Right, d, c),
+		}
+
+	default:
+		return syntax.Copy(x)
+	}
+}
+
+// β-substitution, in place, for the body x of an abstraction
+// applied to y: the variable bound by that abstraction (j, at
+// depth j within x) is replaced by y, and as the abstraction
+// disappears, the variables bound outside of it are decremented.
+func substituteDeBruijn(x, y syntax.Expr, j int) syntax.Expr {
+	switch x.(type) {
+	case *syntax.DeBruijnBVarExpr:
+		n := x.(*syntax.DeBruijnBVarExpr).N
+		if n == j {
+			// y's free indices are relative to the
+			// abstraction's context: lift them over
+			// the j binders in between (copies y).
+			return shiftDeBruijn(y, j, 0)
+		}
+		if n > j {
+			x.(*syntax.DeBruijnBVarExpr).N--
+		}
+		return x
+
+	case *syntax.DeBruijnAbsExpr:
+		x.(*syntax.DeBruijnAbsExpr).Right = substituteDeBruijn(x.(*syntax.DeBruijnAbsExpr).Right, y, j+1)
+		return x
+
+	case *syntax.AppExpr:
+		x.(*syntax.AppExpr).Left = substituteDeBruijn(x.(*syntax.AppExpr).Left, y, j)
+		x.(*syntax.AppExpr).Right = substituteDeBruijn(x.(*syntax.AppExpr).Right, y, j)
+		return x
+
+	case *syntax.ProductExpr:
+		x.(*syntax.ProductExpr).Left = substituteDeBruijn(x.(*syntax.ProductExpr).Left, y, j)
+		x.(*syntax.ProductExpr).Right = substituteDeBruijn(x.(*syntax.ProductExpr).Right, y, j)
+		return x
+
+	case *syntax.LetProductExpr:
+		x.(*syntax.LetProductExpr).Left = substituteDeBruijn(x.(*syntax.LetProductExpr).Left, y, j)
+		x.(*syntax.LetProductExpr).Right = substituteDeBruijn(x.(*syntax.LetProductExpr).Right, y, j)
+		return x
+
+	case *syntax.LetExpr:
+		x.(*syntax.LetExpr).Left = substituteDeBruijn(x.(*syntax.LetExpr).Left, y, j)
+		x.(*syntax.LetExpr).Right = substituteDeBruijn(x.(*syntax.LetExpr).Right, y, j)
+		return x
+
+	case *syntax.LetUnitExpr:
+		x.(*syntax.LetUnitExpr).Left = substituteDeBruijn(x.(*syntax.LetUnitExpr).Left, y, j)
+		x.(*syntax.LetUnitExpr).Right = substituteDeBruijn(x.(*syntax.LetUnitExpr).Right, y, j)
+		return x
+
+	case *syntax.IfExpr:
+		x.(*syntax.IfExpr).Cond = substituteDeBruijn(x.(*syntax.IfExpr).Cond, y, j)
+		x.(*syntax.IfExpr).Left = substituteDeBruijn(x.(*syntax.IfExpr).Left, y, j)
+		x.(*syntax.IfExpr).Right = substituteDeBruijn(x.(*syntax.IfExpr).Right, y, j)
+		return x
+
+	case *syntax.InjExpr:
+		x.(*syntax.InjExpr).Right = substituteDeBruijn(x.(*syntax.InjExpr).Right, y, j)
+		return x
+
+	case *syntax.AnnotExpr:
+		x.(*syntax.AnnotExpr).X = substituteDeBruijn(x.(*syntax.AnnotExpr).X, y, j)
+		return x
+
+	case *syntax.TypeAbsExpr:
+		x.(*syntax.TypeAbsExpr).Right = substituteDeBruijn(x.(*syntax.TypeAbsExpr).Right, y, j)
+		return x
+
+	case *syntax.TypeAppExpr:
+		x.(*syntax.TypeAppExpr).Left = substituteDeBruijn(x.(*syntax.TypeAppExpr).Left, y, j)
+		return x
+
+	case *syntax.MatchExpr:
+		x.(*syntax.MatchExpr).X = substituteDeBruijn(x.(*syntax.MatchExpr).X, y, j)
+		x.(*syntax.MatchExpr).Left = substituteDeBruijn(x.(*syntax.MatchExpr).Left, y, j)
+		x.(*syntax.MatchExpr).Right = substituteDeBruijn(x.(*syntax.MatchExpr).Right, y, j)
+		return x
+
+	case *syntax.UnaryExpr:
+		x.(*syntax.UnaryExpr).Right = substituteDeBruijn(x.(*syntax.UnaryExpr).Right, y, j)
+		return x
+
+	case *syntax.BinaryExpr:
+		x.(*syntax.BinaryExpr).Left = substituteDeBruijn(x.(*syntax.BinaryExpr).Left, y, j)
+		x.(*syntax.BinaryExpr).Right = substituteDeBruijn(x.(*syntax.BinaryExpr).Right, y, j)
+		return x
+
+	// free variables, literals
+	case *syntax.VarExpr, *syntax.UnitExpr, *syntax.IntExpr, *syntax.FloatExpr, *syntax.BoolExpr:
+		return x
+
+	default:
+		panic("assert: " + reflect.ValueOf(x).Type().String())
+	}
+}
@@ -0,0 +1,172 @@
+package eval_test
+
+import (
+	"testing"
+
+	"github.com/mbivert/ftests"
+
+	. "github.com/mbivert/golc/eval"
+	"github.com/mbivert/golc/internal/testutil"
+	"github.com/mbivert/golc/syntax"
+)
+
+func TestDeBruijnConversions(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"free variable",
+			syntax.ToDeBruijn,
+			[]any{testutil.MustParse("x")},
+			[]any{testutil.MustParse("x")},
+		},
+		{
+			"nested abstractions, free variable",
+			syntax.ToDeBruijn,
+			[]any{testutil.MustParse("λx. λy. x y z")},
+			[]any{&syntax.DeBruijnAbsExpr{syntax.Node{}, &syntax.UnknownType{},
+				&syntax.DeBruijnAbsExpr{syntax.Node{}, &syntax.UnknownType{},
+					&syntax.AppExpr{syntax.Node{},
+						&syntax.AppExpr{syntax.Node{},
+							&syntax.DeBruijnBVarExpr{syntax.Node{}, 1},
+							&syntax.DeBruijnBVarExpr{syntax.Node{}, 0},
+						},
+						&syntax.VarExpr{syntax.Node{}, "z"},
+					},
+				},
+			}},
+		},
+		{
+			"shadowing, operators, products",
+			func(s string) string { return syntax.ToDeBruijn(testutil.MustParse(s)).String() },
+			[]any{"λx:int. 〈λx:int. x + 1, x * 2〉"},
+			[]any{"λ:int. 〈λ:int. #0 + 1, #0 * 2〉"},
+		},
+		{
+			"back, readable names",
+			syntax.FromDeBruijn,
+			[]any{syntax.ToDeBruijn(testutil.MustParse("λa. λb. λc. a (b c)"))},
+			[]any{testutil.MustParse("λx. λy. λz. x (y z)")},
+		},
+		{
+			"back, avoiding free variables",
+			syntax.FromDeBruijn,
+			[]any{syntax.ToDeBruijn(testutil.MustParse("λa. λb. x a b"))},
+			[]any{testutil.MustParse("λy. λz. x y z")},
+		},
+		{
+			"back, shadowing",
+			syntax.FromDeBruijn,
+			[]any{syntax.ToDeBruijn(testutil.MustParse("λx. x (λx. x)"))},
+			[]any{testutil.MustParse("λx. x (λy. y)")},
+		},
+	})
+}
+
+func TestDeBruijnShiftSubstitute(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"shift, bound variables untouched",
+			ShiftDeBruijn,
+			[]any{syntax.ToDeBruijn(testutil.MustParse("λx. x")), 2, 0},
+			[]any{syntax.ToDeBruijn(testutil.MustParse("λx. x"))},
+		},
+		{
+			"shift, outer variables",
+			func(x syntax.Expr, d, c int) string { return ShiftDeBruijn(x, d, c).String() },
+			[]any{&syntax.DeBruijnAbsExpr{syntax.Node{}, &syntax.UnknownType{},
+				&syntax.AppExpr{syntax.Node{}, &syntax.DeBruijnBVarExpr{syntax.Node{}, 0}, &syntax.DeBruijnBVarExpr{syntax.Node{}, 1}}}, 2, 0},
+			[]any{"λ. #0 #3"},
+		},
+		{
+			// (λx. λy. x y) (λz. z) → λy. (λz. z) y
+			"substitute, under an abstraction",
+			func(x, y syntax.Expr) string { return SubstituteDeBruijn(x, y, 0).String() },
+			[]any{
+				syntax.ToDeBruijn(testutil.MustParse("λx. λy. x y")).(*syntax.DeBruijnAbsExpr).Right,
+				syntax.ToDeBruijn(testutil.MustParse("λz. z")),
+			},
+			[]any{"λ. (λ. #0) #0"},
+		},
+		{
+			// λw. (λx. λy. w) M → λw. λy. w
+			"substitute, outer variables decremented",
+			func(x, y syntax.Expr) string { return SubstituteDeBruijn(x, y, 0).String() },
+			[]any{
+				&syntax.DeBruijnAbsExpr{syntax.Node{}, &syntax.UnknownType{}, &syntax.DeBruijnBVarExpr{syntax.Node{}, 2}},
+				&syntax.VarExpr{syntax.Node{}, "M"},
+			},
+			[]any{"λ. #1"},
+		},
+		{
+			// λw. (λx. λy. x) w → λw. λy. w
+			"substitute, argument lifted",
+			func(x, y syntax.Expr) string { return SubstituteDeBruijn(x, y, 0).String() },
+			[]any{
+				&syntax.DeBruijnAbsExpr{syntax.Node{}, &syntax.UnknownType{}, &syntax.DeBruijnBVarExpr{syntax.Node{}, 1}},
+				&syntax.DeBruijnBVarExpr{syntax.Node{}, 0},
+			},
+			[]any{"λ. #1"},
+		},
+	})
+}
+
+func evalDeBruijn(x syntax.Expr) syntax.Expr {
+	y, _ := EvalWith(x, EvalOptions{Engine: DeBruijnEngine})
+	return y
+}
+
+// Both engines should agree (up to α-equivalence: the
+// de Bruijn evaluator picks its own names)
+func TestDeBruijnEval(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"arithmetic",
+			evalDeBruijn,
+			[]any{testutil.MustSTypeParse("(λx:int. λy:int. x * y + 1) 3 4")},
+			[]any{testutil.MustSTypeParse("13")},
+		},
+		{
+			"capture",
+			evalDeBruijn,
+			[]any{testutil.MustParse("(λx. λy. x) y")},
+			[]any{testutil.MustParse("λx. y")},
+		},
+		{
+			"products",
+			evalDeBruijn,
+			[]any{testutil.MustParse("(λx. 〈x, λx. x〉) a")},
+			[]any{testutil.MustParse("〈a, λx. x〉")},
+		},
+		{
+			"let 〈...〉",
+			evalDeBruijn,
+			[]any{testutil.MustParse("(λy. let 〈x, z〉 = 〈y, 1〉 in λy. x) z")},
+			[]any{testutil.MustParse("λx. z")},
+		},
+		{
+			"fact three",
+			func(s string) bool {
+				return syntax.AlphaEqual(evalDeBruijn(testutil.MustParseChurch(s)), testutil.MustEval(testutil.MustParseChurch(s)))
+			},
+			[]any{"fact three"},
+			[]any{true},
+		},
+		{
+			"strategies, limits",
+			EvalWith,
+			[]any{testutil.MustParse("(λx. y) ((λx. x x) (λx. x x))"),
+				EvalOptions{Engine: DeBruijnEngine, Strategy: ApplicativeOrder, MaxSteps: 3}},
+			[]any{testutil.MustParse("(λx. y) ((λx. x x) (λx. x x))"),
+				&LimitError{ErrMaxSteps, 3, testutil.MustParse("(λx. y) ((λx. x x) (λx. x x))")}},
+		},
+	})
+}
+
+func benchmarkFact(b *testing.B, g Engine) {
+	s := "fact three"
+	for i := 0; i < b.N; i++ {
+		EvalWith(testutil.MustParseChurch(s), EvalOptions{Engine: g})
+	}
+}
+
+func BenchmarkFactSubst(b *testing.B)    { benchmarkFact(b, SubstEngine) }
+func BenchmarkFactDeBruijn(b *testing.B) { benchmarkFact(b, DeBruijnEngine) }
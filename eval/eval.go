@@ -0,0 +1,920 @@
+/*
+ * Evaluation. We assume things have been typechecked.
+ */
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/mbivert/golc/syntax"
+)
+
+// true if x is an int, float or bool literal
+func isLiteral(x syntax.Expr) bool {
+	switch x.(type) {
+	case *syntax.IntExpr, *syntax.FloatExpr, *syntax.BoolExpr:
+		return true
+	}
+	return false
+}
+
+// Kind of literals expected by the δ-reduced operators
+var operandKinds = map[syntax.TokenKind]string{
+	syntax.TokenPlus:    "int",
+	syntax.TokenMinus:   "int",
+	syntax.TokenStar:    "int",
+	syntax.TokenSlash:   "int",
+	syntax.TokenLess:    "int",
+	syntax.TokenMore:    "int",
+	syntax.TokenLessEq:  "int",
+	syntax.TokenMoreEq:  "int",
+	syntax.TokenFPlus:   "float",
+	syntax.TokenFMinus:  "float",
+	syntax.TokenFStar:   "float",
+	syntax.TokenFSlash:  "float",
+	syntax.TokenFLess:   "float",
+	syntax.TokenFMore:   "float",
+	syntax.TokenFLessEq: "float",
+	syntax.TokenFMoreEq: "float",
+	syntax.TokenExcl:    "bool",
+	syntax.TokenAndAnd:  "bool",
+	syntax.TokenOrOr:    "bool",
+}
+
+// x's kind, if it's a literal ("int", "float", "bool")
+func literalKind(x syntax.Expr) string {
+	switch x.(type) {
+	case *syntax.IntExpr:
+		return "int"
+	case *syntax.FloatExpr:
+		return "float"
+	case *syntax.BoolExpr:
+		return "bool"
+	}
+	return ""
+}
+
+// Ensure op can be δ-reduced on the literals xs, which may
+// be ill-typed (unchecked expressions); raises a located
+// runtime error otherwise.
+func checkOperands(x syntax.Expr, op syntax.TokenKind, xs ...syntax.Expr) {
+	k, ok := operandKinds[op]
+	if !ok {
+		return
+	}
+	s := "s"
+	if len(xs) == 1 {
+		s = ""
+	}
+	for _, y := range xs {
+		if literalKind(y) != k {
+			panic(syntax.ErrAt(x, "runtime error: %s expects %s operand%s, got '%s'", op, k, s, y))
+		}
+	}
+	if op == syntax.TokenSlash && xs[1].(*syntax.IntExpr).Value == 0 {
+		panic(syntax.ErrAt(x, "runtime error: division by zero"))
+	}
+}
+
+// Turn the unexpected errors raised while δ-reducing x into
+// located errors; to be deferred, once checkOperands() passed.
+func locate(x syntax.Expr) {
+	if r := recover(); r != nil {
+		panic(syntax.ErrAt(x, "internal error: %v", r))
+	}
+}
+
+// δ-reduction; x's operand is expected to be a literal
+func evalUnaryExpr(x *syntax.UnaryExpr) syntax.Expr {
+	checkOperands(x, x.Op, x.Right)
+	defer locate(x)
+
+	r := x.Right
+
+	int64Ops := map[syntax.TokenKind](func(int64) int64){
+		syntax.TokenPlus:  func(a int64) int64 { return a },
+		syntax.TokenMinus: func(a int64) int64 { return -a },
+	}
+
+	float64Ops := map[syntax.TokenKind](func(float64) float64){
+		syntax.TokenFPlus:  func(a float64) float64 { return a },
+		syntax.TokenFMinus: func(a float64) float64 { return -a },
+	}
+
+	switch x.Op {
+	case syntax.TokenPlus:
+		fallthrough
+	case syntax.TokenMinus:
+		return &syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, int64Ops[x.Op](r.(*syntax.IntExpr).Value)}
+
+	case syntax.TokenFPlus:
+		fallthrough
+	case syntax.TokenFMinus:
+		return &syntax.FloatExpr{syntax.Node{&syntax.FloatType{}, nil}, float64Ops[x.Op](r.(*syntax.FloatExpr).Value)}
+
+	case syntax.TokenExcl:
+		return &syntax.BoolExpr{syntax.Node{&syntax.BoolType{}, nil}, !r.(*syntax.BoolExpr).Value}
+
+	default:
+		panic("TODO: " + x.Op.String())
+	}
+
+	return nil
+}
+
+// δ-reduction; x's operands are expected to be literals
+func evalBinaryExpr(x *syntax.BinaryExpr) syntax.Expr {
+	checkOperands(x, x.Op, x.Left, x.Right)
+	defer locate(x)
+
+	l, r := x.Left, x.Right
+
+	int64Ops := map[syntax.TokenKind](func(int64, int64) int64){
+		syntax.TokenPlus:  func(a, b int64) int64 { return a + b },
+		syntax.TokenStar:  func(a, b int64) int64 { return a * b },
+		syntax.TokenMinus: func(a, b int64) int64 { return a - b },
+		syntax.TokenSlash: func(a, b int64) int64 { return a / b },
+	}
+
+	int64CmpOps := map[syntax.TokenKind](func(int64, int64) bool){
+		syntax.TokenLess:   func(a, b int64) bool { return a < b },
+		syntax.TokenMore:   func(a, b int64) bool { return a > b },
+		syntax.TokenLessEq: func(a, b int64) bool { return a <= b },
+		syntax.TokenMoreEq: func(a, b int64) bool { return a >= b },
+	}
+
+	float64Ops := map[syntax.TokenKind](func(float64, float64) float64){
+		syntax.TokenFPlus:  func(a, b float64) float64 { return a + b },
+		syntax.TokenFStar:  func(a, b float64) float64 { return a * b },
+		syntax.TokenFMinus: func(a, b float64) float64 { return a - b },
+		syntax.TokenFSlash: func(a, b float64) float64 { return a / b },
+	}
+
+	float64CmpOps := map[syntax.TokenKind](func(float64, float64) bool){
+		syntax.TokenFLess:   func(a, b float64) bool { return a < b },
+		syntax.TokenFMore:   func(a, b float64) bool { return a > b },
+		syntax.TokenFLessEq: func(a, b float64) bool { return a <= b },
+		syntax.TokenFMoreEq: func(a, b float64) bool { return a >= b },
+	}
+
+	boolOps := map[syntax.TokenKind](func(bool, bool) bool){
+		syntax.TokenAndAnd: func(a, b bool) bool { return a && b },
+		syntax.TokenOrOr:   func(a, b bool) bool { return a || b },
+	}
+
+	switch x.Op {
+	// XXX/TODO: should we allow e.g. x + 3? where x
+	// is undefined (why not I guess?)
+	case syntax.TokenPlus:
+		fallthrough
+	case syntax.TokenStar:
+		fallthrough
+	case syntax.TokenMinus:
+		fallthrough
+	case syntax.TokenSlash:
+		return &syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil},
+			int64Ops[x.Op](l.(*syntax.IntExpr).Value, r.(*syntax.IntExpr).Value),
+		}
+
+	case syntax.TokenLess:
+		fallthrough
+	case syntax.TokenMore:
+		fallthrough
+	case syntax.TokenLessEq:
+		fallthrough
+	case syntax.TokenMoreEq:
+		return &syntax.BoolExpr{syntax.Node{&syntax.BoolType{}, nil},
+			int64CmpOps[x.Op](l.(*syntax.IntExpr).Value, r.(*syntax.IntExpr).Value),
+		}
+
+	case syntax.TokenFPlus:
+		fallthrough
+	case syntax.TokenFStar:
+		fallthrough
+	case syntax.TokenFMinus:
+		fallthrough
+	case syntax.TokenFSlash:
+		return &syntax.FloatExpr{syntax.Node{&syntax.FloatType{}, nil},
+			float64Ops[x.Op](l.(*syntax.FloatExpr).Value, r.(*syntax.FloatExpr).Value),
+		}
+
+	case syntax.TokenFLess:
+		fallthrough
+	case syntax.TokenFMore:
+		fallthrough
+	case syntax.TokenFLessEq:
+		fallthrough
+	case syntax.TokenFMoreEq:
+		return &syntax.BoolExpr{syntax.Node{&syntax.BoolType{}, nil},
+			float64CmpOps[x.Op](l.(*syntax.FloatExpr).Value, r.(*syntax.FloatExpr).Value),
+		}
+
+	case syntax.TokenAndAnd:
+		fallthrough
+	case syntax.TokenOrOr:
+		return &syntax.BoolExpr{syntax.Node{&syntax.BoolType{}, nil},
+			boolOps[x.Op](l.(*syntax.BoolExpr).Value, r.(*syntax.BoolExpr).Value),
+		}
+
+	default:
+		panic("TODO: " + x.Op.String())
+	}
+}
+
+// δ-reduction; x's condition is expected to be a literal
+func evalIfExpr(x *syntax.IfExpr) syntax.Expr {
+	if literalKind(x.Cond) != "bool" {
+		panic(syntax.ErrAt(x, "runtime error: if expects a bool condition, got '%s'", x.Cond))
+	}
+	defer locate(x)
+
+	if x.Cond.(*syntax.BoolExpr).Value {
+		return x.Left
+	}
+	return x.Right
+}
+
+// Runtime error for x (let 〈...〉, let * or match), which can't
+// take v, its bound value or scrutinee, apart: as for if (see
+// evalIfExpr()), v is a literal (or *) of the wrong kind. Nil if
+// x is merely stuck (e.g. v is a free variable).
+func stuckErr(x syntax.Expr, v value) error {
+	y, ok := v.(syntax.Expr)
+	if !ok {
+		return nil
+	}
+	_, isUnit := y.(*syntax.UnitExpr)
+	if !isLiteral(y) && !isUnit {
+		return nil
+	}
+
+	switch x.(type) {
+	case *syntax.LetProductExpr:
+		return syntax.ErrAt(x, "runtime error: let 〈...〉 expects a pair, got '%s'", y)
+	case *syntax.LetUnitExpr:
+		if !isUnit {
+			return syntax.ErrAt(x, "runtime error: let * expects *, got '%s'", y)
+		}
+	case *syntax.MatchExpr:
+		return syntax.ErrAt(x, "runtime error: match expects an injection, got '%s'", y)
+	}
+	return nil
+}
+
+// Panics with stuckErr(x, v), if any, unless evaluation
+// has been stopped
+func (e *evaluator) checkStuck(x syntax.Expr, v value) {
+	if err := stuckErr(x, v); err != nil && !e.stop {
+		panic(err)
+	}
+}
+
+// α-renaming x{b,a}: renaming a as b in x.
+//
+// renaming is performed in-place (why not I guess?)
+func renameExpr(x syntax.Expr, b, a string) syntax.Expr {
+	switch x.(type) {
+	case *syntax.UnitExpr:
+		return x
+	case *syntax.IntExpr:
+		return x
+	case *syntax.FloatExpr:
+		return x
+	case *syntax.BoolExpr:
+		return x
+	case *syntax.ProductExpr:
+		x.(*syntax.ProductExpr).Left = renameExpr(x.(*syntax.ProductExpr).Left, b, a)
+		x.(*syntax.ProductExpr).Right = renameExpr(x.(*syntax.ProductExpr).Right, b, a)
+		return x
+
+	case *syntax.UnaryExpr:
+		x.(*syntax.UnaryExpr).Right = renameExpr(x.(*syntax.UnaryExpr).Right, b, a)
+		return x
+
+	case *syntax.BinaryExpr:
+		x.(*syntax.BinaryExpr).Left = renameExpr(x.(*syntax.BinaryExpr).Left, b, a)
+		x.(*syntax.BinaryExpr).Right = renameExpr(x.(*syntax.BinaryExpr).Right, b, a)
+		return x
+
+	case *syntax.AppExpr:
+		x.(*syntax.AppExpr).Left = renameExpr(x.(*syntax.AppExpr).Left, b, a)
+		x.(*syntax.AppExpr).Right = renameExpr(x.(*syntax.AppExpr).Right, b, a)
+		return x
+
+	case *syntax.VarExpr:
+		if x.(*syntax.VarExpr).Name == a {
+			x.(*syntax.VarExpr).Name = b
+		}
+		return x
+
+	case *syntax.AbsExpr:
+		if x.(*syntax.AbsExpr).Name == a {
+			x.(*syntax.AbsExpr).Name = b
+		}
+		x.(*syntax.AbsExpr).Right = renameExpr(x.(*syntax.AbsExpr).Right, b, a)
+		return x
+
+	case *syntax.LetProductExpr:
+		x.(*syntax.LetProductExpr).Left = renameExpr(x.(*syntax.LetProductExpr).Left, b, a)
+		x.(*syntax.LetProductExpr).Right = renameExpr(x.(*syntax.LetProductExpr).Right, b, a)
+		return x
+
+	case *syntax.LetExpr:
+		x.(*syntax.LetExpr).Left = renameExpr(x.(*syntax.LetExpr).Left, b, a)
+		x.(*syntax.LetExpr).Right = renameExpr(x.(*syntax.LetExpr).Right, b, a)
+		return x
+
+	case *syntax.LetUnitExpr:
+		x.(*syntax.LetUnitExpr).Left = renameExpr(x.(*syntax.LetUnitExpr).Left, b, a)
+		x.(*syntax.LetUnitExpr).Right = renameExpr(x.(*syntax.LetUnitExpr).Right, b, a)
+		return x
+
+	case *syntax.IfExpr:
+		x.(*syntax.IfExpr).Cond = renameExpr(x.(*syntax.IfExpr).Cond, b, a)
+		x.(*syntax.IfExpr).Left = renameExpr(x.(*syntax.IfExpr).Left, b, a)
+		x.(*syntax.IfExpr).Right = renameExpr(x.(*syntax.IfExpr).Right, b, a)
+		return x
+
+	case *syntax.InjExpr:
+		x.(*syntax.InjExpr).Right = renameExpr(x.(*syntax.InjExpr).Right, b, a)
+		return x
+
+	case *syntax.AnnotExpr:
+		x.(*syntax.AnnotExpr).X = renameExpr(x.(*syntax.AnnotExpr).X, b, a)
+		return x
+
+	case *syntax.TypeAbsExpr:
+		x.(*syntax.TypeAbsExpr).Right = renameExpr(x.(*syntax.TypeAbsExpr).Right, b, a)
+		return x
+
+	case *syntax.TypeAppExpr:
+		x.(*syntax.TypeAppExpr).Left = renameExpr(x.(*syntax.TypeAppExpr).Left, b, a)
+		return x
+
+	case *syntax.MatchExpr:
+		x.(*syntax.MatchExpr).X = renameExpr(x.(*syntax.MatchExpr).X, b, a)
+		x.(*syntax.MatchExpr).Left = renameExpr(x.(*syntax.MatchExpr).Left, b, a)
+		x.(*syntax.MatchExpr).Right = renameExpr(x.(*syntax.MatchExpr).Right, b, a)
+		return x
+
+	default:
+		panic("assert")
+	}
+
+	return nil
+}
+
+// Evaluation engines
+type Engine int
+
+const (
+	SubstEngine    Engine = iota // named terms, capture-avoiding substitution
+	DeBruijnEngine               // nameless terms, shifting (debruijn.go)
+	CEKEngine                    // call-by-value machine (machine.go)
+	KrivineEngine                // call-by-name machine (machine.go)
+	NeedEngine                   // call-by-need machine (machine.go)
+)
+
+func (g Engine) String() string {
+	switch g {
+	case SubstEngine:
+		return "subst"
+	case DeBruijnEngine:
+		return "debruijn"
+	case CEKEngine:
+		return "cek"
+	case KrivineEngine:
+		return "krivine"
+	case NeedEngine:
+		return "need"
+	}
+	return fmt.Sprintf("Engine(%d)", int(g))
+}
+
+// Strategy implemented by a machine engine: normal forms are
+// read back as applicative order's (CEK) or normal order's
+// (Krivine, need) would be. ok is false for the rewriting
+// engines, which implement all strategies.
+func (g Engine) Strategy() (st Strategy, ok bool) {
+	switch g {
+	case CEKEngine:
+		return ApplicativeOrder, true
+	case KrivineEngine, NeedEngine:
+		return NormalOrder, true
+	}
+	return NormalOrder, false
+}
+
+// Evaluation options; zero values mean normal order, no limits.
+// Machine engines only support some of them (see check()).
+type EvalOptions struct {
+	Strategy Strategy // machine engines: see Engine.Strategy()
+	Engine   Engine
+	MaxSteps int              // β/δ steps
+	MaxSize  int              // term size, in nodes (see sizeExpr())
+	Context  context.Context  // deadline, cancellation
+	Trace    func(Event) bool // see EvalTrace()
+	Stats    *EvalStats       // filled once evaluation is over
+}
+
+type EvalStats struct {
+	Steps int // β/δ steps performed
+	Saved int // β/δ steps spared by sharing (NeedEngine)
+}
+
+var (
+	ErrMaxSteps = errors.New("too many steps")
+	ErrMaxSize  = errors.New("term too large")
+)
+
+// Evaluation interrupted by one of the EvalOptions' limits.
+// Err is either ErrMaxSteps, ErrMaxSize or the context's error.
+type LimitError struct {
+	Err   error
+	Steps int         // number of β/δ steps performed
+	Term  syntax.Expr // partially reduced term
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("evaluation stopped after %d steps: %s", e.Steps, e.Err)
+}
+
+func (e *LimitError) Unwrap() error { return e.Err }
+
+// Reject the options opts' engine can't honor: machines only
+// implement their own strategy, and have neither a term to
+// measure nor rewritings to trace.
+func (opts EvalOptions) check() error {
+	st, ok := opts.Engine.Strategy()
+	switch {
+	case !ok:
+		return nil
+	case opts.Strategy != st:
+		return fmt.Errorf("The %s engine only implements the %s strategy, not %s",
+			opts.Engine, st, opts.Strategy)
+	case opts.MaxSize > 0:
+		return fmt.Errorf("The %s engine can't limit the term's size", opts.Engine)
+	case opts.Trace != nil:
+		return fmt.Errorf("The %s engine can't trace evaluation", opts.Engine)
+	}
+	return nil
+}
+
+// Evaluation state; the zero value evaluates without tracing
+// nor limits.
+type evaluator struct {
+	root  *syntax.Expr     // whole term being reduced
+	path  syntax.Path      // current position within *root
+	trace func(Event) bool // nil: no tracing; false: stop
+	n     int              // number of β/δ steps performed
+	stop  bool             // trace asked to stop, or limit hit
+	opts  EvalOptions
+	err   error // limit hit, if any
+	saved int   // steps spared by sharing
+	size  int   // size of *root, if limited (see contracted())
+}
+
+func newEvaluator(opts EvalOptions) *evaluator {
+	return &evaluator{trace: opts.Trace, opts: opts}
+}
+
+// Called before each β/δ step: false if we're not
+// allowed to perform it.
+func (e *evaluator) allowed() bool {
+	switch {
+	case e.opts.MaxSteps > 0 && e.n >= e.opts.MaxSteps:
+		e.err = ErrMaxSteps
+	case e.opts.MaxSize > 0 && e.size > e.opts.MaxSize:
+		e.err = ErrMaxSize
+	case e.opts.Context != nil && e.opts.Context.Err() != nil:
+		e.err = e.opts.Context.Err()
+	default:
+		return true
+	}
+	e.stop = true
+	return false
+}
+
+// Size of the redex x, about to be contracted, if the term's
+// size is limited (0 otherwise).
+func (e *evaluator) redexSize(x syntax.Expr) int {
+	if e.opts.MaxSize <= 0 {
+		return 0
+	}
+	return syntax.SizeExpr(x)
+}
+
+// A redex of size n has been contracted to y: the size of the
+// term is updated, rather than recomputed, and checked right
+// away, as a single step may duplicate large arguments.
+func (e *evaluator) contracted(n int, y syntax.Expr) {
+	if e.opts.MaxSize <= 0 {
+		return
+	}
+	e.size += syntax.SizeExpr(y) - n
+	if e.size > e.opts.MaxSize && e.err == nil {
+		e.err = ErrMaxSize
+		e.stop = true
+	}
+}
+
+func (e *evaluator) push(field string) {
+	e.path = append(e.path, field)
+}
+
+func (e *evaluator) pop() {
+	e.path = e.path[:len(e.path)-1]
+}
+
+// pop, push
+func (e *evaluator) swap(field string) {
+	e.path[len(e.path)-1] = field
+}
+
+// Perform a step on the sub-term in the given field
+func (e *evaluator) down(field string, p *syntax.Expr) bool {
+	e.push(field)
+	b := e.step(p)
+	e.pop()
+	return b
+}
+
+// β-substitution: x[y/a]: substituing a for y in x
+func substituteExpr(x, y syntax.Expr, a string) syntax.Expr {
+	var e evaluator
+	return e.substitute(x, y, a)
+}
+
+func (e *evaluator) substitute(x, y syntax.Expr, a string) syntax.Expr {
+	switch x.(type) {
+	case *syntax.UnitExpr:
+		return x
+	case *syntax.IntExpr:
+		return x
+	case *syntax.FloatExpr:
+		return x
+	case *syntax.BoolExpr:
+		return x
+	case *syntax.ProductExpr:
+		e.push("left")
+		x.(*syntax.ProductExpr).Left = e.substitute(x.(*syntax.ProductExpr).Left, y, a)
+		e.swap("right")
+		x.(*syntax.ProductExpr).Right = e.substitute(x.(*syntax.ProductExpr).Right, y, a)
+		e.pop()
+		return x
+
+	case *syntax.UnaryExpr:
+		e.push("right")
+		x.(*syntax.UnaryExpr).Right = e.substitute(x.(*syntax.UnaryExpr).Right, y, a)
+		e.pop()
+		return x
+
+	case *syntax.BinaryExpr:
+		e.push("left")
+		x.(*syntax.BinaryExpr).Left = e.substitute(x.(*syntax.BinaryExpr).Left, y, a)
+		e.swap("right")
+		x.(*syntax.BinaryExpr).Right = e.substitute(x.(*syntax.BinaryExpr).Right, y, a)
+		e.pop()
+		return x
+
+	case *syntax.AppExpr:
+		e.push("left")
+		x.(*syntax.AppExpr).Left = e.substitute(x.(*syntax.AppExpr).Left, y, a)
+		e.swap("right")
+		x.(*syntax.AppExpr).Right = e.substitute(x.(*syntax.AppExpr).Right, y, a)
+		e.pop()
+		return x
+
+	// names are bound by right's abstractions
+	case *syntax.LetProductExpr:
+		e.push("left")
+		x.(*syntax.LetProductExpr).Left = e.substitute(x.(*syntax.LetProductExpr).Left, y, a)
+		e.swap("right")
+		x.(*syntax.LetProductExpr).Right = e.substitute(x.(*syntax.LetProductExpr).Right, y, a)
+		e.pop()
+		return x
+
+	case *syntax.LetExpr:
+		e.push("left")
+		x.(*syntax.LetExpr).Left = e.substitute(x.(*syntax.LetExpr).Left, y, a)
+		e.swap("right")
+		x.(*syntax.LetExpr).Right = e.substitute(x.(*syntax.LetExpr).Right, y, a)
+		e.pop()
+		return x
+
+	case *syntax.LetUnitExpr:
+		e.push("left")
+		x.(*syntax.LetUnitExpr).Left = e.substitute(x.(*syntax.LetUnitExpr).Left, y, a)
+		e.swap("right")
+		x.(*syntax.LetUnitExpr).Right = e.substitute(x.(*syntax.LetUnitExpr).Right, y, a)
+		e.pop()
+		return x
+
+	case *syntax.IfExpr:
+		e.push("cond")
+		x.(*syntax.IfExpr).Cond = e.substitute(x.(*syntax.IfExpr).Cond, y, a)
+		e.swap("left")
+		x.(*syntax.IfExpr).Left = e.substitute(x.(*syntax.IfExpr).Left, y, a)
+		e.swap("right")
+		x.(*syntax.IfExpr).Right = e.substitute(x.(*syntax.IfExpr).Right, y, a)
+		e.pop()
+		return x
+
+	case *syntax.InjExpr:
+		e.push("right")
+		x.(*syntax.InjExpr).Right = e.substitute(x.(*syntax.InjExpr).Right, y, a)
+		e.pop()
+		return x
+
+	case *syntax.AnnotExpr:
+		e.push("x")
+		x.(*syntax.AnnotExpr).X = e.substitute(x.(*syntax.AnnotExpr).X, y, a)
+		e.pop()
+		return x
+
+	// type variables and variables don't clash
+	case *syntax.TypeAbsExpr:
+		e.push("right")
+		x.(*syntax.TypeAbsExpr).Right = e.substitute(x.(*syntax.TypeAbsExpr).Right, y, a)
+		e.pop()
+		return x
+
+	case *syntax.TypeAppExpr:
+		e.push("left")
+		x.(*syntax.TypeAppExpr).Left = e.substitute(x.(*syntax.TypeAppExpr).Left, y, a)
+		e.pop()
+		return x
+
+	// names are bound by the branches' abstractions
+	case *syntax.MatchExpr:
+		e.push("x")
+		x.(*syntax.MatchExpr).X = e.substitute(x.(*syntax.MatchExpr).X, y, a)
+		e.swap("left")
+		x.(*syntax.MatchExpr).Left = e.substitute(x.(*syntax.MatchExpr).Left, y, a)
+		e.swap("right")
+		x.(*syntax.MatchExpr).Right = e.substitute(x.(*syntax.MatchExpr).Right, y, a)
+		e.pop()
+		return x
+
+	case *syntax.VarExpr:
+		if x.(*syntax.VarExpr).Name == a {
+			// NOTE/TODO: because substitution/evaluation
+			// are performed in-place, we can't just use the
+			// same y pointer for every occurence.
+			//
+			// However, if we knew they'd be only one use,
+			// then we could (optimization)
+			return syntax.Copy(y)
+		}
+		return x
+
+	case *syntax.AbsExpr:
+		name := x.(*syntax.AbsExpr).Name
+		if name == a {
+			return x
+		}
+		if !syntax.IsFree(y, name) {
+			e.push("right")
+			x.(*syntax.AbsExpr).Right = e.substitute(x.(*syntax.AbsExpr).Right, y, a)
+			e.pop()
+			return x
+		}
+		// bounded variable name of x occurs freely in y:
+		// if we're about so swap a for y below the current
+		// abstraction, we need to make sure our name won't
+		// conflict with what happens in y. Hence, we need
+		// to get a name which would conflict with nothing in
+		// x, y or a for that matter.
+		b := syntax.GetFresh(syntax.AllVars(x.(*syntax.AbsExpr).Right), syntax.AllVars(y), map[string]bool{a: true})
+		z := e.snapshot(x)
+		x.(*syntax.AbsExpr).Name = b
+		x.(*syntax.AbsExpr).Right = renameExpr(x.(*syntax.AbsExpr).Right, b, name)
+		e.emit(RuleAlpha, z, x)
+
+		e.push("right")
+		x.(*syntax.AbsExpr).Right = e.substitute(x.(*syntax.AbsExpr).Right, y, a)
+		e.pop()
+		return x
+
+	default:
+		panic("assert")
+	}
+
+	return nil
+}
+
+// Perform a single (normal order) reduction step on x;
+// the boolean is false if x is in normal form.
+func reduceExpr(x syntax.Expr) (syntax.Expr, bool) {
+	var e evaluator
+	e.root = &x
+	b := e.step(&x)
+	return x, b
+}
+
+// Contract the β-redex *p. Contracted redexes are immediately
+// replaced in their parent, so that *e.root is always consistent,
+// should we want to snapshot it.
+func (e *evaluator) beta(p *syntax.Expr) bool {
+	if e.stop || !e.allowed() {
+		return false
+	}
+
+	x := (*p).(*syntax.AppExpr)
+	z := e.snapshot(x)
+	n := e.redexSize(x)
+
+	e.push("left")
+	y := e.open(x.Left, x.Right)
+	e.pop()
+
+	*p = y
+	e.contracted(n, y)
+	e.emit(RuleBeta, z, y)
+	return true
+}
+
+// Body of the abstraction f, whose bound variable is substituted
+// by y, in either representation. The path leads to f.
+func (e *evaluator) open(f, y syntax.Expr) syntax.Expr {
+	e.push("right")
+	defer e.pop()
+
+	switch f.(type) {
+	case *syntax.AbsExpr:
+		return e.substitute(f.(*syntax.AbsExpr).Right, y, f.(*syntax.AbsExpr).Name)
+	case *syntax.DeBruijnAbsExpr:
+		return substituteDeBruijn(f.(*syntax.DeBruijnAbsExpr).Right, y, 0)
+	default:
+		panic("assert: " + reflect.ValueOf(f).Type().String())
+	}
+}
+
+// Contract the redex *p, let 〈x1, ..., xn〉 = 〈M, N〉 in P: x1 is
+// substituted by M, and the remaining names are bound to N, i.e.
+// xn is substituted by N if n = 2, or we're left with
+//
+//	let 〈x2, ..., xn〉 = N in P[M/x1]
+func (e *evaluator) letProduct(p *syntax.Expr) bool {
+	if e.stop || !e.allowed() {
+		return false
+	}
+
+	x := (*p).(*syntax.LetProductExpr)
+	z := e.snapshot(x)
+	n := e.redexSize(x)
+	m := x.Left.(*syntax.ProductExpr)
+
+	e.push("right")
+	y := e.open(x.Right, m.Left)
+	if x.N > 2 {
+		y = &syntax.LetProductExpr{syntax.Node{x.Type(), x.Span()}, x.N - 1, m.Right, y}
+	} else {
+		y = e.open(y, m.Right)
+	}
+	e.pop()
+
+	*p = y
+	e.contracted(n, y)
+	e.emit(RuleBeta, z, y)
+	return true
+}
+
+// Contract the redex *p, let x = M in N, to N[M/x]
+func (e *evaluator) let(p *syntax.Expr) bool {
+	if e.stop || !e.allowed() {
+		return false
+	}
+
+	x := (*p).(*syntax.LetExpr)
+	z := e.snapshot(x)
+	n := e.redexSize(x)
+
+	e.push("right")
+	y := e.open(x.Right, x.Left)
+	e.pop()
+
+	*p = y
+	e.contracted(n, y)
+	e.emit(RuleBeta, z, y)
+	return true
+}
+
+// Contract the redex *p, let * = * in P
+func (e *evaluator) letUnit(p *syntax.Expr) bool {
+	if e.stop || !e.allowed() {
+		return false
+	}
+
+	z := e.snapshot(*p)
+	n := e.redexSize(*p)
+	*p = (*p).(*syntax.LetUnitExpr).Right
+	e.contracted(n, *p)
+	e.emit(RuleBeta, z, *p)
+	return true
+}
+
+// Contract the redex *p, match inl M with inl x → N | inr y → P
+// (resp. inr M), to N[M/x] (resp. P[M/y])
+func (e *evaluator) match(p *syntax.Expr) bool {
+	if e.stop || !e.allowed() {
+		return false
+	}
+
+	x := (*p).(*syntax.MatchExpr)
+	z := e.snapshot(x)
+	n := e.redexSize(x)
+	m := x.X.(*syntax.InjExpr)
+
+	var y syntax.Expr
+	if m.Inr {
+		e.push("right")
+		y = e.open(x.Right, m.Right)
+	} else {
+		e.push("left")
+		y = e.open(x.Left, m.Right)
+	}
+	e.pop()
+
+	*p = y
+	e.contracted(n, y)
+	e.emit(RuleBeta, z, y)
+	return true
+}
+
+// Contract the δ-redex *p, whose operands are expected
+// to be literals.
+func (e *evaluator) delta(p *syntax.Expr) bool {
+	if e.stop || !e.allowed() {
+		return false
+	}
+
+	z := e.snapshot(*p)
+	n := e.redexSize(*p)
+	switch (*p).(type) {
+	case *syntax.UnaryExpr:
+		*p = evalUnaryExpr((*p).(*syntax.UnaryExpr))
+	case *syntax.BinaryExpr:
+		*p = evalBinaryExpr((*p).(*syntax.BinaryExpr))
+	case *syntax.IfExpr:
+		*p = evalIfExpr((*p).(*syntax.IfExpr))
+	default:
+		panic("assert: " + reflect.ValueOf(*p).Type().String())
+	}
+	e.contracted(n, *p)
+	e.emit(RuleDelta, z, *p)
+	return true
+}
+
+// NOTE: we're returning an Expr here.
+//
+// This is because computation is expected to stop on irreducible
+// lambda expressions at some point.
+//
+// See EvalOptions for bounded evaluation.
+func evalExpr(x syntax.Expr) syntax.Expr {
+	var e evaluator
+	y, _ := e.eval(x)
+	return y
+}
+
+// The error is a *LimitError, if any.
+func (e *evaluator) eval(x syntax.Expr) (syntax.Expr, error) {
+	if err := e.opts.check(); err != nil {
+		return nil, err
+	}
+
+	if e.opts.Stats != nil {
+		defer func() { *e.opts.Stats = EvalStats{e.n, e.saved} }()
+	}
+
+	// ascriptions, type abstractions/applications only
+	// matter to typing
+	x = syntax.Erase(x)
+
+	switch e.opts.Engine {
+	case CEKEngine, KrivineEngine, NeedEngine:
+		return e.runMachine(x)
+	}
+	if e.opts.Engine == DeBruijnEngine {
+		x = syntax.ToDeBruijn(x)
+	}
+	if e.opts.MaxSize > 0 {
+		e.size = syntax.SizeExpr(x)
+	}
+
+	e.root = &x
+	for e.step(&x) {
+	}
+
+	if e.opts.Engine == DeBruijnEngine {
+		x = syntax.FromDeBruijn(x)
+	}
+	if e.err != nil {
+		return x, &LimitError{e.err, e.n, x}
+	}
+	return x, nil
+}
@@ -0,0 +1,993 @@
+package eval_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mbivert/ftests"
+
+	. "github.com/mbivert/golc/eval"
+	"github.com/mbivert/golc/internal/testutil"
+	"github.com/mbivert/golc/syntax"
+)
+
+// Some of those aren't (yet?) properly typed
+func TestEvalRenameExpr(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"z | y, x",
+			RenameExpr,
+			[]any{testutil.MustParse("z"), "y", "x"},
+			[]any{
+				testutil.MustParse("z"),
+			},
+		},
+		{
+			"x | y, x",
+			RenameExpr,
+			[]any{testutil.MustParse("x"), "y", "x"},
+			[]any{
+				testutil.MustParse("y"),
+			},
+		},
+		{
+			"(x y) (y x z)  | y, x",
+			RenameExpr,
+			[]any{testutil.MustParse("(x y) (y x z)"), "y", "x"},
+			[]any{
+				testutil.MustParse("(y y) (y y z) "),
+			},
+		},
+		{
+			"λx. x z  | y, x",
+			RenameExpr,
+			[]any{testutil.MustParse("λx. x z"), "y", "x"},
+			[]any{
+				testutil.MustParse("λy. y z"),
+			},
+		},
+		{
+			"λx. x z  | y, y",
+			RenameExpr,
+			[]any{testutil.MustParse("λx. x z"), "y", "y"},
+			[]any{
+				testutil.MustParse("λx. x z"),
+			},
+		},
+		{
+			"λx. λy. y z foo bar  | z, x",
+			RenameExpr,
+			[]any{testutil.MustParse("λx. λy. y z foo bar"), "z", "x"},
+			[]any{
+				testutil.MustParse("λz. λy. y z foo bar"),
+			},
+		},
+		{
+			"λx. λy. y z foo bar  | foo, y",
+			RenameExpr,
+			[]any{testutil.MustParse("λx. λy. y z foo bar"), "foo", "y"},
+			[]any{
+				testutil.MustParse("λx. λfoo. foo z foo bar"),
+			},
+		},
+		{
+			"(2<3) && !(true) && 3. ≤. 5. (no changes expected) | y, x",
+			RenameExpr,
+			[]any{testutil.MustSTypeParse("(2<3) && !(true) && (3. ≤. 5.)"), "y", "x"},
+			[]any{
+				testutil.MustSTypeParse("(2<3) && !(true) && (3. ≤. 5.)"),
+			},
+		},
+		// NOTE: type checking doesn't like for x to be unbounded hence testutil.MustParse()
+		// instead of testutil.MustSTypeParse().
+		// TODO: there are plans to allow it, as x's type can be infered.
+		{
+			"(2<3) && !(true) && 3. ≤. x | y, x",
+			RenameExpr,
+			[]any{testutil.MustParse("(2<3) && !(true) && 3. ≤. x"), "y", "x"},
+			[]any{
+				testutil.MustParse("(2<3) && !(true) && 3. ≤. y"),
+			},
+		},
+		{
+			"λx:int. x+3",
+			RenameExpr,
+			[]any{testutil.MustSTypeParse("λx:int. x+3"), "y", "x"},
+			[]any{
+				testutil.MustSTypeParse("λy:int. y+3"),
+			},
+		},
+		{
+			"λf:int→int.x:int. f (x+3)",
+			RenameExpr,
+			[]any{testutil.MustSTypeParse("λf:int→int.x:int. f (x+3)"), "g", "f"},
+			[]any{
+				testutil.MustSTypeParse("λg:int→int.x:int. g (x+3)"),
+			},
+		},
+	})
+}
+
+func TestEvalSubstituteExpr(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"matching variable is substituted",
+			SubstituteExpr,
+			[]any{testutil.MustParse("x"), testutil.MustParse("λx. λy. x y"), "x"},
+			[]any{
+				testutil.MustParse("λx. λy. x y"),
+			},
+		},
+		{
+			"un-matching variable name",
+			SubstituteExpr,
+			[]any{testutil.MustParse("y"), testutil.MustParse("λx. λy. x y"), "x"},
+			[]any{
+				testutil.MustParse("y"),
+			},
+		},
+		{
+			"variable substituted in both parts of an apply",
+			SubstituteExpr,
+			[]any{testutil.MustParse("(x (x y))"), testutil.MustParse("λx. λy. x y"), "x"},
+			[]any{
+				testutil.MustParse("((λx. λy. x y) ((λx. λy. x y) y))"),
+			},
+		},
+		{
+			"bound variable not substituted",
+			SubstituteExpr,
+			[]any{testutil.MustParse("λx. λz. x z"), testutil.MustParse("λx. λy. x y"), "x"},
+			[]any{
+				testutil.MustParse("λx. λz. x z"),
+			},
+		},
+		{
+			"deeper bound variable not substituted",
+			SubstituteExpr,
+			[]any{testutil.MustParse("λx. λz. x z"), testutil.MustParse("λx. λy. x y"), "z"},
+			[]any{
+				testutil.MustParse("λx. λz. x z"),
+			},
+		},
+		{
+			"replacing a free variable, no conflict",
+			SubstituteExpr,
+			[]any{testutil.MustParse("λx. λy. x z"), testutil.MustParse("λx. λy. x y"), "z"},
+			[]any{
+				testutil.MustParse("λx. λy. x (λx. λy. x y)"),
+			},
+		},
+		{
+			"replacing a free variable, renaming",
+			SubstituteExpr,
+			[]any{testutil.MustParse("λx. λy. x z y"), testutil.MustParse("λx. λz. x y z"), "z"},
+			[]any{
+				testutil.MustParse("λx. λx0. x (λx. λz. x y z) x0"),
+			},
+		},
+		{
+			"replacing a free variable, renaming (bis)",
+			SubstituteExpr,
+			[]any{testutil.MustParse("λx. λy. x z y"), testutil.MustParse("λx. λz. x y z"), "z"},
+			[]any{
+				testutil.MustParse("λx. λx0. x (λx. λz. x y z) x0"),
+			},
+		},
+		{
+			"Selinger's example",
+			SubstituteExpr,
+			[]any{testutil.MustParse("λx. y x"), testutil.MustParse("λz. x z"), "y"},
+			[]any{
+				testutil.MustParse("λx0. (λz. x z) x0"),
+			},
+		},
+		{
+			"replacing bound variable by the variable to rename",
+			SubstituteExpr,
+			[]any{
+				testutil.MustParse(`
+					(λf. n.
+						((λy.
+							(
+								(λn. x. y. (n (λz. y) x))
+								n
+								(λf. x. (f x)) y))
+						(λx0.
+							(n (f (λf. x. (n (λg. h. (h (g f))) (λu. x) (λu. u))) x0)))))
+				`),
+				testutil.MustParse("f"),
+				"x0",
+			},
+			[]any{
+				testutil.MustParse(`
+					(λx1. n.
+						((λy.
+							(
+								(λn. x. y. (n (λz. y) x))
+								n
+								(λx1. x. (x1 x)) y))
+						(λx0.
+							(n (x1 (λx1. x. (n (λg. h. (h (g x1))) (λu. x) (λu. u))) x0)))))
+				`),
+			},
+		},
+		{
+			"don't re-use a name already used below",
+			SubstituteExpr,
+			[]any{
+				testutil.MustParse(`(λn. x0. y. (n (λz. y) x0))`),
+				testutil.MustParse(`
+					(λx0.
+						(n (x1 (λx1. x0.
+							(n (λg. h. (h (g x1))) (λu. x0) (λu. u))) x0)))
+				`),
+				"y",
+			},
+			[]any{
+				testutil.MustParse(`(λx2. x0. y. (x2 (λz. y) x0))`),
+			},
+		},
+		{
+			"\"complex\" substitute",
+			SubstituteExpr,
+			[]any{
+				testutil.MustParse(`
+					(λy.
+						(λp. λx. λy. p x y)
+						x
+						(λx. λy. x)
+						(
+							(λp. λx. λy. p x y)
+							y
+							(λx. λy. x)
+							(λx. λy. y)))
+				`),
+				testutil.MustParse(`(λx. λy. x)`),
+				"x",
+			},
+			[]any{
+				testutil.MustParse(`
+					(λy.
+						(λp. λx. λy. p x y)
+						(λx. λy. x)
+						(λx. λy. x)
+						(
+							(λp. λx. λy. p x y)
+							y
+							(λx. λy. x)
+							(λx. λy. y)))
+				`),
+			},
+		},
+		{
+			"\"complex\" substitute (bis)",
+			SubstituteExpr,
+			[]any{
+				testutil.MustParse(`
+					(λp. λx. λy. p x y)
+					(λx. λy. x)
+					(λx. λy. x)
+					(
+						(λp. λx. λy. p x y)
+						y
+						(λx. λy. x)
+						(λx. λy. y))
+				`),
+				testutil.MustParse(`(λx. λy. x)`),
+				"y",
+			},
+			[]any{
+				testutil.MustParse(`
+					(λp. λx. λy. p x y)
+					(λx. λy. x)
+					(λx. λy. x)
+					(
+						(λp. λx. λy. p x y)
+						(λx. λy. x)
+						(λx. λy. x)
+						(λx. λy. y))
+				`),
+			},
+		},
+		{
+			"\"complex\" substitute (ter)",
+			SubstituteExpr,
+			[]any{
+				testutil.MustParse(`((λx. λy. x) (λx. λy. x) y)`),
+				testutil.MustParse(`((λx. λy. x) (λx. λy. x) (λx. λy. y))`),
+				"y",
+			},
+			[]any{
+				testutil.MustParse(`
+				(λx. λy. x) (λx. λy. x)
+					((λx. λy. x) (λx. λy. x) (λx. λy. y))
+				`),
+			},
+		},
+		{
+			"\"complex\" substitute (xor, 1)",
+			SubstituteExpr,
+			[]any{
+				testutil.MustParse(`
+					((((λp. λx. λy. (p x y)) x)
+						((((λp. λx. λy. (p x y)) y) (λx. λy. y)) (λx. λy. x)))
+						((((λp. λx. λy. (p x y)) y) (λx. λy. x)) (λx. λy. y)))
+				`),
+				testutil.MustParse(`(λx. (λy. x))`),
+				"y",
+			},
+			[]any{
+				testutil.MustParse(`
+					((((λp. λx. λy. (p x y)) x)
+						((((λp. λx. λy. (p x y)) (λx. (λy. x))) (λx. λy. y)) (λx. λy. x)))
+						((((λp. λx. λy. (p x y)) (λx. (λy. x))) (λx. λy. x)) (λx. λy. y)))
+				`),
+			},
+		},
+		{
+			"\"complex\" substitute (xor, 2)",
+			SubstituteExpr,
+			[]any{
+				testutil.MustParse(`
+					(λy.
+						((((λp. λx. λy. (p x y)) x)
+							((((λp. λx. λy. (p x y)) y) (λx. λy. y)) (λx. λy. x)))
+							((((λp. λx. λy. (p x y)) y) (λx. λy. x)) (λx. λy. y))))
+						(λx. (λy. x))
+				`),
+				testutil.MustParse(`(λx. (λy. x))`),
+				"x",
+			},
+			[]any{
+				testutil.MustParse(`
+					(λy.
+						((((λp. λx. λy. (p x y)) (λx. (λy. x)))
+							((((λp. λx. λy. (p x y)) y) (λx. λy. y)) (λx. λy. x)))
+							((((λp. λx. λy. (p x y)) y) (λx. λy. x)) (λx. λy. y))))
+						(λx. (λy. x))
+				`),
+			},
+		},
+	})
+}
+
+func TestEvalArithmetic(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		/*
+			{
+				"empty input",
+				MustEval,
+				[]any{strings.NewReader(""), ""},
+				[]any{nil, diagErr(1, 1, 1, "Unexpected token: EOF")},
+			},
+		*/
+		{
+			"3+4",
+			testutil.MustEval,
+			[]any{testutil.MustSTypeParse("3+4")},
+			[]any{
+				&syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, 7},
+			},
+		},
+		{
+			"3+4*2",
+			testutil.MustEval,
+			[]any{testutil.MustSTypeParse("3+4*2")},
+			[]any{
+				&syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, 11},
+			},
+		},
+		{
+			"(3+4)*2",
+			testutil.MustEval,
+			[]any{testutil.MustSTypeParse("(3+4)*2")},
+			[]any{
+				&syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, 14},
+			},
+		},
+		{
+			"-.(1.5 *. 2.)",
+			testutil.MustEval,
+			[]any{testutil.MustSTypeParse("-.(1.5 *. 2.)")},
+			[]any{
+				&syntax.FloatExpr{syntax.Node{&syntax.FloatType{}, nil}, -3.},
+			},
+		},
+		{
+			"(2<3) && !(true)",
+			testutil.MustEval,
+			[]any{testutil.MustSTypeParse("(2<3) && !(true)")},
+			[]any{
+				&syntax.BoolExpr{syntax.Node{&syntax.BoolType{}, nil}, false},
+			},
+		},
+	})
+}
+
+// Evaluate s, unchecked; errors are compared as strings
+func evalErrStr(s string) string {
+	x, err := syntax.Parse(s, "f.lc")
+	if err == nil {
+		_, err = Eval(x)
+	}
+	return fmt.Sprint(err)
+}
+
+func TestEvalRuntimeErrors(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"division by zero",
+			evalErrStr,
+			[]any{"1 + 4 / (2 - 2)"},
+			[]any{"f.lc:1:5: runtime error: division by zero"},
+		},
+		{
+			"ill-typed binary operator",
+			evalErrStr,
+			[]any{"(λx. x *. 2.) 1"},
+			[]any{"f.lc:1:6: runtime error: *. expects float operands, got '1'"},
+		},
+		{
+			"ill-typed unary operator",
+			evalErrStr,
+			[]any{"-true"},
+			[]any{"f.lc:1:1: runtime error: - expects int operand, got 'true'"},
+		},
+		{
+			"ill-typed condition",
+			evalErrStr,
+			[]any{"if 1 then 2 else 3"},
+			[]any{"f.lc:1:1: runtime error: if expects a bool condition, got '1'"},
+		},
+		{
+			"ill-typed pair",
+			evalErrStr,
+			[]any{"(let 〈a, b〉 = 3 in a) + 1"},
+			[]any{"f.lc:1:2: runtime error: let 〈...〉 expects a pair, got '3'"},
+		},
+		{
+			"ill-typed unit",
+			evalErrStr,
+			[]any{"let * = 1. in 2"},
+			[]any{"f.lc:1:1: runtime error: let * expects *, got '1.0'"},
+		},
+		{
+			"ill-typed injection",
+			evalErrStr,
+			[]any{"match 3 with inl x → x | inr y → y"},
+			[]any{"f.lc:1:1: runtime error: match expects an injection, got '3'"},
+		},
+		{
+			"float division by zero is IEEE's",
+			evalErrStr,
+			[]any{"1. /. 0."},
+			[]any{"<nil>"},
+		},
+	})
+}
+
+func TestEvalLambdaMaths(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		/*
+			{
+				"empty input",
+				MustEval,
+				[]any{strings.NewReader(""), ""},
+				[]any{nil, diagErr(1, 1, 1, "Unexpected token: EOF")},
+			},
+		*/
+		{
+			"(λx:int. x+3) 5",
+			testutil.MustEval,
+			[]any{testutil.MustSTypeParse("(λx:int. x+3) 5")},
+			[]any{
+				&syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, 8},
+			},
+		},
+		{
+			"let f = (λx:int. x+3) in f 5",
+			testutil.MustEval,
+			[]any{testutil.MustSTypeParse("let f = (λx:int. x+3) : int → int in f 5")},
+			[]any{
+				&syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, 8},
+			},
+		},
+	})
+}
+
+func TestEvalBasicLambda(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"variable",
+			testutil.MustEval,
+			[]any{testutil.MustParse("x")},
+			[]any{
+				testutil.MustParse("x"),
+			},
+		},
+		{
+			"irreducible apply",
+			testutil.MustEval,
+			[]any{testutil.MustParse("x y")},
+			[]any{
+				testutil.MustParse("x y"),
+			},
+		},
+		{
+			"irreducible applies",
+			testutil.MustEval,
+			[]any{testutil.MustParse("x y z")},
+			[]any{
+				testutil.MustParse("x y z"),
+			},
+		},
+		{
+			"function call, single arg",
+			testutil.MustEval,
+			[]any{testutil.MustParse("(λx. x y) z")},
+			[]any{
+				testutil.MustParse("z y"),
+			},
+		},
+		{
+			"function call, two args",
+			testutil.MustEval,
+			[]any{testutil.MustParse("(λx. λy. x y) z z0")},
+			[]any{
+				testutil.MustParse("z z0"),
+			},
+		},
+		{
+			"and T F == F",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("land T F")},
+			[]any{
+				testutil.F,
+			},
+		},
+		{
+			"and F T == F",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("land F T")},
+			[]any{
+				testutil.F,
+			},
+		},
+		{
+			"and F F == F",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("land F F")},
+			[]any{
+				testutil.F,
+			},
+		},
+		{
+			"and T T == T",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("land T T")},
+			[]any{
+				testutil.T,
+			},
+		},
+		{
+			"not F == T",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("lnot F")},
+			[]any{
+				testutil.T,
+			},
+		},
+		{
+			"not T == F",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("lnot T")},
+			[]any{
+				testutil.F,
+			},
+		},
+		{
+			"let ... in ... -like",
+			testutil.MustEval,
+			[]any{testutil.MustParse(`
+				(
+					(λ zero.
+					(λ one.
+					(λ two.
+					(λ ifelse.
+					(λ iszero.
+					(
+						((ifelse (iszero one)) two) zero
+					)
+					) (λn. (λx. (λy. ((n (λz.y)) x))))
+					) (λp. (λx. (λy. ((p x) y))))
+					) (λf. (λx. (f (f x))))
+					) (λf. (λx. (f x)))
+					) (λf. (λx. x))
+				)
+			`)},
+			[]any{
+				testutil.MustParse("λf. (λx. x)"),
+			},
+		},
+		{
+			"let ... in ... -like (or T T)",
+			testutil.MustEval,
+			[]any{testutil.MustParse(`
+				(
+					(λ ifelse.
+					(λ F.
+					(λ T.
+					(λ Or.
+					(
+						(Or T) T
+					)
+					) (λx. (λy. (((ifelse x) T) (((ifelse y) T) F))))
+					) (λx. (λy. x))
+					) (λx. (λy. y))
+					) (λp. (λx. (λy. ((p x) y))))
+				)
+			`)},
+			[]any{
+				testutil.T,
+			},
+		},
+		{
+			"or T T == T (1)",
+			testutil.MustEval,
+			[]any{testutil.MustParse(`
+				((λx. λy. x) (λx. λy. x) ((λx. λy. x) (λx. λy. x) (λx. λy. y)))
+			`)},
+			[]any{
+				testutil.T,
+			},
+		},
+		{
+			"or T T == T (2)",
+			testutil.MustEval,
+			[]any{testutil.MustParse(`
+				((λx. λy. x) (λx. λy. x)
+					(
+						(λp. λx. λy. p x y)
+						(λx. λy. x)
+						(λx. λy. x)
+						(λx. λy. y)))
+			`)},
+			[]any{
+				testutil.T,
+			},
+		},
+		{
+			"or T T == T (3)",
+			testutil.MustEval,
+			[]any{testutil.MustParse(`
+				(λy. ((λx. λy. x) (λx. λy. x) y))
+					(
+						(λp. λx. λy. p x y)
+						(λx. λy. x)
+						(λx. λy. x)
+						(λx. λy. y))
+			`)},
+			[]any{
+				testutil.T,
+			},
+		},
+		{
+			"or T T == T (4)",
+			testutil.MustEval,
+			[]any{testutil.MustParse(`
+				(λp. λx. λy. p x y)
+					(λx. λy. x)
+					(λx. λy. x)
+					(
+						(λp. λx. λy. p x y)
+						(λx. λy. x)
+						(λx. λy. x)
+						(λx. λy. y))
+			`)},
+			[]any{
+				testutil.T,
+			},
+		},
+		{
+			"or T T == T (5)",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("lor T T")},
+			[]any{
+				testutil.T,
+			},
+		},
+		{
+			"or F T == T",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("lor F T")},
+			[]any{
+				testutil.T,
+			},
+		},
+		{
+			"or T F == T",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("lor T F")},
+			[]any{
+				testutil.T,
+			},
+		},
+		{
+			"or F F == F",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("lor F F")},
+			[]any{
+				testutil.F,
+			},
+		},
+		{
+			"xor F T == T",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("lxor F T")},
+			[]any{
+				testutil.T,
+			},
+		},
+		{
+			"xor T F == T",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("lxor T F")},
+			[]any{
+				testutil.T,
+			},
+		},
+		{
+			"xor F F == F",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("lxor F F")},
+			[]any{
+				testutil.F,
+			},
+		},
+		{
+			"xor T T == F",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("lxor T T")},
+			[]any{
+				testutil.F,
+			},
+		},
+		{
+			"succ zero == one",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("succ zero")},
+			[]any{
+				testutil.MustParseChurch("one"),
+			},
+		},
+		{
+			"succ (succ one) == three",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("succ (succ one)")},
+			[]any{
+				testutil.MustParseChurch("three"),
+			},
+		},
+		{
+			"add two three == add three two",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("add two three")},
+			[]any{
+				testutil.MustEval(testutil.MustParseChurch("add three two")),
+			},
+		},
+		{
+			"mult two three == add three three",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("mult two three")},
+			[]any{
+				testutil.MustEval(testutil.MustParseChurch("add three three")),
+			},
+		},
+		{
+			"iszero zero == T",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("iszero zero")},
+			[]any{
+				testutil.T,
+			},
+		},
+		{
+			"iszero one == T",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("iszero one")},
+			[]any{
+				testutil.F,
+			},
+		},
+		{
+			"iszero three == T",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("iszero three")},
+			[]any{
+				testutil.F,
+			},
+		},
+		{
+			"pred one == zero",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("pred one")},
+			[]any{
+				testutil.MustParseChurch("zero"),
+			},
+		},
+		{
+			"pred two == one",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("pred two")},
+			[]any{
+				testutil.MustParseChurch("one"),
+			},
+		},
+		{
+			"pred (pred three) == one",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("pred (pred three)")},
+			[]any{
+				testutil.MustParseChurch("one"),
+			},
+		},
+		{
+			"fact zero == one",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("fact zero")},
+			[]any{
+				testutil.MustParseChurch("one"),
+			},
+		},
+		{
+			"fact one == one",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("fact one")},
+			[]any{
+				testutil.MustParseChurch("one"),
+			},
+		},
+		{
+			"fact two == two",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("fact two")},
+			[]any{
+				testutil.MustParseChurch("two"),
+			},
+		},
+		{
+			"fact three == three * two * one == six",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("fact three")},
+			[]any{
+				testutil.MustEval(testutil.MustParseChurch("mult three two")),
+			},
+		},
+		{
+			"fact four == four * three * two",
+			testutil.MustEval,
+			[]any{testutil.MustParseChurch("fact four")},
+			[]any{
+				testutil.MustEval(testutil.MustParseChurch("mult (mult (add three one) three) two")),
+			},
+		},
+	})
+}
+
+/*
+	D/diff i t
+
+	∂/pdiff t x u
+
+	⊙/tmult
+
+	⊕/tadd // depends on whether we want to combine λ-calcs
+
+
+	1 ∂ λ
+
+*/
+
+// Trace x's evaluation, as printed events
+func traceExpr(x syntax.Expr, max int) []string {
+	var xs []string
+	EvalTrace(x, func(ev Event) bool {
+		xs = append(xs, ev.String())
+		return ev.N < max
+	})
+	return xs
+}
+
+func TestEvalTrace(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"normal form",
+			traceExpr,
+			[]any{testutil.MustParse("x"), 10},
+			[]any{[]string(nil)},
+		},
+		{
+			"β, δ",
+			traceExpr,
+			[]any{testutil.MustSTypeParse("(λx:int. x+3) (1*2)"), 10},
+			[]any{[]string{
+				"1: →β 1 * 2 + 3  [ε]",
+				"2: →δ 2 + 3  [left]",
+				"3: →δ 5  [ε]",
+			}},
+		},
+		{
+			"α-renaming",
+			traceExpr,
+			[]any{testutil.MustParse("(λx. λy. x) y"), 10},
+			[]any{[]string{
+				"1: ≡α λx0. x  [left.right]",
+				"1: →β λx0. y  [ε]",
+			}},
+		},
+		{
+			"stops early",
+			traceExpr,
+			[]any{testutil.MustParse("(λx. x x) (λx. x x)"), 2},
+			[]any{[]string{
+				"1: →β (λx. x x) (λx. x x)  [ε]",
+				"2: →β (λx. x x) (λx. x x)  [ε]",
+			}},
+		},
+	})
+}
+
+func TestEvalLimits(t *testing.T) {
+	omega := "(λx. x x) (λx. x x)"
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ftests.Run(t, []ftests.Test{
+		{
+			"within limits",
+			EvalWith,
+			[]any{testutil.MustParse("(λx. λy. x) a b"), EvalOptions{MaxSteps: 2, MaxSize: 7}},
+			[]any{testutil.MustParse("a"), nil},
+		},
+		{
+			"max steps",
+			EvalWith,
+			[]any{testutil.MustParse(omega), EvalOptions{MaxSteps: 3}},
+			[]any{testutil.MustParse(omega), &LimitError{ErrMaxSteps, 3, testutil.MustParse(omega)}},
+		},
+		{
+			"max size",
+			EvalWith,
+			[]any{testutil.MustParse("(λx. x x x) (λx. x x x)"), EvalOptions{MaxSize: 20}},
+			[]any{
+				testutil.MustParse("(λx. x x x) (λx. x x x) (λx. x x x) (λx. x x x)"),
+				&LimitError{ErrMaxSize, 2,
+					testutil.MustParse("(λx. x x x) (λx. x x x) (λx. x x x) (λx. x x x)")},
+			},
+		},
+		{
+			"max size, exceeded by the last step",
+			EvalWith,
+			[]any{testutil.MustParse("(λx. f x x x) (g a b c d)"), EvalOptions{MaxSize: 20}},
+			[]any{
+				testutil.MustParse("f (g a b c d) (g a b c d) (g a b c d)"),
+				&LimitError{ErrMaxSize, 1, testutil.MustParse("f (g a b c d) (g a b c d) (g a b c d)")},
+			},
+		},
+		{
+			"max size, tracked across steps",
+			EvalWith,
+			[]any{testutil.MustParse("(λx. x x) ((λy. y) (λz. z))"), EvalOptions{MaxSize: 10, Strategy: ApplicativeOrder}},
+			[]any{testutil.MustParse("λz. z"), nil},
+		},
+		{
+			"canceled context",
+			EvalWith,
+			[]any{testutil.MustParse(omega), EvalOptions{Context: canceled}},
+			[]any{testutil.MustParse(omega), &LimitError{context.Canceled, 0, testutil.MustParse(omega)}},
+		},
+	})
+}
@@ -0,0 +1,10 @@
+package eval
+
+// Internals, exposed to the (external) tests
+
+var (
+	RenameExpr         = renameExpr
+	SubstituteExpr     = substituteExpr
+	ShiftDeBruijn      = shiftDeBruijn
+	SubstituteDeBruijn = substituteDeBruijn
+)
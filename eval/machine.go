@@ -0,0 +1,681 @@
+/*
+ * Environment-based abstract machines: instead of substituting
+ * arguments in the body of abstractions (eval.go), variables are
+ * bound to values in an environment, and abstractions evaluate
+ * to closures. Two machines are available:
+ *
+ *	CEK	call-by-value (Felleisen, Friedman), with an
+ *		explicit continuation;
+ *	Krivine	call-by-name, where arguments are delayed as
+ *		thunks (closures of a term and its environment).
+ *
+ * The Krivine machine can also implement call-by-need (lazy
+ * evaluation): the environments then form a graph, where thunks
+ * are shared among all the variables bound to a given argument;
+ * a thunk is evaluated (at most) once, and its value memoised.
+ * Each reuse of a memoised thunk saves the reductions its
+ * evaluation took (see EvalStats.Saved).
+ *
+ * Both evaluate terms to weak head normal forms; normal forms
+ * are then read back into Expr, by applying the closures found
+ * along the way to fresh (neutral) variables, and evaluating
+ * the delayed arguments. The read-back normal forms are thus those
+ * of applicative order (CEK) and normal order (Krivine, with or
+ * without sharing): CEK will diverge where applicative order
+ * diverges (e.g. on fact, see lib/church.lc).
+ *
+ * Operators are strict in both machines; stuck operations (e.g.
+ * x+1, x being free) are neutral, as are applications of free
+ * variables or literals.
+ *
+ * MaxSteps and the context are honored; when hit, LimitError's
+ * Term is the original term. As there's no term to measure nor
+ * rewriting to trace, MaxSize and Trace are rejected, as are
+ * strategies other than the machine's (see Engine.Strategy()).
+ */
+package eval
+
+import (
+	"reflect"
+
+	"github.com/mbivert/golc/syntax"
+)
+
+// Machine values: closures, thunks (Krivine), pairs, injections,
+// neutral terms, and literals (*IntExpr, *FloatExpr, *BoolExpr,
+// *UnitExpr) which are stored as is.
+type value interface{}
+
+type vclosure struct {
+	abs *syntax.AbsExpr
+	env *menv
+}
+
+type vthunk struct {
+	x   syntax.Expr
+	env *menv
+
+	// call-by-need: memoised value, and how many steps
+	// it took to compute it, sharing aside
+	v     value
+	steps int
+}
+
+type vpair struct {
+	left, right value
+}
+
+// Operator applied to operand(s) which aren't literals;
+// left is nil for unary operators.
+type vstuck struct {
+	op          syntax.TokenKind
+	left, right value
+}
+
+// let 〈...〉 (n > 0 names left to bind, body being the closure
+// of their abstractions) or let * (n = 0, body being a thunk),
+// whose bound value v isn't a pair (resp. *).
+type vlet struct {
+	n       int
+	v, body value
+}
+
+// if whose condition isn't a literal; branches are thunks
+type vif struct {
+	cond, left, right value
+}
+
+// inl v, or inr v
+type vinj struct {
+	inr bool
+	v   value
+}
+
+// match whose scrutinee v isn't an injection; branches are
+// the closures of their abstractions
+type vmatch struct {
+	v, left, right value
+}
+
+// head is a free/fresh variable (*VarExpr), a literal, a
+// pair, an injection, a stuck operation, let, if or match.
+type vneutral struct {
+	head value
+	args []value
+}
+
+// Environment, as a linked list (so that closures can
+// share it)
+type menv struct {
+	name string
+	v    value
+	next *menv
+}
+
+func (m *menv) bind(n string, v value) *menv {
+	return &menv{n, v, m}
+}
+
+func (m *menv) lookup(n string) (value, bool) {
+	for ; m != nil; m = m.next {
+		if m.name == n {
+			return m.v, true
+		}
+	}
+	return nil, false
+}
+
+type machine interface {
+	// Evaluate x in env to a weak head normal form
+	run(x syntax.Expr, env *menv) value
+
+	// Evaluate a thunk to a weak head normal form
+	force(t *vthunk) value
+}
+
+// Thrown (panic) when a limit has been reached
+type limitHit struct{}
+
+// Count a β/δ step, making sure we can perform it
+func (e *evaluator) tick() {
+	if !e.allowed() {
+		panic(limitHit{})
+	}
+	e.n++
+}
+
+// δ-reduction on values (stuck if v isn't a literal); z is
+// the reduced expression, whose operand evaluates to v.
+func (e *evaluator) delta1(z *syntax.UnaryExpr, v value) value {
+	if x, ok := v.(syntax.Expr); ok && isLiteral(x) {
+		e.tick()
+		return evalUnaryExpr(&syntax.UnaryExpr{syntax.Node{nil, z.Loc}, z.Op, x})
+	}
+	return &vstuck{z.Op, nil, v}
+}
+
+func (e *evaluator) delta2(z *syntax.BinaryExpr, l, r value) value {
+	x, okl := l.(syntax.Expr)
+	y, okr := r.(syntax.Expr)
+	if okl && okr && isLiteral(x) && isLiteral(y) {
+		e.tick()
+		return evalBinaryExpr(&syntax.BinaryExpr{syntax.Node{nil, z.Loc}, z.Op, x, y})
+	}
+	return &vstuck{z.Op, l, r}
+}
+
+// Bind the names of z, i.e. of the abstractions in its body,
+// to the components of v. Returns the let's body and its
+// environment, or, if v isn't a (nested) pair, a stuck value.
+func (e *evaluator) matchPair(m machine, z *syntax.LetProductExpr, v value, env *menv) (syntax.Expr, *menv, value) {
+	body := z.Right
+	for n := z.N; n > 1; n-- {
+		if t, ok := v.(*vthunk); ok {
+			v = m.force(t)
+		}
+		p, ok := v.(*vpair)
+		if !ok {
+			e.checkStuck(z, v)
+			return nil, nil, &vlet{n, v, &vclosure{body.(*syntax.AbsExpr), env}}
+		}
+		e.tick()
+		env = env.bind(body.(*syntax.AbsExpr).Name, p.left)
+		body, v = body.(*syntax.AbsExpr).Right, p.right
+	}
+	return body.(*syntax.AbsExpr).Right, env.bind(body.(*syntax.AbsExpr).Name, v), nil
+}
+
+// Branch of z chosen by its condition's value v, if it's a
+// literal; env is z's environment.
+func (e *evaluator) branch(z *syntax.IfExpr, v value, env *menv) (syntax.Expr, *menv, bool) {
+	if x, ok := v.(syntax.Expr); ok && isLiteral(x) {
+		e.tick()
+		return evalIfExpr(&syntax.IfExpr{syntax.Node{nil, z.Loc}, x, z.Left, z.Right}), env, true
+	}
+	return z, env, false
+}
+
+// Branch of z chosen by its scrutinee's value v, if it's an
+// injection, along with its environment, where the branch's
+// name is bound to the injected value; env is z's environment.
+func (e *evaluator) choose(z *syntax.MatchExpr, v value, env *menv) (syntax.Expr, *menv, bool) {
+	i, ok := v.(*vinj)
+	if !ok {
+		e.checkStuck(z, v)
+		return z, env, false
+	}
+	e.tick()
+	b := z.Left.(*syntax.AbsExpr)
+	if i.inr {
+		b = z.Right.(*syntax.AbsExpr)
+	}
+	return b.Right, env.bind(b.Name, i.v), true
+}
+
+// Apply a value which isn't a closure
+func applyNeutral(f, v value) value {
+	if n, ok := f.(*vneutral); ok {
+		return &vneutral{n.head, append(n.args[:len(n.args):len(n.args)], v)}
+	}
+	return &vneutral{f, []value{v}}
+}
+
+type cek struct {
+	e *evaluator
+}
+
+// NOTE: values are never delayed in CEK
+func (m *cek) force(t *vthunk) value {
+	return m.run(t.x, t.env)
+}
+
+// Continuation frames: evaluate the argument x (kArg), then
+// apply f to it (kFun); evaluate the operand then apply x's op
+// (kUnary); evaluate the right operand, then apply x's op (kBinL,
+// kBinR); evaluate the right component, then pair (kPairL,
+// kPairR); evaluate the bound expression, then the body of a
+// let (kLet); evaluate the condition, then a branch (kIf);
+// evaluate the injected expression, then inject it (kInj);
+// evaluate the scrutinee, then a branch (kMatch).
+type kArg struct {
+	x   syntax.Expr
+	env *menv
+}
+
+type kFun struct {
+	f value
+}
+
+type kUnary struct {
+	x *syntax.UnaryExpr
+}
+
+type kBinL struct {
+	x   *syntax.BinaryExpr
+	env *menv
+}
+
+type kBinR struct {
+	x    *syntax.BinaryExpr
+	left value
+}
+
+type kPairL struct {
+	right syntax.Expr
+	env   *menv
+}
+
+type kPairR struct {
+	left value
+}
+
+type kLet struct {
+	x   syntax.Expr // *LetProductExpr, *LetUnitExpr
+	env *menv
+}
+
+type kIf struct {
+	x   *syntax.IfExpr
+	env *menv
+}
+
+type kInj struct {
+	inr bool
+}
+
+type kMatch struct {
+	x   *syntax.MatchExpr
+	env *menv
+}
+
+func (m *cek) run(x syntax.Expr, env *menv) value {
+	var k []any
+	var v value
+
+eval:
+	for {
+		switch x.(type) {
+		case *syntax.VarExpr:
+			var ok bool
+			if v, ok = env.lookup(x.(*syntax.VarExpr).Name); !ok {
+				v = &vneutral{syntax.Copy(x), nil}
+			}
+
+		case *syntax.AbsExpr:
+			v = &vclosure{x.(*syntax.AbsExpr), env}
+
+		case *syntax.UnitExpr, *syntax.IntExpr, *syntax.FloatExpr, *syntax.BoolExpr:
+			v = x
+
+		case *syntax.AppExpr:
+			k = append(k, &kArg{x.(*syntax.AppExpr).Right, env})
+			x = x.(*syntax.AppExpr).Left
+			continue eval
+
+		case *syntax.UnaryExpr:
+			k = append(k, &kUnary{x.(*syntax.UnaryExpr)})
+			x = x.(*syntax.UnaryExpr).Right
+			continue eval
+
+		case *syntax.BinaryExpr:
+			k = append(k, &kBinL{x.(*syntax.BinaryExpr), env})
+			x = x.(*syntax.BinaryExpr).Left
+			continue eval
+
+		case *syntax.ProductExpr:
+			k = append(k, &kPairL{x.(*syntax.ProductExpr).Right, env})
+			x = x.(*syntax.ProductExpr).Left
+			continue eval
+
+		case *syntax.LetProductExpr:
+			k = append(k, &kLet{x, env})
+			x = x.(*syntax.LetProductExpr).Left
+			continue eval
+
+		case *syntax.LetUnitExpr:
+			k = append(k, &kLet{x, env})
+			x = x.(*syntax.LetUnitExpr).Left
+			continue eval
+
+		case *syntax.IfExpr:
+			k = append(k, &kIf{x.(*syntax.IfExpr), env})
+			x = x.(*syntax.IfExpr).Cond
+			continue eval
+
+		case *syntax.InjExpr:
+			k = append(k, &kInj{x.(*syntax.InjExpr).Inr})
+			x = x.(*syntax.InjExpr).Right
+			continue eval
+
+		case *syntax.MatchExpr:
+			k = append(k, &kMatch{x.(*syntax.MatchExpr), env})
+			x = x.(*syntax.MatchExpr).X
+			continue eval
+
+		default:
+			panic("assert: " + reflect.ValueOf(x).Type().String())
+		}
+
+		// x has been evaluated to v: pass it to the continuation
+		for len(k) > 0 {
+			f := k[len(k)-1]
+			k = k[:len(k)-1]
+
+			switch f.(type) {
+			case *kArg:
+				k = append(k, &kFun{v})
+				x, env = f.(*kArg).x, f.(*kArg).env
+				continue eval
+
+			case *kFun:
+				if c, ok := f.(*kFun).f.(*vclosure); ok {
+					m.e.tick()
+					x, env = c.abs.Right, c.env.bind(c.abs.Name, v)
+					continue eval
+				}
+				v = applyNeutral(f.(*kFun).f, v)
+
+			case *kUnary:
+				v = m.e.delta1(f.(*kUnary).x, v)
+
+			case *kBinL:
+				k = append(k, &kBinR{f.(*kBinL).x, v})
+				x, env = f.(*kBinL).x.Right, f.(*kBinL).env
+				continue eval
+
+			case *kBinR:
+				v = m.e.delta2(f.(*kBinR).x, f.(*kBinR).left, v)
+
+			case *kPairL:
+				k = append(k, &kPairR{v})
+				x, env = f.(*kPairL).right, f.(*kPairL).env
+				continue eval
+
+			case *kPairR:
+				v = &vpair{f.(*kPairR).left, v}
+
+			case *kLet:
+				x, env = f.(*kLet).x, f.(*kLet).env
+				if y, ok := x.(*syntax.LetProductExpr); ok {
+					var w value
+					if x, env, w = m.e.matchPair(m, y, v, env); w == nil {
+						continue eval
+					}
+					v = w
+				} else if _, ok := v.(*syntax.UnitExpr); ok {
+					m.e.tick()
+					x = x.(*syntax.LetUnitExpr).Right
+					continue eval
+				} else {
+					m.e.checkStuck(x, v)
+					v = &vlet{0, v, &vthunk{x: x.(*syntax.LetUnitExpr).Right, env: env}}
+				}
+
+			case *kIf:
+				var ok bool
+				if x, env, ok = m.e.branch(f.(*kIf).x, v, f.(*kIf).env); ok {
+					continue eval
+				}
+				v = &vif{v,
+					&vthunk{x: f.(*kIf).x.Left, env: env},
+					&vthunk{x: f.(*kIf).x.Right, env: env},
+				}
+
+			case *kInj:
+				v = &vinj{f.(*kInj).inr, v}
+
+			case *kMatch:
+				var ok bool
+				if x, env, ok = m.e.choose(f.(*kMatch).x, v, f.(*kMatch).env); ok {
+					continue eval
+				}
+				v = &vmatch{v,
+					&vclosure{f.(*kMatch).x.Left.(*syntax.AbsExpr), env},
+					&vclosure{f.(*kMatch).x.Right.(*syntax.AbsExpr), env},
+				}
+			}
+		}
+
+		return v
+	}
+}
+
+type krivine struct {
+	e    *evaluator
+	need bool // call-by-need
+}
+
+func (m *krivine) force(t *vthunk) value {
+	if !m.need {
+		return m.run(t.x, t.env)
+	}
+	if t.v != nil {
+		m.e.saved += t.steps
+		return t.v
+	}
+	// steps a non-sharing evaluation would have performed
+	n := m.e.n + m.e.saved
+	t.v = m.run(t.x, t.env)
+	t.steps = m.e.n + m.e.saved - n
+	t.x, t.env = nil, nil
+	return t.v
+}
+
+func (m *krivine) run(x syntax.Expr, env *menv) value {
+	// arguments, the next one last
+	var s []value
+
+	// h applied to the arguments left on the stack
+	spine := func(h value) value {
+		for i := len(s) - 1; i >= 0; i-- {
+			h = applyNeutral(h, s[i])
+		}
+		return h
+	}
+
+	for {
+		switch x.(type) {
+		case *syntax.VarExpr:
+			v, ok := env.lookup(x.(*syntax.VarExpr).Name)
+			if !ok {
+				return spine(&vneutral{syntax.Copy(x), nil})
+			}
+			if t, ok := v.(*vthunk); ok {
+				if !m.need {
+					x, env = t.x, t.env
+					continue
+				}
+				v = m.force(t)
+			}
+			// memoised closure
+			if c, ok := v.(*vclosure); ok {
+				x, env = c.abs, c.env
+				continue
+			}
+			return spine(v)
+
+		case *syntax.AbsExpr:
+			if len(s) == 0 {
+				return &vclosure{x.(*syntax.AbsExpr), env}
+			}
+			m.e.tick()
+			env = env.bind(x.(*syntax.AbsExpr).Name, s[len(s)-1])
+			s = s[:len(s)-1]
+			x = x.(*syntax.AbsExpr).Right
+
+		case *syntax.AppExpr:
+			s = append(s, &vthunk{x: x.(*syntax.AppExpr).Right, env: env})
+			x = x.(*syntax.AppExpr).Left
+
+		case *syntax.UnitExpr, *syntax.IntExpr, *syntax.FloatExpr, *syntax.BoolExpr:
+			return spine(x)
+
+		case *syntax.UnaryExpr:
+			v := m.run(x.(*syntax.UnaryExpr).Right, env)
+			return spine(m.e.delta1(x.(*syntax.UnaryExpr), v))
+
+		case *syntax.BinaryExpr:
+			l := m.run(x.(*syntax.BinaryExpr).Left, env)
+			r := m.run(x.(*syntax.BinaryExpr).Right, env)
+			return spine(m.e.delta2(x.(*syntax.BinaryExpr), l, r))
+
+		case *syntax.ProductExpr:
+			return spine(&vpair{
+				&vthunk{x: x.(*syntax.ProductExpr).Left, env: env},
+				&vthunk{x: x.(*syntax.ProductExpr).Right, env: env},
+			})
+
+		case *syntax.LetProductExpr:
+			y := x.(*syntax.LetProductExpr)
+			var w value
+			if x, env, w = m.e.matchPair(m, y, m.run(y.Left, env), env); w != nil {
+				return spine(w)
+			}
+
+		case *syntax.LetUnitExpr:
+			y := x.(*syntax.LetUnitExpr)
+			v := m.run(y.Left, env)
+			if _, ok := v.(*syntax.UnitExpr); !ok {
+				m.e.checkStuck(y, v)
+				return spine(&vlet{0, v, &vthunk{x: y.Right, env: env}})
+			}
+			m.e.tick()
+			x = y.Right
+
+		case *syntax.IfExpr:
+			y := x.(*syntax.IfExpr)
+			v := m.run(y.Cond, env)
+			var ok bool
+			if x, env, ok = m.e.branch(y, v, env); !ok {
+				return spine(&vif{v,
+					&vthunk{x: y.Left, env: env},
+					&vthunk{x: y.Right, env: env},
+				})
+			}
+
+		case *syntax.InjExpr:
+			return spine(&vinj{x.(*syntax.InjExpr).Inr, &vthunk{x: x.(*syntax.InjExpr).Right, env: env}})
+
+		case *syntax.MatchExpr:
+			y := x.(*syntax.MatchExpr)
+			v := m.run(y.X, env)
+			var ok bool
+			if x, env, ok = m.e.choose(y, v, env); !ok {
+				return spine(&vmatch{v,
+					&vclosure{y.Left.(*syntax.AbsExpr), env},
+					&vclosure{y.Right.(*syntax.AbsExpr), env},
+				})
+			}
+
+		default:
+			panic("assert: " + reflect.ValueOf(x).Type().String())
+		}
+	}
+}
+
+// Read v back into a normal form. Fresh variables are named
+// after the original bound variables, unless they clash with
+// a name in scope (free variables of the original term, or
+// fresh variables introduced above).
+func readback(m machine, v value, scope map[string]bool) syntax.Expr {
+	switch v.(type) {
+	case *vclosure:
+		c := v.(*vclosure)
+		n := c.abs.Name
+		if scope[n] {
+			n = syntax.GetFresh(scope)
+		}
+		scope[n] = true
+		x := m.run(c.abs.Right, c.env.bind(c.abs.Name, &vneutral{&syntax.VarExpr{syntax.Node{}, n}, nil}))
+		y := readback(m, x, scope)
+		delete(scope, n)
+		return &syntax.AbsExpr{syntax.Node{}, syntax.CopyType(c.abs.Typ), n, y}
+
+	case *vthunk:
+		return readback(m, m.force(v.(*vthunk)), scope)
+
+	case *vpair:
+		return &syntax.ProductExpr{syntax.Node{},
+			readback(m, v.(*vpair).left, scope),
+			readback(m, v.(*vpair).right, scope),
+		}
+
+	case *vstuck:
+		if v.(*vstuck).left == nil {
+			return &syntax.UnaryExpr{syntax.Node{}, v.(*vstuck).op, readback(m, v.(*vstuck).right, scope)}
+		}
+		return &syntax.BinaryExpr{syntax.Node{}, v.(*vstuck).op,
+			readback(m, v.(*vstuck).left, scope),
+			readback(m, v.(*vstuck).right, scope),
+		}
+
+	case *vlet:
+		l := v.(*vlet)
+		if l.n == 0 {
+			return &syntax.LetUnitExpr{syntax.Node{}, readback(m, l.v, scope), readback(m, l.body, scope)}
+		}
+		return &syntax.LetProductExpr{syntax.Node{}, l.n, readback(m, l.v, scope), readback(m, l.body, scope)}
+
+	case *vif:
+		return &syntax.IfExpr{syntax.Node{},
+			readback(m, v.(*vif).cond, scope),
+			readback(m, v.(*vif).left, scope),
+			readback(m, v.(*vif).right, scope),
+		}
+
+	case *vinj:
+		return &syntax.InjExpr{syntax.Node{}, v.(*vinj).inr, readback(m, v.(*vinj).v, scope)}
+
+	case *vmatch:
+		return &syntax.MatchExpr{syntax.Node{},
+			readback(m, v.(*vmatch).v, scope),
+			readback(m, v.(*vmatch).left, scope),
+			readback(m, v.(*vmatch).right, scope),
+		}
+
+	case *vneutral:
+		x := readback(m, v.(*vneutral).head, scope)
+		for _, a := range v.(*vneutral).args {
+			x = &syntax.AppExpr{syntax.Node{}, x, readback(m, a, scope)}
+		}
+		return x
+
+	// *VarExpr (neutral heads)
+	// *UnitExpr
+	// *IntExpr
+	// *FloatExpr
+	// *BoolExpr
+	default:
+		return syntax.Copy(v.(syntax.Expr))
+	}
+}
+
+// Evaluate x to its normal form with one of the machines;
+// the error is a *LimitError, if any.
+func (e *evaluator) runMachine(x syntax.Expr) (y syntax.Expr, err error) {
+	var m machine
+	switch e.opts.Engine {
+	case CEKEngine:
+		m = &cek{e}
+	case KrivineEngine:
+		m = &krivine{e, false}
+	case NeedEngine:
+		m = &krivine{e, true}
+	default:
+		panic("assert: " + e.opts.Engine.String())
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(limitHit); !ok {
+				panic(r)
+			}
+			y, err = x, &LimitError{e.err, e.n, x}
+		}
+	}()
+
+	// let x = M in N is run as (λx. N) M
+	x = syntax.Desugar(x)
+
+	return readback(m, m.run(x, nil), syntax.FreeVars(x)), nil
+}
@@ -0,0 +1,257 @@
+package eval_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mbivert/ftests"
+
+	. "github.com/mbivert/golc/eval"
+	"github.com/mbivert/golc/internal/testutil"
+	"github.com/mbivert/golc/syntax"
+)
+
+// Options to evaluate with g, using its strategy
+func engineOpts(g Engine) EvalOptions {
+	st, _ := g.Strategy()
+	return EvalOptions{Strategy: st, Engine: g}
+}
+
+// Evaluate s with the given machine, and compare the result
+// with the normal form obtained by substitution
+func machineAgrees(s string, g Engine) (bool, error) {
+	y, err := EvalWith(testutil.MustParseChurch(s), engineOpts(g))
+	if err != nil {
+		return false, err
+	}
+	z := testutil.MustEval(testutil.MustParseChurch(s))
+	if p, ok := syntax.AlphaDiff(y, z); !ok {
+		return false, fmt.Errorf("%s ≠ %s at %s", y, z, p)
+	}
+	return true, nil
+}
+
+func evalMachine(s string, g Engine) syntax.Expr {
+	y, _ := EvalWith(testutil.MustParseChurch(s), engineOpts(g))
+	return y
+}
+
+func TestMachine(t *testing.T) {
+	omega := "((λx. x x) (λx. x x))"
+
+	var tests []ftests.Test
+	for _, g := range []Engine{CEKEngine, KrivineEngine, NeedEngine} {
+		for _, s := range []string{
+			"x",
+			"(λx. λy. x) y",
+			"λz. (λx. x) z",
+			"x ((λy. y) z)",
+			"(λx:int. λy:int. x * y + 1) 3 4",
+			"(λx:bool. !x && true) false",
+			"(λx:float. x *. 2.) 1.5",
+			"λx:int. x + (λy:int. y) 1",
+			"(λx. 〈x, λx. x〉) a",
+			"(λp. p (λx. λy. y)) (λf. f 1 2)",
+			"let 〈x, y, z〉 = 〈1, 2, 3〉 in x + y * z",
+			"(λp. let 〈x, y, z〉 = p in 〈z, x〉) 〈1, y〉",
+			"λp. let 〈x, y〉 = p in 〈y, x〉",
+			"(λu. let * = u in 1) (*)",
+			"let * = u in (λx. x) 1",
+			"let x = 1 + 2 in x * x",
+			"let f = λx. x + 1 in λy. f (f y)",
+			"(λx:int. if x > 0 then x else -x) (-3)",
+			"λx. if x then (λy. y) 1 else 2",
+			"match inr 〈1, 2〉 with inl x → x | inr p → let 〈x, y〉 = p in x + y",
+			"λs. match s with inl x → (λy. y) x | inr y → inl y",
+			"(λs. match s with inl x → x | inr y → 0) (inl ((λx. x) 1))",
+			"mult two",
+			"add two three",
+			"pred (pred three)",
+		} {
+			tests = append(tests, ftests.Test{
+				Name:     g.String() + ": " + s,
+				Fun:      machineAgrees,
+				Args:     []any{s, g},
+				Expected: []any{true, nil},
+			})
+		}
+	}
+
+	tests = append(tests, []ftests.Test{
+		{
+			"readable names",
+			evalMachine,
+			[]any{"(λf. λx. f (f x)) (λy. y) ", KrivineEngine},
+			[]any{testutil.MustParse("λx. x")},
+		},
+		{
+			"fresh names avoid free variables",
+			evalMachine,
+			[]any{"(λx. λy. x) y", CEKEngine},
+			[]any{testutil.MustParse("λx0. y")},
+		},
+		{
+			"krivine, discarded divergent argument",
+			evalMachine,
+			[]any{"(λx. y) " + omega, KrivineEngine},
+			[]any{testutil.MustParse("y")},
+		},
+		{
+			"cek, discarded divergent argument",
+			EvalWith,
+			[]any{testutil.MustParse("(λx. y) " + omega), EvalOptions{Strategy: ApplicativeOrder, Engine: CEKEngine, MaxSteps: 10}},
+			[]any{testutil.MustParse("(λx. y) " + omega),
+				&LimitError{ErrMaxSteps, 10, testutil.MustParse("(λx. y) " + omega)}},
+		},
+		{
+			"need, fact three",
+			machineAgrees,
+			[]any{"fact three", NeedEngine},
+			[]any{true, nil},
+		},
+		{
+			"need, recursion with a native if",
+			evalMachine,
+			[]any{"TFP (λf. λn. if n ≤ 1 then 1 else n * (f (n - 1))) 5", NeedEngine},
+			[]any{testutil.MustParse("120")},
+		},
+		{
+			"krivine, fact three",
+			machineAgrees,
+			[]any{"fact three", KrivineEngine},
+			[]any{true, nil},
+		},
+	}...)
+
+	ftests.Run(t, tests)
+}
+
+// Evaluation statistics
+func evalStats(s string, g Engine) (string, EvalStats) {
+	var st EvalStats
+	opts := engineOpts(g)
+	opts.Stats = &st
+	y, _ := EvalWith(testutil.MustParse(s), opts)
+	return y.String(), st
+}
+
+func TestMachineSharing(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"substitution, duplicated work",
+			evalStats,
+			[]any{"(λx:int. x + x) (1 + 2 * 3)", SubstEngine},
+			[]any{"14", EvalStats{6, 0}},
+		},
+		{
+			"krivine, duplicated work",
+			evalStats,
+			[]any{"(λx:int. x + x) (1 + 2 * 3)", KrivineEngine},
+			[]any{"14", EvalStats{6, 0}},
+		},
+		{
+			"need, shared work",
+			evalStats,
+			[]any{"(λx:int. x + x) (1 + 2 * 3)", NeedEngine},
+			[]any{"14", EvalStats{4, 2}},
+		},
+		{
+			"need, nested sharing",
+			evalStats,
+			[]any{"(λx:int. (λy:int. y + y) (x + x)) (1 + 1)", NeedEngine},
+			[]any{"8", EvalStats{5, 4}},
+		},
+		{
+			"need, unused argument",
+			evalStats,
+			[]any{"(λx:int. 1) (1 + 1)", NeedEngine},
+			[]any{"1", EvalStats{1, 0}},
+		},
+	})
+}
+
+func BenchmarkFactKrivine(b *testing.B) { benchmarkFact(b, KrivineEngine) }
+func BenchmarkFactNeed(b *testing.B)    { benchmarkFact(b, NeedEngine) }
+
+// fact diverges under call-by-value: compare all engines on
+// some plain Church arithmetic instead
+func benchmarkChurch(b *testing.B, g Engine) {
+	s := "mult three (add two three)"
+	for i := 0; i < b.N; i++ {
+		EvalWith(testutil.MustParseChurch(s), engineOpts(g))
+	}
+}
+
+func BenchmarkChurchSubst(b *testing.B)    { benchmarkChurch(b, SubstEngine) }
+func BenchmarkChurchDeBruijn(b *testing.B) { benchmarkChurch(b, DeBruijnEngine) }
+func BenchmarkChurchCEK(b *testing.B)      { benchmarkChurch(b, CEKEngine) }
+func BenchmarkChurchKrivine(b *testing.B)  { benchmarkChurch(b, KrivineEngine) }
+func BenchmarkChurchNeed(b *testing.B)     { benchmarkChurch(b, NeedEngine) }
+
+// Runtime errors are located, whatever the engine
+func TestMachineRuntimeErrors(t *testing.T) {
+	var tests []ftests.Test
+	for _, g := range []Engine{SubstEngine, DeBruijnEngine, CEKEngine, KrivineEngine, NeedEngine} {
+		for _, c := range [][2]string{
+			{"(λx. 1 + !x) 2", "f.lc:1:10: runtime error: ! expects bool operand, got '2'"},
+			{"(λx. let 〈a, b〉 = x in a) 3", "f.lc:1:6: runtime error: let 〈...〉 expects a pair, got '3'"},
+			{"let 〈a, b, c〉 = 〈a, 2〉 in a", "f.lc:1:1: runtime error: let 〈...〉 expects a pair, got '2'"},
+			{"let * = true in 1", "f.lc:1:1: runtime error: let * expects *, got 'true'"},
+			{"match * with inl x → x | inr y → y", "f.lc:1:1: runtime error: match expects an injection, got '*'"},
+		} {
+			tests = append(tests, ftests.Test{
+				Name: g.String(),
+				Fun: func(s string, g Engine) string {
+					x, err := syntax.Parse(s, "f.lc")
+					if err == nil {
+						_, err = EvalWith(x, engineOpts(g))
+					}
+					return fmt.Sprint(err)
+				},
+				Args:     []any{c[0], g},
+				Expected: []any{c[1]},
+			})
+		}
+	}
+	ftests.Run(t, tests)
+}
+
+// Machines only implement their own strategy, and can't
+// measure nor trace terms
+func TestMachineOptions(t *testing.T) {
+	x := testutil.MustParse("(λx. x) y")
+	trace := func(Event) bool { return true }
+
+	ftests.Run(t, []ftests.Test{
+		{
+			"cek, normal order",
+			EvalWith,
+			[]any{x, EvalOptions{Engine: CEKEngine}},
+			[]any{nil, fmt.Errorf("The cek engine only implements the applicative strategy, not normal")},
+		},
+		{
+			"krivine, call-by-value",
+			EvalWith,
+			[]any{x, EvalOptions{Strategy: CallByValue, Engine: KrivineEngine}},
+			[]any{nil, fmt.Errorf("The krivine engine only implements the normal strategy, not cbv")},
+		},
+		{
+			"need, size limit",
+			EvalWith,
+			[]any{x, EvalOptions{Engine: NeedEngine, MaxSize: 10}},
+			[]any{nil, fmt.Errorf("The need engine can't limit the term's size")},
+		},
+		{
+			"krivine, tracing",
+			EvalWith,
+			[]any{x, EvalOptions{Engine: KrivineEngine, Trace: trace}},
+			[]any{nil, fmt.Errorf("The krivine engine can't trace evaluation")},
+		},
+		{
+			"rewriting engines support all options",
+			EvalWith,
+			[]any{x, EvalOptions{Strategy: CallByValue, Engine: DeBruijnEngine, MaxSize: 10}},
+			[]any{testutil.MustParse("y"), nil},
+		},
+	})
+}
@@ -0,0 +1,304 @@
+/*
+ * Reduction strategies, following Selinger's "Lecture Notes on
+ * the Lambda Calculus" (papers/Selinger-Lambda-Calculus-Notes.pdf),
+ * which also motivates the terminology (redex, normal forms, etc.).
+ *
+ * Each strategy is implemented as a one-step reduction: step()
+ * locates the next redex according to the strategy, contracts
+ * it, and reports whether it found one. Evaluation then iterates
+ * step() until there are no more redexes, so the term reached is:
+ *
+ *	normal order		β-normal form
+ *	applicative order	β-normal form (when it terminates)
+ *	call-by-name		weak normal form
+ *	call-by-value		weak normal form (when it terminates)
+ *	head			head normal form
+ *	weak head		weak head normal form
+ *
+ * Where a weak normal form has no redex outside of an abstraction,
+ * a head normal form is of the shape λx1...λxn. y M1 ... Mk (the
+ * Mi being arbitrary), and a weak head normal form is either an
+ * abstraction or of the shape y M1 ... Mk.
+ *
+ * Normal order is the zero value: by the standardization theorem,
+ * it reaches a term's normal form whenever there is one. Applicative
+ * order on the other hand may diverge on terms having a normal form
+ * (e.g. (λx. y) Ω, or the Church encoded factorial, where both
+ * branches of the conditional get evaluated).
+ *
+ * Arithmetic/logic operators are strict in all strategies: their
+ * operands are reduced first (left to right), and the operator is
+ * δ-contracted once they're all literals. Products are values for
+ * the weak head/head strategies; their components are otherwise
+ * reduced as application arguments would be.
+ *
+ * let x = M in N is contracted as (λx. N) M would be.
+ *
+ * let 〈x, y〉 = M in N and let * = M in N are contracted once M
+ * has been reduced to a product (resp. *); they're otherwise
+ * handled as (λx. λy. N) M (resp. (λ_. N) M) would be.
+ *
+ * The condition of an if is reduced first, and the if δ-contracted
+ * once it's a literal, discarding the other branch, whatever the
+ * strategy. Should the condition be stuck, branches are reduced
+ * as (λ_. N) would be.
+ *
+ * Injections are values for the weak head/head strategies, as
+ * products are. Likewise, a match is contracted once its scrutinee
+ * is an injection; should it be stuck, its branches, which are
+ * abstractions, are reduced as arguments would be.
+ */
+package eval
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mbivert/golc/syntax"
+)
+
+type Strategy int
+
+const (
+	// Leftmost-outermost redex first, including under λ
+	NormalOrder Strategy = iota
+
+	// Leftmost-innermost redex first, including under λ: the
+	// function and its argument are normalized before the
+	// application is contracted.
+	ApplicativeOrder
+
+	// Leftmost-outermost redex first, but never under λ
+	CallByName
+
+	// Leftmost-innermost redex first, but never under λ: the
+	// argument is reduced to a value (an abstraction or a weak
+	// normal form) before the application is contracted.
+	CallByValue
+
+	// Head redex only, including under λ
+	HeadOrder
+
+	// Head redex only, never under λ
+	WeakHeadOrder
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case NormalOrder:
+		return "normal"
+	case ApplicativeOrder:
+		return "applicative"
+	case CallByName:
+		return "cbn"
+	case CallByValue:
+		return "cbv"
+	case HeadOrder:
+		return "head"
+	case WeakHeadOrder:
+		return "whnf"
+	}
+	return fmt.Sprintf("Strategy(%d)", int(s))
+}
+
+// Reduce under λ?
+func (s Strategy) underAbs() bool {
+	return s == NormalOrder || s == ApplicativeOrder || s == HeadOrder
+}
+
+// Contract the function and its argument before the
+// application itself?
+func (s Strategy) innermost() bool {
+	return s == ApplicativeOrder || s == CallByValue
+}
+
+// Reduce arguments which aren't in head position?
+func (s Strategy) args() bool {
+	return s != HeadOrder && s != WeakHeadOrder
+}
+
+// true if x is an abstraction, in either representation
+func isAbs(x syntax.Expr) bool {
+	switch x.(type) {
+	case *syntax.AbsExpr, *syntax.DeBruijnAbsExpr:
+		return true
+	}
+	return false
+}
+
+// Perform a single reduction step on *p, according to the
+// evaluator's strategy; false if there was no redex to contract
+// (or if we've been asked to stop).
+func (e *evaluator) step(p *syntax.Expr) bool {
+	if e.stop {
+		return false
+	}
+
+	s := e.opts.Strategy
+	x := *p
+
+	switch x.(type) {
+	// NOTE: "cannot fallthrough in type switch"
+	case *syntax.UnitExpr:
+		return false
+
+	case *syntax.IntExpr:
+		return false
+
+	case *syntax.FloatExpr:
+		return false
+
+	case *syntax.BoolExpr:
+		return false
+
+	case *syntax.VarExpr:
+		return false
+
+	case *syntax.DeBruijnBVarExpr:
+		return false
+
+	case *syntax.ProductExpr:
+		if !s.args() {
+			return false
+		}
+		return e.down("left", &x.(*syntax.ProductExpr).Left) ||
+			e.down("right", &x.(*syntax.ProductExpr).Right)
+
+	case *syntax.UnaryExpr:
+		if e.down("right", &x.(*syntax.UnaryExpr).Right) {
+			return true
+		}
+		if !isLiteral(x.(*syntax.UnaryExpr).Right) {
+			return false
+		}
+		return e.delta(p)
+
+	case *syntax.BinaryExpr:
+		if e.down("left", &x.(*syntax.BinaryExpr).Left) ||
+			e.down("right", &x.(*syntax.BinaryExpr).Right) {
+			return true
+		}
+		if !isLiteral(x.(*syntax.BinaryExpr).Left) || !isLiteral(x.(*syntax.BinaryExpr).Right) {
+			return false
+		}
+		return e.delta(p)
+
+	case *syntax.AbsExpr:
+		if !s.underAbs() {
+			return false
+		}
+		return e.down("right", &x.(*syntax.AbsExpr).Right)
+
+	case *syntax.DeBruijnAbsExpr:
+		if !s.underAbs() {
+			return false
+		}
+		return e.down("right", &x.(*syntax.DeBruijnAbsExpr).Right)
+
+	case *syntax.AppExpr:
+		if s.innermost() {
+			if e.down("left", &x.(*syntax.AppExpr).Left) {
+				return true
+			}
+			if e.down("right", &x.(*syntax.AppExpr).Right) {
+				return true
+			}
+			if isAbs(x.(*syntax.AppExpr).Left) {
+				return e.beta(p)
+			}
+			return false
+		}
+
+		if isAbs(x.(*syntax.AppExpr).Left) {
+			return e.beta(p)
+		}
+		if e.down("left", &x.(*syntax.AppExpr).Left) {
+			return true
+		}
+		return s.args() && e.down("right", &x.(*syntax.AppExpr).Right)
+
+	// As for applications, where the names are the function and
+	// the bound expression the argument.
+	case *syntax.LetProductExpr:
+		if s.innermost() {
+			if e.down("left", &x.(*syntax.LetProductExpr).Left) ||
+				e.down("right", &x.(*syntax.LetProductExpr).Right) {
+				return true
+			}
+			if _, ok := x.(*syntax.LetProductExpr).Left.(*syntax.ProductExpr); ok {
+				return e.letProduct(p)
+			}
+			e.checkStuck(x, x.(*syntax.LetProductExpr).Left)
+			return false
+		}
+
+		if _, ok := x.(*syntax.LetProductExpr).Left.(*syntax.ProductExpr); ok {
+			return e.letProduct(p)
+		}
+		if e.down("left", &x.(*syntax.LetProductExpr).Left) {
+			return true
+		}
+		e.checkStuck(x, x.(*syntax.LetProductExpr).Left)
+		return s.args() && e.down("right", &x.(*syntax.LetProductExpr).Right)
+
+	// As (λx. N) M: always a redex
+	case *syntax.LetExpr:
+		if s.innermost() && (e.down("right", &x.(*syntax.LetExpr).Right) ||
+			e.down("left", &x.(*syntax.LetExpr).Left)) {
+			return true
+		}
+		return e.let(p)
+
+	case *syntax.LetUnitExpr:
+		if s.innermost() {
+			if e.down("left", &x.(*syntax.LetUnitExpr).Left) ||
+				(s.underAbs() && e.down("right", &x.(*syntax.LetUnitExpr).Right)) {
+				return true
+			}
+			if _, ok := x.(*syntax.LetUnitExpr).Left.(*syntax.UnitExpr); ok {
+				return e.letUnit(p)
+			}
+			e.checkStuck(x, x.(*syntax.LetUnitExpr).Left)
+			return false
+		}
+
+		if _, ok := x.(*syntax.LetUnitExpr).Left.(*syntax.UnitExpr); ok {
+			return e.letUnit(p)
+		}
+		if e.down("left", &x.(*syntax.LetUnitExpr).Left) {
+			return true
+		}
+		e.checkStuck(x, x.(*syntax.LetUnitExpr).Left)
+		return s.args() && s.underAbs() && e.down("right", &x.(*syntax.LetUnitExpr).Right)
+
+	case *syntax.IfExpr:
+		if e.down("cond", &x.(*syntax.IfExpr).Cond) {
+			return true
+		}
+		if isLiteral(x.(*syntax.IfExpr).Cond) {
+			return e.delta(p)
+		}
+		return s.args() && s.underAbs() &&
+			(e.down("left", &x.(*syntax.IfExpr).Left) || e.down("right", &x.(*syntax.IfExpr).Right))
+
+	case *syntax.InjExpr:
+		if !s.args() {
+			return false
+		}
+		return e.down("right", &x.(*syntax.InjExpr).Right)
+
+	case *syntax.MatchExpr:
+		if e.down("x", &x.(*syntax.MatchExpr).X) {
+			return true
+		}
+		if _, ok := x.(*syntax.MatchExpr).X.(*syntax.InjExpr); ok {
+			return e.match(p)
+		}
+		e.checkStuck(x, x.(*syntax.MatchExpr).X)
+		return s.args() &&
+			(e.down("left", &x.(*syntax.MatchExpr).Left) || e.down("right", &x.(*syntax.MatchExpr).Right))
+
+	default:
+		panic("assert: " + reflect.ValueOf(x).Type().String())
+	}
+}
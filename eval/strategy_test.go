@@ -0,0 +1,179 @@
+package eval_test
+
+import (
+	"testing"
+
+	"github.com/mbivert/ftests"
+
+	. "github.com/mbivert/golc/eval"
+	"github.com/mbivert/golc/internal/testutil"
+	"github.com/mbivert/golc/syntax"
+)
+
+// Evaluate s with the given strategy, giving up after
+// a few steps; nil if we gave up.
+func evalStrategy(s string, st Strategy) syntax.Expr {
+	y, err := EvalWith(testutil.MustParseChurch(s), EvalOptions{Strategy: st, MaxSteps: 1000})
+	if err != nil {
+		return nil
+	}
+	return y
+}
+
+func TestStrategy(t *testing.T) {
+	omega := "((λx. x x) (λx. x x))"
+	fact2 := "fact two"
+
+	ftests.Run(t, []ftests.Test{
+		{
+			"normal order, discarded divergent argument",
+			evalStrategy,
+			[]any{"(λx. y) " + omega, NormalOrder},
+			[]any{testutil.MustParse("y")},
+		},
+		{
+			"applicative order, discarded divergent argument",
+			evalStrategy,
+			[]any{"(λx. y) " + omega, ApplicativeOrder},
+			[]any{syntax.Expr(nil)},
+		},
+		{
+			"call-by-name, discarded divergent argument",
+			evalStrategy,
+			[]any{"(λx. y) " + omega, CallByName},
+			[]any{testutil.MustParse("y")},
+		},
+		{
+			"call-by-value, discarded divergent argument",
+			evalStrategy,
+			[]any{"(λx. y) " + omega, CallByValue},
+			[]any{syntax.Expr(nil)},
+		},
+		{
+			"weak head, let 〈...〉 of a redex",
+			evalStrategy,
+			[]any{"let 〈x, y, z〉 = 〈a, (λp. p) 〈b, c〉〉 in z", WeakHeadOrder},
+			[]any{testutil.MustParse("c")},
+		},
+		{
+			"call-by-name, stuck let *",
+			evalStrategy,
+			[]any{"let * = u in (λx. x) 1", CallByName},
+			[]any{testutil.MustParse("let * = u in (λx. x) 1")},
+		},
+		{
+			"call-by-value, let of a divergent expression",
+			evalStrategy,
+			[]any{"let x = " + omega + " in y", CallByValue},
+			[]any{syntax.Expr(nil)},
+		},
+		{
+			"call-by-name, let of a divergent expression",
+			evalStrategy,
+			[]any{"let x = " + omega + " in y", CallByName},
+			[]any{testutil.MustParse("y")},
+		},
+		{
+			"applicative order, discarded if branch",
+			evalStrategy,
+			[]any{"if 1 ≤ 2 then y else " + omega, ApplicativeOrder},
+			[]any{testutil.MustParse("y")},
+		},
+		{
+			"call-by-value, discarded match branch",
+			evalStrategy,
+			[]any{"match inl 1 with inl x → x | inr y → " + omega, CallByValue},
+			[]any{testutil.MustParse("1")},
+		},
+		{
+			"weak head, injections are values",
+			evalStrategy,
+			[]any{"(λx. inl x) ((λy. y) 1)", WeakHeadOrder},
+			[]any{testutil.MustParse("inl ((λy. y) 1)")},
+		},
+		{
+			"normal order, stuck match",
+			evalStrategy,
+			[]any{"match s with inl x → (λy. y) x | inr y → y", NormalOrder},
+			[]any{testutil.MustParse("match s with inl x → x | inr y → y")},
+		},
+		{
+			"normal order, stuck if",
+			evalStrategy,
+			[]any{"if x then (λy. y) 1 else 2", NormalOrder},
+			[]any{testutil.MustParse("if x then 1 else 2")},
+		},
+		{
+			"call-by-value, stuck if",
+			evalStrategy,
+			[]any{"if x then (λy. y) 1 else 2", CallByValue},
+			[]any{testutil.MustParse("if x then (λy. y) 1 else 2")},
+		},
+		{
+			"normal order, under λ",
+			evalStrategy,
+			[]any{"λz. (λx. x) z", NormalOrder},
+			[]any{testutil.MustParse("λz. z")},
+		},
+		{
+			"call-by-name, not under λ",
+			evalStrategy,
+			[]any{"λz. (λx. x) z", CallByName},
+			[]any{testutil.MustParse("λz. (λx. x) z")},
+		},
+		{
+			"head, under λ",
+			evalStrategy,
+			[]any{"λz. (λx. x) z", HeadOrder},
+			[]any{testutil.MustParse("λz. z")},
+		},
+		{
+			"weak head, not under λ",
+			evalStrategy,
+			[]any{"λz. (λx. x) z", WeakHeadOrder},
+			[]any{testutil.MustParse("λz. (λx. x) z")},
+		},
+		{
+			"call-by-name, arguments",
+			evalStrategy,
+			[]any{"x ((λy. y) z)", CallByName},
+			[]any{testutil.MustParse("x z")},
+		},
+		{
+			"head, arguments",
+			evalStrategy,
+			[]any{"λw. x ((λy. y) z)", HeadOrder},
+			[]any{testutil.MustParse("λw. x ((λy. y) z)")},
+		},
+		{
+			"weak head, arguments",
+			evalStrategy,
+			[]any{"x ((λy. y) z)", WeakHeadOrder},
+			[]any{testutil.MustParse("x ((λy. y) z)")},
+		},
+		{
+			"call-by-value, argument reduced to a value",
+			evalStrategy,
+			[]any{"(λx. x) ((λy. y) (λy. (λz. z) y))", CallByValue},
+			[]any{testutil.MustParse("λy. (λz. z) y")},
+		},
+		{
+			"weak head, δ",
+			evalStrategy,
+			[]any{"(λx. x + 1) 2", WeakHeadOrder},
+			[]any{testutil.MustParse("3")},
+		},
+		{
+			"normal order, fact two",
+			evalStrategy,
+			[]any{fact2, NormalOrder},
+			[]any{testutil.MustParseChurch("two")},
+		},
+		{
+			"applicative order, fact two",
+			evalStrategy,
+			[]any{fact2, ApplicativeOrder},
+			[]any{syntax.Expr(nil)},
+		},
+	})
+}
@@ -0,0 +1,90 @@
+/*
+ * Reduction tracing: the evaluator (eval.go) can report every
+ * rewriting it performs (β, δ, α) to a callback, along with
+ * where it happened and what the whole term looks like
+ * afterwards.
+ */
+package eval
+
+import (
+	"fmt"
+
+	"github.com/mbivert/golc/syntax"
+)
+
+// Rewriting rule
+type Rule int
+
+const (
+	RuleBeta  Rule = iota // (λx.M) N → M[N/x], or a let, let 〈...〉/let */match contraction
+	RuleDelta             // arithmetic/logic operator, or if, on literals
+	RuleAlpha             // bound variable renamed to avoid a capture
+)
+
+func (r Rule) String() string {
+	switch r {
+	case RuleBeta:
+		return "β"
+	case RuleDelta:
+		return "δ"
+	case RuleAlpha:
+		return "α"
+	}
+	return fmt.Sprintf("Rule(%d)", int(r))
+}
+
+// A single rewriting.
+//
+// α events occur while substituting for a β step: their path
+// goes through the β-redex's abstraction (left.right), and Term
+// is nil, as the whole term is then in an intermediate state.
+// N is the number of the β step to come.
+type Event struct {
+	N      int // step number, starting at 1
+	Rule   Rule
+	Path   syntax.Path // to the redex, at the time it's contracted
+	Redex  syntax.Expr
+	Result syntax.Expr // contractum
+	Term   syntax.Expr // whole term, after the rewriting
+}
+
+func (ev Event) String() string {
+	if ev.Rule == RuleAlpha {
+		return fmt.Sprintf("%d: ≡α %s  [%s]", ev.N, ev.Result, ev.Path)
+	}
+	return fmt.Sprintf("%d: →%s %s  [%s]", ev.N, ev.Rule, ev.Term, ev.Path)
+}
+
+// Copy x, only if it's going to be traced.
+func (e *evaluator) snapshot(x syntax.Expr) syntax.Expr {
+	if e.trace == nil {
+		return nil
+	}
+	return syntax.Copy(x)
+}
+
+func (e *evaluator) emit(r Rule, redex, result syntax.Expr) {
+	if r != RuleAlpha {
+		e.n++
+	}
+	if e.trace == nil {
+		return
+	}
+
+	ev := Event{
+		N:      e.n,
+		Rule:   r,
+		Path:   append(syntax.Path{}, e.path...),
+		Redex:  redex,
+		Result: syntax.Copy(result),
+	}
+	if r == RuleAlpha {
+		ev.N++
+	} else {
+		ev.Term = syntax.Copy(*e.root)
+	}
+
+	if !e.trace(ev) {
+		e.stop = true
+	}
+}
@@ -4,8 +4,9 @@ go 1.22.5
 
 toolchain go1.23.1
 
+require github.com/mbivert/ftests v1.0.0
+
 require (
-	github.com/mbivert/ftests v1.0.0 // indirect
 	golang.org/x/mod v0.19.0 // indirect
 	golang.org/x/sync v0.7.0 // indirect
 	golang.org/x/tools v0.23.0 // indirect
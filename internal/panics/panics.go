@@ -0,0 +1,18 @@
+/*
+ * The implementation reports errors by panicking; the public
+ * APIs (syntax, types, eval) turn those panics back into errors.
+ */
+package panics
+
+import "fmt"
+
+// Turn a panic into an error; to be deferred.
+func Catch(err *error) {
+	if x := recover(); x != nil {
+		if e, ok := x.(error); ok {
+			*err = e
+		} else {
+			*err = fmt.Errorf("%v", x)
+		}
+	}
+}
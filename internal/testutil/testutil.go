@@ -0,0 +1,72 @@
+/*
+ * Helpers shared by the tests: they panic on errors, rather
+ * than returning them.
+ */
+package testutil
+
+import (
+	"github.com/mbivert/golc/lib"
+
+	"github.com/mbivert/golc/eval"
+	"github.com/mbivert/golc/syntax"
+	"github.com/mbivert/golc/types"
+)
+
+// To ease tests so far; the spans are dropped, so that
+// parsed expressions can be compared (reflect.DeepEqual())
+// with built or evaluated ones.
+func MustParse(src string) syntax.Expr {
+	x, err := syntax.Parse(src, "")
+	if err != nil {
+		panic(err)
+	}
+	return syntax.Unlocate(x)
+}
+
+// To ease tests so far
+func MustParseProgram(src, fn string) *syntax.Program {
+	q, err := syntax.ParseProgram(src, fn)
+	if err != nil {
+		panic(err)
+	}
+	for _, d := range q.Decls {
+		syntax.Unlocate(d.X)
+	}
+	syntax.Unlocate(q.Main)
+	return q
+}
+
+// To ease tests so far
+func MustSType(x syntax.Expr) syntax.Expr {
+	if _, err := types.Check(x); err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// To ease tests so far
+func MustSTypeParse(s string) syntax.Expr {
+	return MustSType(MustParse(s))
+}
+
+// Church encodings (see lib/church.lc)
+var Church = MustParseProgram(lib.Church, "lib/church.lc")
+
+// Parse src, inlining the Church encodings it refers to
+// (e.g. "fact two"); to ease tests.
+func MustParseChurch(src string) syntax.Expr {
+	return eval.Inline(Church, MustParse(src))
+}
+
+// True, False (see lib/church.lc)
+var T = eval.Inline(Church, &syntax.VarExpr{syntax.Node{}, "T"})
+var F = eval.Inline(Church, &syntax.VarExpr{syntax.Node{}, "F"})
+
+// x's normal form (see Eval)
+func MustEval(x syntax.Expr) syntax.Expr {
+	y, err := eval.Eval(x)
+	if err != nil {
+		panic(err)
+	}
+	return y
+}
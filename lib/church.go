@@ -0,0 +1,12 @@
+/*
+ * The library's sources, embedded so that they can be used
+ * without the source tree at hand (e.g. by tests).
+ */
+package lib
+
+import _ "embed"
+
+// Church encodings: booleans, numerals, fixed-point combinator
+//
+//go:embed church.lc
+var Church string
@@ -0,0 +1,250 @@
+/*
+ * Public API: scanning, parsing and printing; the AST itself
+ * is in parser.go, and can also be built with the constructors
+ * below. The scanner, parser and printer proper are unexported.
+ *
+ * The pipeline is: Scan (optional) → Parse → types.Check →
+ * eval.Eval, or ParseProgram → types.CheckProgram → Program.Bind →
+ * eval.Eval for source files. None of those panic: errors
+ * (including "runtime" errors, e.g. when evaluating an ill-typed,
+ * unchecked expression) are returned.
+ *
+ * Scanning and parsing errors are Diagnostics (diag.go), which
+ * hold all the errors found in the source. Parsed expressions
+ * remember where they come from (Span), so that type and runtime
+ * errors on them are *Diagnostic as well.
+ *
+ * NOTE: types.Check and eval.Eval operate in-place; Copy
+ * beforehand if the original expression is still needed.
+ */
+package syntax
+
+import (
+	"fmt"
+
+	"github.com/mbivert/golc/internal/panics"
+)
+
+// Scan src; fn is only used for error messages.
+func Scan(src, fn string) (toks []Token, err error) {
+	defer panics.Catch(&err)
+	return scanAll(src, fn)
+}
+
+// Parse src as a single expression; fn is only used for
+// error messages. Syntax errors are reported as Diagnostics.
+func Parse(src, fn string) (x Expr, err error) {
+	defer panics.Catch(&err)
+	return parse(src, fn)
+}
+
+// Parse a top-level definition, "let $x = $M [: $T]" with no "in".
+// The returned name is empty if src isn't such a definition, in
+// which case it should be parsed with Parse.
+//
+// t is nil if no type was specified.
+func ParseDef(src, fn string) (n string, x Expr, t Type, err error) {
+	defer panics.Catch(&err)
+	n, x, t, err = parseDef(src, fn)
+	if _, ok := t.(*UnknownType); ok {
+		t = nil
+	}
+	return n, x, t, err
+}
+
+// Parse src as a program: top-level declarations, followed
+// by an optional expression (see program.go).
+func ParseProgram(src, fn string) (q *Program, err error) {
+	defer panics.Catch(&err)
+	return parseProgram(src, fn)
+}
+
+// x, t printed in the given style; String() uses UnicodeStyle
+func Format(x Expr, s Style) string     { return printer{s, 0}.expr(x) }
+func FormatType(t Type, s Style) string { return printer{s, 0}.typ(t) }
+
+// As Format(), breaking lines so as to fit in width columns
+// where possible.
+func FormatWidth(x Expr, s Style, width int) string {
+	return printer{s, width}.expr(x)
+}
+
+// Constructors. Literals are already typed.
+func NewIntExpr(v int64) *IntExpr {
+	return &IntExpr{Node{&IntType{}, nil}, v}
+}
+
+func NewFloatExpr(v float64) *FloatExpr {
+	return &FloatExpr{Node{&FloatType{}, nil}, v}
+}
+
+func NewBoolExpr(v bool) *BoolExpr {
+	return &BoolExpr{Node{&BoolType{}, nil}, v}
+}
+
+func NewUnitExpr() *UnitExpr {
+	return &UnitExpr{Node{&UnitType{}, nil}}
+}
+
+func NewVarExpr(name string) *VarExpr {
+	return &VarExpr{Node{}, name}
+}
+
+// t may be nil (no annotation)
+func NewAbsExpr(name string, t Type, right Expr) *AbsExpr {
+	if t == nil {
+		t = &UnknownType{}
+	}
+	return &AbsExpr{Node{}, t, name, right}
+}
+
+func NewAppExpr(left, right Expr) *AppExpr {
+	return &AppExpr{Node{}, left, right}
+}
+
+func NewProductExpr(left, right Expr) *ProductExpr {
+	return &ProductExpr{Node{}, left, right}
+}
+
+// let 〈names〉 = left in body; there should be at least two names
+func NewLetProductExpr(names []string, left, body Expr) *LetProductExpr {
+	for i := len(names) - 1; i >= 0; i-- {
+		body = &AbsExpr{Node{}, &UnknownType{}, names[i], body}
+	}
+	return &LetProductExpr{Node{}, len(names), left, body}
+}
+
+// let name = left : t in body; t may be nil (no annotation)
+func NewLetExpr(name string, t Type, left, body Expr) *LetExpr {
+	return &LetExpr{Node{}, left, NewAbsExpr(name, t, body)}
+}
+
+// let * = left in right
+func NewLetUnitExpr(left, right Expr) *LetUnitExpr {
+	return &LetUnitExpr{Node{}, left, right}
+}
+
+// if cond then left else right
+func NewIfExpr(cond, left, right Expr) *IfExpr {
+	return &IfExpr{Node{}, cond, left, right}
+}
+
+// inl right, or inr right
+func NewInjExpr(inr bool, right Expr) *InjExpr {
+	return &InjExpr{Node{}, inr, right}
+}
+
+// match x with inl l → left | inr r → right
+func NewMatchExpr(x Expr, l string, left Expr, r string, right Expr) *MatchExpr {
+	return &MatchExpr{Node{}, x,
+		&AbsExpr{Node{}, &UnknownType{}, l, left},
+		&AbsExpr{Node{}, &UnknownType{}, r, right},
+	}
+}
+
+// (x : t)
+func NewAnnotExpr(x Expr, t Type) *AnnotExpr {
+	return &AnnotExpr{Node{}, x, t}
+}
+
+// Λname. right
+func NewTypeAbsExpr(name string, right Expr) *TypeAbsExpr {
+	return &TypeAbsExpr{Node{}, name, right}
+}
+
+// left [t]
+func NewTypeAppExpr(left Expr, t Type) *TypeAppExpr {
+	return &TypeAppExpr{Node{}, left, t}
+}
+
+// operators, by their string representation
+var unaryOps = map[string]TokenKind{}
+var binaryOps = map[string]TokenKind{}
+
+func init() {
+	for _, k := range []TokenKind{
+		TokenPlus, TokenMinus, TokenFPlus, TokenFMinus, TokenExcl,
+	} {
+		unaryOps[k.String()] = k
+	}
+	for k := range opPrecs {
+		binaryOps[k.String()] = k
+	}
+}
+
+// op is e.g. "-", "-." or "!"
+func NewUnaryExpr(op string, right Expr) (*UnaryExpr, error) {
+	k, ok := unaryOps[op]
+	if !ok {
+		return nil, fmt.Errorf("Unknown unary operator '%s'", op)
+	}
+	return &UnaryExpr{Node{}, k, right}, nil
+}
+
+// op is e.g. "+", "≤." or "&&"
+func NewBinaryExpr(op string, left, right Expr) (*BinaryExpr, error) {
+	k, ok := binaryOps[op]
+	if !ok {
+		return nil, fmt.Errorf("Unknown binary operator '%s'", op)
+	}
+	return &BinaryExpr{Node{}, k, left, right}, nil
+}
+
+func NewArrowType(left, right Type) *ArrowType {
+	return &ArrowType{left, right}
+}
+
+func NewProductType(left, right Type) *ProductType {
+	return &ProductType{left, right}
+}
+
+func NewSumType(left, right Type) *SumType {
+	return &SumType{left, right}
+}
+
+func NewLinArrowType(left, right Type) *LinArrowType {
+	return &LinArrowType{left, right}
+}
+
+func NewTensorType(left, right Type) *TensorType {
+	return &TensorType{left, right}
+}
+
+func NewBangType(t Type) *BangType {
+	return &BangType{t}
+}
+
+func NewVarType(name string) *VarType {
+	return &VarType{name}
+}
+
+// ∀name. t
+func NewForallType(name string, t Type) *ForallType {
+	return &ForallType{name, t}
+}
+
+// Accessors, for what isn't a plain field.
+
+// Bound variable's type annotation (nil if none)
+func (e *AbsExpr) ArgType() Type {
+	if _, ok := e.Typ.(*UnknownType); ok {
+		return nil
+	}
+	return e.Typ
+}
+
+func (e *LetExpr) Name() string { n, _, _ := letBinder(e); return n }
+func (e *LetExpr) Body() Expr   { _, _, y := letBinder(e); return y }
+
+// Bound variable's type annotation (nil if none)
+func (e *LetExpr) ArgType() Type {
+	if _, t, _ := letBinder(e); t != nil {
+		if _, ok := t.(*UnknownType); !ok {
+			return t
+		}
+	}
+	return nil
+}
+
+func (e *LetProductExpr) Names() []string { ns, _ := letNames(e); return ns }
+func (e *LetProductExpr) Body() Expr      { _, y := letNames(e); return y }
@@ -0,0 +1,187 @@
+package syntax_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mbivert/ftests"
+
+	. "github.com/mbivert/golc/syntax"
+)
+
+func mustBinaryExpr(op string, l, r Expr) Expr {
+	x, err := NewBinaryExpr(op, l, r)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+func TestAPIScan(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"λx. x",
+			Scan,
+			[]any{"λx. x", ""},
+			[]any{[]Token{
+				{TokenLambda, 1, 1, "λ"},
+				{TokenName, 1, 2, "x"},
+				{TokenDot, 1, 3, "."},
+				{TokenName, 1, 5, "x"},
+				{TokenEOF, 1, 6, ""},
+			}, nil},
+		},
+	})
+}
+
+// Parse, dropping the spans so that the result can be compared
+// with built expressions
+func parseUnlocated(src, fn string) (Expr, error) {
+	x, err := Parse(src, fn)
+	return Unlocate(x), err
+}
+
+func parseDefUnlocated(src, fn string) (string, Expr, Type, error) {
+	n, x, t, err := ParseDef(src, fn)
+	return n, Unlocate(x), t, err
+}
+
+func TestAPIParseDef(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"let x = 1 : int",
+			parseDefUnlocated,
+			[]any{"let x = 1 : int", ""},
+			[]any{"x", NewIntExpr(1), &IntType{}, nil},
+		},
+		{
+			"let x = 1",
+			parseDefUnlocated,
+			[]any{"let x = 1", ""},
+			[]any{"x", NewIntExpr(1), nil, nil},
+		},
+	})
+}
+
+func TestAPIConstructors(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"abstraction",
+			parseUnlocated,
+			[]any{"λx:int. x + 3", ""},
+			[]any{
+				NewAbsExpr("x", &IntType{},
+					mustBinaryExpr("+", NewVarExpr("x"), NewIntExpr(3)),
+				),
+				nil,
+			},
+		},
+		{
+			"application, product",
+			parseUnlocated,
+			[]any{"f 〈true, 1.5〉", ""},
+			[]any{
+				NewAppExpr(
+					NewVarExpr("f"),
+					NewProductExpr(NewBoolExpr(true), NewFloatExpr(1.5)),
+				),
+				nil,
+			},
+		},
+		{
+			"let 〈...〉, let *",
+			parseUnlocated,
+			[]any{"let 〈x, y〉 = p in let * = u in x", ""},
+			[]any{
+				NewLetProductExpr([]string{"x", "y"}, NewVarExpr("p"),
+					NewLetUnitExpr(NewVarExpr("u"), NewVarExpr("x")),
+				),
+				nil,
+			},
+		},
+		{
+			"injection, match",
+			parseUnlocated,
+			[]any{"match inl 1 with inl x → x | inr y → 0", ""},
+			[]any{
+				NewMatchExpr(NewInjExpr(false, NewIntExpr(1)),
+					"x", NewVarExpr("x"), "y", NewIntExpr(0),
+				),
+				nil,
+			},
+		},
+		{
+			"ascription",
+			parseUnlocated,
+			[]any{"(λx. x + 1 : int → int)", ""},
+			[]any{
+				NewAnnotExpr(
+					NewAbsExpr("x", nil,
+						mustBinaryExpr("+", NewVarExpr("x"), NewIntExpr(1))),
+					NewArrowType(&IntType{}, &IntType{}),
+				),
+				nil,
+			},
+		},
+		{
+			"let",
+			parseUnlocated,
+			[]any{"let x = 1 : int in x + 1", ""},
+			[]any{
+				NewLetExpr("x", &IntType{}, NewIntExpr(1),
+					mustBinaryExpr("+", NewVarExpr("x"), NewIntExpr(1)),
+				),
+				nil,
+			},
+		},
+		{
+			"unknown operator",
+			NewBinaryExpr,
+			[]any{"%", NewIntExpr(1), NewIntExpr(2)},
+			[]any{(*BinaryExpr)(nil), fmt.Errorf("Unknown binary operator '%%'")},
+		},
+	})
+}
+
+// Walk an expression through the accessors
+func describe(x Expr) string {
+	switch x := x.(type) {
+	case *IntExpr:
+		return fmt.Sprint(x.Value)
+	case *VarExpr:
+		return x.Name
+	case *AbsExpr:
+		return fmt.Sprintf("abs(%s, %v, %s)", x.Name, x.ArgType(), describe(x.Right))
+	case *AppExpr:
+		return fmt.Sprintf("app(%s, %s)", describe(x.Left), describe(x.Right))
+	case *BinaryExpr:
+		return fmt.Sprintf("bin(%s, %s, %s)", x.Op, describe(x.Left), describe(x.Right))
+	case *LetProductExpr:
+		return fmt.Sprintf("let(%v, %s, %s)", x.Names(), describe(x.Left), describe(x.Body()))
+	}
+	return "?"
+}
+
+func TestAPIAccessors(t *testing.T) {
+	x, err := Parse("(λf:int → int. f (2 * 3)) (λx. x)", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ftests.Run(t, []ftests.Test{
+		{
+			"describe",
+			describe,
+			[]any{x},
+			[]any{"app(abs(f, int → int, app(f, bin(*, 2, 3))), abs(x, <nil>, x))"},
+		},
+		{
+			"describe, let 〈...〉",
+			func(src string) string {
+				y, _ := Parse(src, "")
+				return describe(y)
+			},
+			[]any{"let 〈x, y〉 = p in x + y"},
+			[]any{"let([x y], p, bin(+, x, y))"},
+		},
+	})
+}
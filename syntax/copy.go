@@ -0,0 +1,208 @@
+/*
+ * Deep copies of types and expressions; spans, which are
+ * immutable, are shared.
+ */
+package syntax
+
+// Deep copy of t
+func CopyType(t Type) Type {
+	switch t.(type) {
+	case *VarType:
+		return &VarType{t.(*VarType).Name}
+
+	case *ArrowType:
+		return &ArrowType{
+			CopyType(t.(*ArrowType).Left),
+			CopyType(t.(*ArrowType).Right),
+		}
+
+	case *ProductType:
+		return &ProductType{
+			CopyType(t.(*ProductType).Left),
+			CopyType(t.(*ProductType).Right),
+		}
+
+	case *SumType:
+		return &SumType{
+			CopyType(t.(*SumType).Left),
+			CopyType(t.(*SumType).Right),
+		}
+
+	case *LinArrowType:
+		return &LinArrowType{
+			CopyType(t.(*LinArrowType).Left),
+			CopyType(t.(*LinArrowType).Right),
+		}
+
+	case *TensorType:
+		return &TensorType{
+			CopyType(t.(*TensorType).Left),
+			CopyType(t.(*TensorType).Right),
+		}
+
+	case *BangType:
+		return &BangType{CopyType(t.(*BangType).T)}
+
+	case *ForallType:
+		return &ForallType{t.(*ForallType).Name, CopyType(t.(*ForallType).T)}
+
+	// "iotas" (unit / primitive types)
+	case *UnitType:
+		return &UnitType{}
+	case *BoolType:
+		return &BoolType{}
+	case *IntType:
+		return &IntType{}
+	case *FloatType:
+		return &FloatType{}
+
+	case *UnknownType:
+		return &UnknownType{}
+
+	default:
+		return nil
+		//		panic("O__o: "+reflect.ValueOf(t).Type().String())
+	}
+
+	return t
+}
+
+// Deep copy of x
+func Copy(x Expr) Expr {
+	switch x.(type) {
+	case *UnitExpr:
+		return &UnitExpr{Node{CopyType(x.Type()), x.Span()}}
+	case *IntExpr:
+		return &IntExpr{Node{CopyType(x.Type()), x.Span()}, x.(*IntExpr).Value}
+	case *FloatExpr:
+		return &FloatExpr{Node{CopyType(x.Type()), x.Span()}, x.(*FloatExpr).Value}
+	case *BoolExpr:
+		return &BoolExpr{Node{CopyType(x.Type()), x.Span()}, x.(*BoolExpr).Value}
+	case *ProductExpr:
+		return &ProductExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			Copy(x.(*ProductExpr).Left),
+			Copy(x.(*ProductExpr).Right),
+		}
+
+	case *UnaryExpr:
+		return &UnaryExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			x.(*UnaryExpr).Op,
+			Copy(x.(*UnaryExpr).Right),
+		}
+
+	case *BinaryExpr:
+		return &BinaryExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			x.(*BinaryExpr).Op,
+			Copy(x.(*BinaryExpr).Left),
+			Copy(x.(*BinaryExpr).Right),
+		}
+
+	case *AppExpr:
+		return &AppExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			Copy(x.(*AppExpr).Left),
+			Copy(x.(*AppExpr).Right),
+		}
+
+	case *VarExpr:
+		return &VarExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			x.(*VarExpr).Name,
+		}
+
+	case *AbsExpr:
+		return &AbsExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			CopyType(x.(*AbsExpr).Typ),
+			x.(*AbsExpr).Name,
+			Copy(x.(*AbsExpr).Right),
+		}
+
+	case *DeBruijnBVarExpr:
+		return &DeBruijnBVarExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			x.(*DeBruijnBVarExpr).N,
+		}
+
+	case *DeBruijnAbsExpr:
+		return &DeBruijnAbsExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			CopyType(x.(*DeBruijnAbsExpr).Typ),
+			Copy(x.(*DeBruijnAbsExpr).Right),
+		}
+
+	case *LetProductExpr:
+		return &LetProductExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			x.(*LetProductExpr).N,
+			Copy(x.(*LetProductExpr).Left),
+			Copy(x.(*LetProductExpr).Right),
+		}
+
+	case *LetExpr:
+		return &LetExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			Copy(x.(*LetExpr).Left),
+			Copy(x.(*LetExpr).Right),
+		}
+
+	case *LetUnitExpr:
+		return &LetUnitExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			Copy(x.(*LetUnitExpr).Left),
+			Copy(x.(*LetUnitExpr).Right),
+		}
+
+	case *IfExpr:
+		return &IfExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			Copy(x.(*IfExpr).Cond),
+			Copy(x.(*IfExpr).Left),
+			Copy(x.(*IfExpr).Right),
+		}
+
+	case *InjExpr:
+		return &InjExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			x.(*InjExpr).Inr,
+			Copy(x.(*InjExpr).Right),
+		}
+
+	case *AnnotExpr:
+		return &AnnotExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			Copy(x.(*AnnotExpr).X),
+			CopyType(x.(*AnnotExpr).Typ),
+		}
+
+	case *TypeAbsExpr:
+		return &TypeAbsExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			x.(*TypeAbsExpr).Name,
+			Copy(x.(*TypeAbsExpr).Right),
+		}
+
+	case *TypeAppExpr:
+		return &TypeAppExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			Copy(x.(*TypeAppExpr).Left),
+			CopyType(x.(*TypeAppExpr).Typ),
+		}
+
+	case *MatchExpr:
+		return &MatchExpr{
+			Node{CopyType(x.Type()), x.Span()},
+			Copy(x.(*MatchExpr).X),
+			Copy(x.(*MatchExpr).Left),
+			Copy(x.(*MatchExpr).Right),
+		}
+
+	default:
+		panic("assert")
+	}
+
+	return nil
+}
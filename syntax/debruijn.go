@@ -0,0 +1,320 @@
+/*
+ * Nameless (de Bruijn) representation: bound variables are
+ * replaced by the number of abstractions between them and
+ * their binder, e.g. λx. λy. x y is λ. λ. #1 #0.
+ *
+ * Only variables and abstractions have a specific node; free
+ * variables stay VarExpr, and other nodes are shared with the
+ * named representation (let 〈...〉 and match bind their
+ * names with abstractions).
+ *
+ * The evaluator works on this representation when asked to
+ * (see ../eval/debruijn.go).
+ *
+ * See also https://plfa.github.io/DeBruijn/
+ */
+package syntax
+
+import "fmt"
+
+// Bound variable, 0 referring to the closest abstraction
+type DeBruijnBVarExpr struct {
+	Node
+	N int
+}
+
+type DeBruijnAbsExpr struct {
+	Node
+	// bound variable's type (see AbsExpr.Typ)
+	Typ   Type
+	Right Expr
+}
+
+func (e *DeBruijnBVarExpr) String() string {
+	return fmt.Sprintf("#%d", e.N)
+}
+
+func (e *DeBruijnAbsExpr) String() string {
+	return printer{}.expr(e)
+}
+
+// Convert x to its nameless representation; x is left
+// untouched.
+func ToDeBruijn(x Expr) Expr {
+	var aux func(Expr, []string) Expr
+
+	// bound variables, innermost last
+	aux = func(x Expr, bs []string) Expr {
+		switch x.(type) {
+		case *VarExpr:
+			for i := len(bs) - 1; i >= 0; i-- {
+				if bs[i] == x.(*VarExpr).Name {
+					return &DeBruijnBVarExpr{Node{CopyType(x.Type()), x.Span()}, len(bs) - 1 - i}
+				}
+			}
+			return Copy(x)
+
+		case *AbsExpr:
+			return &DeBruijnAbsExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				CopyType(x.(*AbsExpr).Typ),
+				aux(x.(*AbsExpr).Right, append(bs[:len(bs):len(bs)], x.(*AbsExpr).Name)),
+			}
+
+		case *AppExpr:
+			return &AppExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				aux(x.(*AppExpr).Left, bs),
+				aux(x.(*AppExpr).Right, bs),
+			}
+
+		case *ProductExpr:
+			return &ProductExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				aux(x.(*ProductExpr).Left, bs),
+				aux(x.(*ProductExpr).Right, bs),
+			}
+
+		case *LetProductExpr:
+			return &LetProductExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				x.(*LetProductExpr).N,
+				aux(x.(*LetProductExpr).Left, bs),
+				aux(x.(*LetProductExpr).Right, bs),
+			}
+
+		case *LetExpr:
+			return &LetExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				aux(x.(*LetExpr).Left, bs),
+				aux(x.(*LetExpr).Right, bs),
+			}
+
+		case *LetUnitExpr:
+			return &LetUnitExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				aux(x.(*LetUnitExpr).Left, bs),
+				aux(x.(*LetUnitExpr).Right, bs),
+			}
+
+		case *IfExpr:
+			return &IfExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				aux(x.(*IfExpr).Cond, bs),
+				aux(x.(*IfExpr).Left, bs),
+				aux(x.(*IfExpr).Right, bs),
+			}
+
+		case *InjExpr:
+			return &InjExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				x.(*InjExpr).Inr,
+				aux(x.(*InjExpr).Right, bs),
+			}
+
+		case *AnnotExpr:
+			return &AnnotExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				aux(x.(*AnnotExpr).X, bs),
+				CopyType(x.(*AnnotExpr).Typ),
+			}
+
+		case *TypeAbsExpr:
+			return &TypeAbsExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				x.(*TypeAbsExpr).Name,
+				aux(x.(*TypeAbsExpr).Right, bs),
+			}
+
+		case *TypeAppExpr:
+			return &TypeAppExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				aux(x.(*TypeAppExpr).Left, bs),
+				CopyType(x.(*TypeAppExpr).Typ),
+			}
+
+		case *MatchExpr:
+			return &MatchExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				aux(x.(*MatchExpr).X, bs),
+				aux(x.(*MatchExpr).Left, bs),
+				aux(x.(*MatchExpr).Right, bs),
+			}
+
+		case *UnaryExpr:
+			return &UnaryExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				x.(*UnaryExpr).Op,
+				aux(x.(*UnaryExpr).Right, bs),
+			}
+
+		case *BinaryExpr:
+			return &BinaryExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				x.(*BinaryExpr).Op,
+				aux(x.(*BinaryExpr).Left, bs),
+				aux(x.(*BinaryExpr).Right, bs),
+			}
+
+		// *UnitExpr
+		// *IntExpr
+		// *FloatExpr
+		// *BoolExpr
+		default:
+			return Copy(x)
+		}
+	}
+
+	return aux(x, nil)
+}
+
+// Names given to bound variables by FromDeBruijn(), in
+// order of preference, before falling back to GetFresh().
+var deBruijnNames = []string{"x", "y", "z", "w", "u", "v"}
+
+// Convert x back to a named representation; x is left
+// untouched. Bound variables are given the first name which
+// is neither a free variable of x, nor bound by an enclosing
+// abstraction.
+func FromDeBruijn(x Expr) Expr {
+	var aux func(Expr, []string) Expr
+
+	fv := FreeVars(x)
+
+	fresh := func(bs []string) string {
+		m := map[string]bool{}
+		for _, b := range bs {
+			m[b] = true
+		}
+		for _, n := range deBruijnNames {
+			if !fv[n] && !m[n] {
+				return n
+			}
+		}
+		return GetFresh(fv, m)
+	}
+
+	aux = func(x Expr, bs []string) Expr {
+		switch x.(type) {
+		case *DeBruijnBVarExpr:
+			return &VarExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				bs[len(bs)-1-x.(*DeBruijnBVarExpr).N],
+			}
+
+		case *DeBruijnAbsExpr:
+			n := fresh(bs)
+			return &AbsExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				CopyType(x.(*DeBruijnAbsExpr).Typ),
+				n,
+				aux(x.(*DeBruijnAbsExpr).Right, append(bs[:len(bs):len(bs)], n)),
+			}
+
+		case *AppExpr:
+			return &AppExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				aux(x.(*AppExpr).Left, bs),
+				aux(x.(*AppExpr).Right, bs),
+			}
+
+		case *ProductExpr:
+			return &ProductExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				aux(x.(*ProductExpr).Left, bs),
+				aux(x.(*ProductExpr).Right, bs),
+			}
+
+		case *LetProductExpr:
+			return &LetProductExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				x.(*LetProductExpr).N,
+				aux(x.(*LetProductExpr).Left, bs),
+				aux(x.(*LetProductExpr).Right, bs),
+			}
+
+		case *LetExpr:
+			return &LetExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				aux(x.(*LetExpr).Left, bs),
+				aux(x.(*LetExpr).Right, bs),
+			}
+
+		case *LetUnitExpr:
+			return &LetUnitExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				aux(x.(*LetUnitExpr).Left, bs),
+				aux(x.(*LetUnitExpr).Right, bs),
+			}
+
+		case *IfExpr:
+			return &IfExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				aux(x.(*IfExpr).Cond, bs),
+				aux(x.(*IfExpr).Left, bs),
+				aux(x.(*IfExpr).Right, bs),
+			}
+
+		case *InjExpr:
+			return &InjExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				x.(*InjExpr).Inr,
+				aux(x.(*InjExpr).Right, bs),
+			}
+
+		case *AnnotExpr:
+			return &AnnotExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				aux(x.(*AnnotExpr).X, bs),
+				CopyType(x.(*AnnotExpr).Typ),
+			}
+
+		case *TypeAbsExpr:
+			return &TypeAbsExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				x.(*TypeAbsExpr).Name,
+				aux(x.(*TypeAbsExpr).Right, bs),
+			}
+
+		case *TypeAppExpr:
+			return &TypeAppExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				aux(x.(*TypeAppExpr).Left, bs),
+				CopyType(x.(*TypeAppExpr).Typ),
+			}
+
+		case *MatchExpr:
+			return &MatchExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				aux(x.(*MatchExpr).X, bs),
+				aux(x.(*MatchExpr).Left, bs),
+				aux(x.(*MatchExpr).Right, bs),
+			}
+
+		case *UnaryExpr:
+			return &UnaryExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				x.(*UnaryExpr).Op,
+				aux(x.(*UnaryExpr).Right, bs),
+			}
+
+		case *BinaryExpr:
+			return &BinaryExpr{
+				Node{CopyType(x.Type()), x.Span()},
+				x.(*BinaryExpr).Op,
+				aux(x.(*BinaryExpr).Left, bs),
+				aux(x.(*BinaryExpr).Right, bs),
+			}
+
+		// *VarExpr (free)
+		// *UnitExpr
+		// *IntExpr
+		// *FloatExpr
+		// *BoolExpr
+		default:
+			return Copy(x)
+		}
+	}
+
+	return aux(x, nil)
+}
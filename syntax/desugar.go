@@ -0,0 +1,180 @@
+/*
+ * Desugaring: rewriting constructs the parser keeps, for the
+ * sake of printing, errors, typing, in terms of more primitive
+ * ones. So far:
+ *
+ *	let x = M : T in N	→	(λx:T. N) M
+ *	(M : T)			→	M
+ *	Λa. M			→	M
+ *	M [T]			→	M
+ *	λx:T. M			→	λx. M, if a is free in T
+ *
+ * This is opt-in: passes which can't be bothered with such
+ * constructs (e.g. the abstract machines) call Desugar() first.
+ *
+ * Ascriptions, as System F's type abstractions and applications,
+ * only matter to typing: Erase() removes them, and only them (e.g.
+ * for the evaluator, whose traces show lets). As the type variables
+ * bound by Λs are then free, annotations referring to them are
+ * dropped too.
+ */
+package syntax
+
+import (
+	"reflect"
+)
+
+// Desugar x, in place
+func Desugar(x Expr) Expr {
+	switch x.(type) {
+	case *UnitExpr, *IntExpr, *FloatExpr, *BoolExpr, *VarExpr, *DeBruijnBVarExpr:
+
+	case *ProductExpr:
+		x.(*ProductExpr).Left = Desugar(x.(*ProductExpr).Left)
+		x.(*ProductExpr).Right = Desugar(x.(*ProductExpr).Right)
+
+	case *UnaryExpr:
+		x.(*UnaryExpr).Right = Desugar(x.(*UnaryExpr).Right)
+
+	case *BinaryExpr:
+		x.(*BinaryExpr).Left = Desugar(x.(*BinaryExpr).Left)
+		x.(*BinaryExpr).Right = Desugar(x.(*BinaryExpr).Right)
+
+	case *AbsExpr:
+		x.(*AbsExpr).Typ = eraseType(x.(*AbsExpr).Typ)
+		x.(*AbsExpr).Right = Desugar(x.(*AbsExpr).Right)
+
+	case *DeBruijnAbsExpr:
+		x.(*DeBruijnAbsExpr).Typ = eraseType(x.(*DeBruijnAbsExpr).Typ)
+		x.(*DeBruijnAbsExpr).Right = Desugar(x.(*DeBruijnAbsExpr).Right)
+
+	case *AppExpr:
+		x.(*AppExpr).Left = Desugar(x.(*AppExpr).Left)
+		x.(*AppExpr).Right = Desugar(x.(*AppExpr).Right)
+
+	// the application spans the whole let, and has its type
+	case *LetExpr:
+		return &AppExpr{
+			Node{x.Type(), x.Span()},
+			Desugar(x.(*LetExpr).Right),
+			Desugar(x.(*LetExpr).Left),
+		}
+
+	case *LetProductExpr:
+		x.(*LetProductExpr).Left = Desugar(x.(*LetProductExpr).Left)
+		x.(*LetProductExpr).Right = Desugar(x.(*LetProductExpr).Right)
+
+	case *LetUnitExpr:
+		x.(*LetUnitExpr).Left = Desugar(x.(*LetUnitExpr).Left)
+		x.(*LetUnitExpr).Right = Desugar(x.(*LetUnitExpr).Right)
+
+	case *IfExpr:
+		x.(*IfExpr).Cond = Desugar(x.(*IfExpr).Cond)
+		x.(*IfExpr).Left = Desugar(x.(*IfExpr).Left)
+		x.(*IfExpr).Right = Desugar(x.(*IfExpr).Right)
+
+	case *InjExpr:
+		x.(*InjExpr).Right = Desugar(x.(*InjExpr).Right)
+
+	// ascriptions, Λ and [T] only matter to typing
+	case *AnnotExpr:
+		return Desugar(x.(*AnnotExpr).X)
+	case *TypeAbsExpr:
+		return Desugar(x.(*TypeAbsExpr).Right)
+	case *TypeAppExpr:
+		return Desugar(x.(*TypeAppExpr).Left)
+
+	case *MatchExpr:
+		x.(*MatchExpr).X = Desugar(x.(*MatchExpr).X)
+		x.(*MatchExpr).Left = Desugar(x.(*MatchExpr).Left)
+		x.(*MatchExpr).Right = Desugar(x.(*MatchExpr).Right)
+
+	default:
+		panic("assert: " + reflect.ValueOf(x).Type().String())
+	}
+	return x
+}
+
+// Remove x's ascriptions, type abstractions and applications,
+// in place
+func Erase(x Expr) Expr {
+	switch x.(type) {
+	case *UnitExpr, *IntExpr, *FloatExpr, *BoolExpr, *VarExpr, *DeBruijnBVarExpr:
+	case *ProductExpr:
+		x.(*ProductExpr).Left = Erase(x.(*ProductExpr).Left)
+		x.(*ProductExpr).Right = Erase(x.(*ProductExpr).Right)
+	case *UnaryExpr:
+		x.(*UnaryExpr).Right = Erase(x.(*UnaryExpr).Right)
+	case *BinaryExpr:
+		x.(*BinaryExpr).Left = Erase(x.(*BinaryExpr).Left)
+		x.(*BinaryExpr).Right = Erase(x.(*BinaryExpr).Right)
+	case *AbsExpr:
+		x.(*AbsExpr).Typ = eraseType(x.(*AbsExpr).Typ)
+		x.(*AbsExpr).Right = Erase(x.(*AbsExpr).Right)
+	case *DeBruijnAbsExpr:
+		x.(*DeBruijnAbsExpr).Typ = eraseType(x.(*DeBruijnAbsExpr).Typ)
+		x.(*DeBruijnAbsExpr).Right = Erase(x.(*DeBruijnAbsExpr).Right)
+	case *AppExpr:
+		x.(*AppExpr).Left = Erase(x.(*AppExpr).Left)
+		x.(*AppExpr).Right = Erase(x.(*AppExpr).Right)
+	case *LetExpr:
+		x.(*LetExpr).Left = Erase(x.(*LetExpr).Left)
+		x.(*LetExpr).Right = Erase(x.(*LetExpr).Right)
+	case *LetProductExpr:
+		x.(*LetProductExpr).Left = Erase(x.(*LetProductExpr).Left)
+		x.(*LetProductExpr).Right = Erase(x.(*LetProductExpr).Right)
+	case *LetUnitExpr:
+		x.(*LetUnitExpr).Left = Erase(x.(*LetUnitExpr).Left)
+		x.(*LetUnitExpr).Right = Erase(x.(*LetUnitExpr).Right)
+	case *IfExpr:
+		x.(*IfExpr).Cond = Erase(x.(*IfExpr).Cond)
+		x.(*IfExpr).Left = Erase(x.(*IfExpr).Left)
+		x.(*IfExpr).Right = Erase(x.(*IfExpr).Right)
+	case *InjExpr:
+		x.(*InjExpr).Right = Erase(x.(*InjExpr).Right)
+	case *AnnotExpr:
+		return Erase(x.(*AnnotExpr).X)
+	case *TypeAbsExpr:
+		return Erase(x.(*TypeAbsExpr).Right)
+	case *TypeAppExpr:
+		return Erase(x.(*TypeAppExpr).Left)
+	case *MatchExpr:
+		x.(*MatchExpr).X = Erase(x.(*MatchExpr).X)
+		x.(*MatchExpr).Left = Erase(x.(*MatchExpr).Left)
+		x.(*MatchExpr).Right = Erase(x.(*MatchExpr).Right)
+	default:
+		panic("assert: " + reflect.ValueOf(x).Type().String())
+	}
+	return x
+}
+
+// t, or an unknown type if it refers to free type variables
+func eraseType(t Type) Type {
+	if hasFreeTypeVars(t, nil) {
+		return &UnknownType{}
+	}
+	return t
+}
+
+// bs are the type variables bound (∀) where t occurs
+func hasFreeTypeVars(t Type, bs []string) bool {
+	switch t.(type) {
+	case *VarType:
+		return bindIndex(t.(*VarType).Name, bs) == -1
+	case *ArrowType:
+		return hasFreeTypeVars(t.(*ArrowType).Left, bs) || hasFreeTypeVars(t.(*ArrowType).Right, bs)
+	case *ProductType:
+		return hasFreeTypeVars(t.(*ProductType).Left, bs) || hasFreeTypeVars(t.(*ProductType).Right, bs)
+	case *SumType:
+		return hasFreeTypeVars(t.(*SumType).Left, bs) || hasFreeTypeVars(t.(*SumType).Right, bs)
+	case *LinArrowType:
+		return hasFreeTypeVars(t.(*LinArrowType).Left, bs) || hasFreeTypeVars(t.(*LinArrowType).Right, bs)
+	case *TensorType:
+		return hasFreeTypeVars(t.(*TensorType).Left, bs) || hasFreeTypeVars(t.(*TensorType).Right, bs)
+	case *BangType:
+		return hasFreeTypeVars(t.(*BangType).T, bs)
+	case *ForallType:
+		return hasFreeTypeVars(t.(*ForallType).T, append(bs[:len(bs):len(bs)], t.(*ForallType).Name))
+	}
+	return false
+}
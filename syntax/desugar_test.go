@@ -0,0 +1,57 @@
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/mbivert/ftests"
+
+	"github.com/mbivert/golc/internal/testutil"
+	. "github.com/mbivert/golc/syntax"
+)
+
+func desugarStr(s string) string {
+	return Desugar(testutil.MustParse(s)).String()
+}
+
+func TestDesugar(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"let",
+			desugarStr,
+			[]any{"let x = 1 : int in x + 1"},
+			[]any{"(λx:int. x + 1) 1"},
+		},
+		{
+			"nested, in both the bound expression and the body",
+			desugarStr,
+			[]any{"let x = (let y = 1 in y) in let z = x in 〈x, z〉"},
+			[]any{"(λx. (λz. 〈x, z〉) x) ((λy. y) 1)"},
+		},
+		{
+			"Λ and [T] are erased",
+			desugarStr,
+			[]any{"let id = Λa. λx:a. x in id [int] 1"},
+			[]any{"(λid. id 1) (λx. x)"},
+		},
+		{
+			"within other constructs",
+			desugarStr,
+			[]any{"match m with inl a → let b = a in b | inr c → if c then (let d = 1 in d) else 2"},
+			[]any{"match m with inl a → (λb. b) a | inr c → if c then (λd. d) 1 else 2"},
+		},
+		{
+			"spans and types are kept",
+			func(s string) (string, string) {
+				x, err := Parse(s, "f.lc")
+				if err != nil {
+					panic(err)
+				}
+				y := Desugar(testutil.MustSType(x))
+				sp := y.Span()
+				return sp.Start.String() + "-" + sp.End.String(), y.Type().String()
+			},
+			[]any{"let x = 1 in x < 2"},
+			[]any{"1:1-1:19", "bool"},
+		},
+	})
+}
@@ -0,0 +1,130 @@
+/*
+ * Diagnostics: errors (and warnings) located in the source,
+ * as reported by the scanner and the parser, but also by the
+ * typechecker and the evaluator, as expressions remember
+ * where they come from (Span).
+ *
+ * Their textual form is the usual file:line:col: message, which
+ * most editors understand; they can also be marshalled to JSON.
+ */
+package syntax
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Position in a source file (1-based; columns counted in runes);
+// zero if unknown.
+type Pos struct {
+	Ln uint `json:"line"`
+	Cn uint `json:"col"`
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Ln, p.Cn)
+}
+
+// Source range of an expression, End excluded
+type Span struct {
+	File       string
+	Start, End Pos
+}
+
+// End is exclusive: it's the position right after the
+// offending token/node.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`
+	Start    Pos      `json:"start"`
+	End      Pos      `json:"end"`
+	Message  string   `json:"message"`
+	Notes    []string `json:"notes,omitempty"`
+}
+
+func (d *Diagnostic) Error() string {
+	var b strings.Builder
+
+	if d.Start != (Pos{}) {
+		fmt.Fprintf(&b, "%s:%s: ", d.File, d.Start)
+	} else if d.File != "" {
+		fmt.Fprintf(&b, "%s: ", d.File)
+	}
+	if d.Severity != SeverityError {
+		fmt.Fprintf(&b, "%s: ", d.Severity)
+	}
+	b.WriteString(d.Message)
+	for _, n := range d.Notes {
+		fmt.Fprintf(&b, "\n\tnote: %s", n)
+	}
+
+	return b.String()
+}
+
+// All the diagnostics of a run, in order; as an error,
+// one per line.
+type Diagnostics []*Diagnostic
+
+func (ds Diagnostics) Error() string {
+	xs := make([]string, len(ds))
+	for i, d := range ds {
+		xs[i] = d.Error()
+	}
+	return strings.Join(xs, "\n")
+}
+
+// nil if there are no errors
+func (ds Diagnostics) err() error {
+	for _, d := range ds {
+		if d.Severity == SeverityError {
+			return ds
+		}
+	}
+	return nil
+}
+
+// Error located at x, if x comes from the source (see Span);
+// a regular error otherwise.
+func ErrAt(x Expr, m string, args ...interface{}) error {
+	s := x.Span()
+	if s == nil {
+		return fmt.Errorf(m, args...)
+	}
+	return &Diagnostic{SeverityError, s.File, s.Start, s.End, fmt.Sprintf(m, args...), nil}
+}
+
+// Diagnostics from err: err's own if it holds some,
+// otherwise a single unlocated error in file fn.
+func ToDiagnostics(err error, fn string) Diagnostics {
+	var ds Diagnostics
+	if errors.As(err, &ds) {
+		return ds
+	}
+	var d *Diagnostic
+	if errors.As(err, &d) {
+		return Diagnostics{d}
+	}
+	return Diagnostics{{SeverityError, fn, Pos{}, Pos{}, err.Error(), nil}}
+}
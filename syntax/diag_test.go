@@ -0,0 +1,149 @@
+package syntax_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/mbivert/ftests"
+
+	. "github.com/mbivert/golc/syntax"
+)
+
+// Parse src, returns the diagnostics' textual form
+func parseErrs(src string) string {
+	_, err := ParseProgram(src, "f.lc")
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestDiagRecovery(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"resynchronise at )",
+			parseErrs,
+			[]any{"(1 +) (λ. x) (2 *)"},
+			[]any{"f.lc:1:5: Unexpected token: )\n" +
+				"f.lc:1:9: Expecting variable name after lambda, got: .\n" +
+				"f.lc:1:18: Unexpected token: )"},
+		},
+		{
+			"resynchronise at 〉",
+			parseErrs,
+			[]any{"〈1, λ.x〉 〈〉"},
+			[]any{"f.lc:1:6: Expecting variable name after lambda, got: .\n" +
+				"f.lc:1:11: Unexpected token: 〉"},
+		},
+		{
+			"resynchronise at in",
+			parseErrs,
+			[]any{"let x = λ. 1 in (x +)"},
+			[]any{"f.lc:1:10: Expecting variable name after lambda, got: .\n" +
+				"f.lc:1:21: Unexpected token: )"},
+		},
+		{
+			"resynchronise at ;",
+			parseErrs,
+			[]any{"let x = 1 +; let = 2; let y = (1));\ny"},
+			[]any{"f.lc:1:12: Unexpected token: ;\n" +
+				"f.lc:1:18: Expecting variable name after let, got: =\n" +
+				"f.lc:1:34: Expecting ';' after let $x = $M, got )"},
+		},
+		{
+			"unclosed parenthesis: can't resynchronise",
+			parseErrs,
+			[]any{"(λx. (x +) x"},
+			[]any{"f.lc:1:10: Unexpected token: )\n" +
+				"f.lc:1:13: Expecting left paren, got: EOF\n" +
+				"\tnote: 1:1: unclosed '('"},
+		},
+		{
+			"nested constructs are skipped",
+			parseErrs,
+			[]any{"(λ. (x) 〈y, z〉) w (1 +)"},
+			[]any{"f.lc:1:3: Expecting variable name after lambda, got: .\n" +
+				"f.lc:1:23: Unexpected token: )"},
+		},
+		{
+			"unknown character",
+			parseErrs,
+			[]any{"x $ y"},
+			[]any{"f.lc:1:3: Unexpected character: '$'"},
+		},
+		{
+			"invalid UTF-8",
+			parseErrs,
+			[]any{"x \xff y"},
+			[]any{"f.lc:1:3: Invalid UTF-8 encoding"},
+		},
+		{
+			"unterminated comment",
+			parseErrs,
+			[]any{"x /* y"},
+			[]any{"f.lc:1:3: Unterminated comment"},
+		},
+		{
+			"error limit",
+			parseErrs,
+			[]any{"(+) (+) (+) (+) (+) (+) (+) (+) (+) (+) (+) (+)"},
+			[]any{"f.lc:1:3: Unexpected token: )\n" +
+				"f.lc:1:7: Unexpected token: )\n" +
+				"f.lc:1:11: Unexpected token: )\n" +
+				"f.lc:1:15: Unexpected token: )\n" +
+				"f.lc:1:19: Unexpected token: )\n" +
+				"f.lc:1:23: Unexpected token: )\n" +
+				"f.lc:1:27: Unexpected token: )\n" +
+				"f.lc:1:31: Unexpected token: )\n" +
+				"f.lc:1:35: Unexpected token: )\n" +
+				"f.lc:1:39: Unexpected token: )"},
+		},
+	})
+}
+
+func TestDiagFormat(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"error, notes",
+			func(d *Diagnostic) string { return d.Error() },
+			[]any{&Diagnostic{SeverityError, "f.lc", Pos{1, 2}, Pos{1, 3}, "oops",
+				[]string{"a", "b"}}},
+			[]any{"f.lc:1:2: oops\n\tnote: a\n\tnote: b"},
+		},
+		{
+			"warning",
+			func(d *Diagnostic) string { return d.Error() },
+			[]any{&Diagnostic{SeverityWarning, "f.lc", Pos{1, 2}, Pos{1, 3}, "hmm", nil}},
+			[]any{"f.lc:1:2: warning: hmm"},
+		},
+		{
+			"unknown position",
+			func(d *Diagnostic) string { return d.Error() },
+			[]any{&Diagnostic{SeverityError, "f.lc", Pos{}, Pos{}, "oops", nil}},
+			[]any{"f.lc: oops"},
+		},
+		{
+			"JSON",
+			func(d *Diagnostic) (string, error) {
+				b, err := json.Marshal(d)
+				return string(b), err
+			},
+			[]any{&Diagnostic{SeverityWarning, "f.lc", Pos{1, 2}, Pos{1, 3}, "hmm", []string{"a"}}},
+			[]any{`{"severity":"warning","file":"f.lc","start":{"line":1,"col":2},` +
+				`"end":{"line":1,"col":3},"message":"hmm","notes":["a"]}`, nil},
+		},
+		{
+			"from a regular error",
+			ToDiagnostics,
+			[]any{fmt.Errorf("oops"), "f.lc"},
+			[]any{Diagnostics{{SeverityError, "f.lc", Pos{}, Pos{}, "oops", nil}}},
+		},
+		{
+			"from wrapped diagnostics",
+			ToDiagnostics,
+			[]any{fmt.Errorf("x: %w", diagErr(1, 2, 3, "oops")), "f.lc"},
+			[]any{diagErr(1, 2, 3, "oops")},
+		},
+	})
+}
@@ -0,0 +1,286 @@
+/*
+ * Structural equality: α-equivalence for expressions (bound
+ * variables' names don't matter), syntactic equality for types
+ * (up to the names of the type variables bound by ∀ and Λ).
+ *
+ * Types inferred/checked (Node.Typ) are always ignored; the
+ * types annotating abstractions can be ignored too.
+ *
+ * The Diff variants also report the path (see Path) to the
+ * first difference, in a depth-first, left to right order.
+ */
+package syntax
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Syntactic equality of types
+func TypeEqual(a, b Type) bool {
+	_, ok := TypeDiff(a, b)
+	return ok
+}
+
+// Path to the first difference between a and b; the boolean
+// is true if there's none.
+func TypeDiff(a, b Type) (Path, bool) {
+	var p Path
+	if typeDiff(a, b, nil, nil, &p) {
+		return nil, true
+	}
+	return p, false
+}
+
+// de Bruijn index of n in xs (innermost last), -1 if free
+func bindIndex(n string, xs []string) int {
+	for i := len(xs) - 1; i >= 0; i-- {
+		if xs[i] == n {
+			return len(xs) - 1 - i
+		}
+	}
+	return -1
+}
+
+// true if n, in the scope of the variables as, and m, in the
+// scope of bs, refer to the same variable
+func sameVar(n, m string, as, bs []string) bool {
+	i, j := bindIndex(n, as), bindIndex(m, bs)
+	if i == -1 && j == -1 {
+		return n == m
+	}
+	return i == j
+}
+
+// On failure, *p is the path to the difference; as and bs are
+// the type variables bound (∀, Λ) where a and b occur.
+func typeDiff(a, b Type, as, bs []string, p *Path) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+
+	down := func(field string, a, b Type) bool {
+		*p = append(*p, field)
+		if !typeDiff(a, b, as, bs, p) {
+			return false
+		}
+		*p = (*p)[:len(*p)-1]
+		return true
+	}
+
+	switch a.(type) {
+	case *ArrowType:
+		return down("left", a.(*ArrowType).Left, b.(*ArrowType).Left) &&
+			down("right", a.(*ArrowType).Right, b.(*ArrowType).Right)
+
+	case *ProductType:
+		return down("left", a.(*ProductType).Left, b.(*ProductType).Left) &&
+			down("right", a.(*ProductType).Right, b.(*ProductType).Right)
+
+	case *SumType:
+		return down("left", a.(*SumType).Left, b.(*SumType).Left) &&
+			down("right", a.(*SumType).Right, b.(*SumType).Right)
+
+	case *LinArrowType:
+		return down("left", a.(*LinArrowType).Left, b.(*LinArrowType).Left) &&
+			down("right", a.(*LinArrowType).Right, b.(*LinArrowType).Right)
+
+	case *TensorType:
+		return down("left", a.(*TensorType).Left, b.(*TensorType).Left) &&
+			down("right", a.(*TensorType).Right, b.(*TensorType).Right)
+
+	case *BangType:
+		return down("t", a.(*BangType).T, b.(*BangType).T)
+
+	case *ForallType:
+		as = append(as[:len(as):len(as)], a.(*ForallType).Name)
+		bs = append(bs[:len(bs):len(bs)], b.(*ForallType).Name)
+		return down("t", a.(*ForallType).T, b.(*ForallType).T)
+
+	case *VarType:
+		return sameVar(a.(*VarType).Name, b.(*VarType).Name, as, bs)
+
+	// *UnknownType (no annotation)
+	// *MissingType
+	// *UnitType
+	// *BoolType
+	// *IntType
+	// *FloatType
+	default:
+		return true
+	}
+}
+
+// α-equivalence, including abstractions' type annotations
+func AlphaEqual(a, b Expr) bool {
+	_, ok := AlphaDiff(a, b)
+	return ok
+}
+
+// α-equivalence, ignoring abstractions' type annotations
+func AlphaEqualUntyped(a, b Expr) bool {
+	_, ok := AlphaDiffUntyped(a, b)
+	return ok
+}
+
+// Path to the first difference between a and b, up to
+// α-equivalence; the boolean is true if there's none.
+func AlphaDiff(a, b Expr) (Path, bool) {
+	return alphaDiff(a, b, true)
+}
+
+// Same as AlphaDiff(), ignoring type annotations
+func AlphaDiffUntyped(a, b Expr) (Path, bool) {
+	return alphaDiff(a, b, false)
+}
+
+func alphaDiff(a, b Expr, types bool) (Path, bool) {
+	var p Path
+	var aux func(a, b Expr, as, bs []string) bool
+
+	// type variables bound (Λ) in a and b so far
+	var tas, tbs []string
+
+	down := func(field string, a, b Expr, as, bs []string) bool {
+		p = append(p, field)
+		if !aux(a, b, as, bs) {
+			return false
+		}
+		p = p[:len(p)-1]
+		return true
+	}
+
+	// a and b's types (annotations), at field
+	typeDown := func(field string, a, b Type) bool {
+		if !types {
+			return true
+		}
+		p = append(p, field)
+		if !typeDiff(a, b, tas, tbs, &p) {
+			return false
+		}
+		p = p[:len(p)-1]
+		return true
+	}
+
+	// as and bs are the variables bound in a and b
+	aux = func(a, b Expr, as, bs []string) bool {
+		if a == nil || b == nil {
+			return a == nil && b == nil
+		}
+		if reflect.TypeOf(a) != reflect.TypeOf(b) {
+			return false
+		}
+
+		switch a.(type) {
+		case *UnitExpr:
+			return true
+		case *IntExpr:
+			return a.(*IntExpr).Value == b.(*IntExpr).Value
+		case *FloatExpr:
+			return a.(*FloatExpr).Value == b.(*FloatExpr).Value
+		case *BoolExpr:
+			return a.(*BoolExpr).Value == b.(*BoolExpr).Value
+
+		case *VarExpr:
+			return sameVar(a.(*VarExpr).Name, b.(*VarExpr).Name, as, bs)
+
+		case *AbsExpr:
+			return typeDown("typ", a.(*AbsExpr).Typ, b.(*AbsExpr).Typ) &&
+				down("right", a.(*AbsExpr).Right, b.(*AbsExpr).Right,
+					append(as[:len(as):len(as)], a.(*AbsExpr).Name),
+					append(bs[:len(bs):len(bs)], b.(*AbsExpr).Name))
+
+		case *AppExpr:
+			return down("left", a.(*AppExpr).Left, b.(*AppExpr).Left, as, bs) &&
+				down("right", a.(*AppExpr).Right, b.(*AppExpr).Right, as, bs)
+
+		case *ProductExpr:
+			return down("left", a.(*ProductExpr).Left, b.(*ProductExpr).Left, as, bs) &&
+				down("right", a.(*ProductExpr).Right, b.(*ProductExpr).Right, as, bs)
+
+		case *LetProductExpr:
+			return a.(*LetProductExpr).N == b.(*LetProductExpr).N &&
+				down("left", a.(*LetProductExpr).Left, b.(*LetProductExpr).Left, as, bs) &&
+				down("right", a.(*LetProductExpr).Right, b.(*LetProductExpr).Right, as, bs)
+
+		case *LetExpr:
+			return down("left", a.(*LetExpr).Left, b.(*LetExpr).Left, as, bs) &&
+				down("right", a.(*LetExpr).Right, b.(*LetExpr).Right, as, bs)
+
+		case *LetUnitExpr:
+			return down("left", a.(*LetUnitExpr).Left, b.(*LetUnitExpr).Left, as, bs) &&
+				down("right", a.(*LetUnitExpr).Right, b.(*LetUnitExpr).Right, as, bs)
+
+		case *IfExpr:
+			return down("cond", a.(*IfExpr).Cond, b.(*IfExpr).Cond, as, bs) &&
+				down("left", a.(*IfExpr).Left, b.(*IfExpr).Left, as, bs) &&
+				down("right", a.(*IfExpr).Right, b.(*IfExpr).Right, as, bs)
+
+		case *InjExpr:
+			return a.(*InjExpr).Inr == b.(*InjExpr).Inr &&
+				down("right", a.(*InjExpr).Right, b.(*InjExpr).Right, as, bs)
+
+		case *AnnotExpr:
+			return typeDown("typ", a.(*AnnotExpr).Typ, b.(*AnnotExpr).Typ) &&
+				down("x", a.(*AnnotExpr).X, b.(*AnnotExpr).X, as, bs)
+
+		// bound type variables, as bound variables, are compared
+		// by their (de Bruijn) index
+		case *TypeAbsExpr:
+			tas = append(tas, a.(*TypeAbsExpr).Name)
+			tbs = append(tbs, b.(*TypeAbsExpr).Name)
+			ok := down("right", a.(*TypeAbsExpr).Right, b.(*TypeAbsExpr).Right, as, bs)
+			tas, tbs = tas[:len(tas)-1], tbs[:len(tbs)-1]
+			return ok
+
+		case *TypeAppExpr:
+			return typeDown("typ", a.(*TypeAppExpr).Typ, b.(*TypeAppExpr).Typ) &&
+				down("left", a.(*TypeAppExpr).Left, b.(*TypeAppExpr).Left, as, bs)
+
+		case *MatchExpr:
+			return down("x", a.(*MatchExpr).X, b.(*MatchExpr).X, as, bs) &&
+				down("left", a.(*MatchExpr).Left, b.(*MatchExpr).Left, as, bs) &&
+				down("right", a.(*MatchExpr).Right, b.(*MatchExpr).Right, as, bs)
+
+		case *UnaryExpr:
+			return a.(*UnaryExpr).Op == b.(*UnaryExpr).Op &&
+				down("right", a.(*UnaryExpr).Right, b.(*UnaryExpr).Right, as, bs)
+
+		case *BinaryExpr:
+			return a.(*BinaryExpr).Op == b.(*BinaryExpr).Op &&
+				down("left", a.(*BinaryExpr).Left, b.(*BinaryExpr).Left, as, bs) &&
+				down("right", a.(*BinaryExpr).Right, b.(*BinaryExpr).Right, as, bs)
+
+		case *DeBruijnBVarExpr:
+			return a.(*DeBruijnBVarExpr).N == b.(*DeBruijnBVarExpr).N
+
+		case *DeBruijnAbsExpr:
+			return typeDown("typ", a.(*DeBruijnAbsExpr).Typ, b.(*DeBruijnAbsExpr).Typ) &&
+				down("right", a.(*DeBruijnAbsExpr).Right, b.(*DeBruijnAbsExpr).Right, as, bs)
+
+		default:
+			panic("assert: " + reflect.ValueOf(a).Type().String())
+		}
+	}
+
+	if aux(a, b, nil, nil) {
+		return nil, true
+	}
+	return p, false
+}
+
+// Path from the root of the term to a sub-term: each
+// element is the name of the field followed ("left",
+// "right"). The empty path designates the root.
+type Path []string
+
+func (p Path) String() string {
+	if len(p) == 0 {
+		return "ε"
+	}
+	return strings.Join(p, ".")
+}
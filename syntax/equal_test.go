@@ -0,0 +1,142 @@
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/mbivert/ftests"
+
+	"github.com/mbivert/golc/internal/testutil"
+	. "github.com/mbivert/golc/syntax"
+)
+
+func alphaDiffStr(a, b string) (Path, bool) {
+	return AlphaDiff(testutil.MustParse(a), testutil.MustParse(b))
+}
+
+func alphaDiffUntypedStr(a, b string) (Path, bool) {
+	return AlphaDiffUntyped(testutil.MustParse(a), testutil.MustParse(b))
+}
+
+func TestEqualAlpha(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"renamed bound variable",
+			alphaDiffStr,
+			[]any{"λx. x", "λy. y"},
+			[]any{Path(nil), true},
+		},
+		{
+			"free variables differ",
+			alphaDiffStr,
+			[]any{"λx. x y", "λy. y x"},
+			[]any{Path{"right", "right"}, false},
+		},
+		{
+			"bound vs. free",
+			alphaDiffStr,
+			[]any{"λx. λy. x", "λx. λy. y"},
+			[]any{Path{"right", "right"}, false},
+		},
+		{
+			"shadowing",
+			alphaDiffStr,
+			[]any{"λx. λx. x", "λx. λy. y"},
+			[]any{Path(nil), true},
+		},
+		{
+			"literals, operators, products",
+			alphaDiffStr,
+			[]any{"λx:int. 〈x + 1, true〉", "λy:int. 〈y + 1, false〉"},
+			[]any{Path{"right", "right"}, false},
+		},
+		{
+			"operators",
+			alphaDiffStr,
+			[]any{"1 + 2", "1 - 2"},
+			[]any{Path(nil), false},
+		},
+		{
+			"annotations",
+			alphaDiffStr,
+			[]any{"λx:int → int. x", "λx:int → bool. x"},
+			[]any{Path{"typ", "right"}, false},
+		},
+		{
+			"annotations, untyped",
+			alphaDiffUntypedStr,
+			[]any{"λx:int → int. x", "λy. y"},
+			[]any{Path(nil), true},
+		},
+		{
+			"renamed type variable",
+			alphaDiffStr,
+			[]any{"Λa. λx:a. x [a]", "Λb. λx:b. x [b]"},
+			[]any{Path(nil), true},
+		},
+		{
+			"swapped type variables",
+			alphaDiffStr,
+			[]any{"Λa. Λb. λx:a. x", "Λb. Λa. λx:a. x"},
+			[]any{Path{"right", "right", "typ"}, false},
+		},
+		{
+			"free type variables differ",
+			alphaDiffStr,
+			[]any{"Λa. λx:b. x", "Λb. λx:a. x"},
+			[]any{Path{"right", "typ"}, false},
+		},
+		{
+			"∀ within a Λ",
+			alphaDiffStr,
+			[]any{"Λa. λx:∀b. a → b. x", "Λb. λx:∀a. b → a. x"},
+			[]any{Path(nil), true},
+		},
+		{
+			"inferred types are ignored",
+			AlphaEqual,
+			[]any{testutil.MustSTypeParse("λx:int. x + 1"), testutil.MustParse("λy:int. y + 1")},
+			[]any{true},
+		},
+		{
+			"de Bruijn",
+			AlphaEqual,
+			[]any{ToDeBruijn(testutil.MustParse("λx. λy. x")), ToDeBruijn(testutil.MustParse("λa. λb. a"))},
+			[]any{true},
+		},
+	})
+}
+
+func TestEqualType(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"equal",
+			TypeDiff,
+			[]any{
+				&ArrowType{&IntType{}, &ProductType{&BoolType{}, &UnitType{}}},
+				&ArrowType{&IntType{}, &ProductType{&BoolType{}, &UnitType{}}},
+			},
+			[]any{Path(nil), true},
+		},
+		{
+			"differ",
+			TypeDiff,
+			[]any{
+				&ArrowType{&IntType{}, &ProductType{&BoolType{}, &UnitType{}}},
+				&ArrowType{&IntType{}, &ProductType{&BoolType{}, &FloatType{}}},
+			},
+			[]any{Path{"right", "right"}, false},
+		},
+		{
+			"type variables",
+			TypeEqual,
+			[]any{&VarType{"a"}, &VarType{"b"}},
+			[]any{false},
+		},
+		{
+			"missing annotation",
+			TypeEqual,
+			[]any{&UnknownType{}, &IntType{}},
+			[]any{false},
+		},
+	})
+}
@@ -0,0 +1,7 @@
+package syntax
+
+// Internals, exposed to the (external) tests
+
+var ASCIITokens = asciiTokens
+
+func (k TokenKind) Spell(s Style) string { return k.spell(s) }
@@ -0,0 +1,197 @@
+package syntax
+
+import (
+	"encoding/json"
+)
+
+// ftests reports mismatches as JSON; tag each node with
+// its kind (D) so that those reports stay readable.
+func (e *IntExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		D string
+		T Type
+		V int64
+	}{
+		D: "int",
+		T: e.Type(),
+		V: e.Value,
+	})
+}
+
+func (e *FloatExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		D string
+		T Type
+		V float64
+	}{
+		D: "float",
+		T: e.Type(),
+		V: e.Value,
+	})
+}
+
+func (e *BoolExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		D string
+		T Type
+		V bool
+	}{
+		D: "bool",
+		T: e.Type(),
+		V: e.Value,
+	})
+}
+
+func (e *AbsExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		D     string
+		T     Type
+		Typ   Type
+		Name  string
+		Right Expr
+	}{
+		D:     "abs",
+		T:     e.Type(),
+		Typ:   e.Typ,
+		Name:  e.Name,
+		Right: e.Right,
+	})
+}
+
+func (e *AppExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		D     string
+		T     Type
+		Left  Expr
+		Right Expr
+	}{
+		D:     "app",
+		T:     e.Type(),
+		Left:  e.Left,
+		Right: e.Right,
+	})
+}
+
+func (e *VarExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		D    string
+		T    Type
+		Name string
+	}{
+		D:    "var",
+		T:    e.Type(),
+		Name: e.Name,
+	})
+}
+
+func (e *BinaryExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		D     string
+		T     Type
+		Op    string
+		Left  Expr
+		Right Expr
+	}{
+		D:     "bin",
+		T:     e.Type(),
+		Op:    e.Op.String(),
+		Left:  e.Left,
+		Right: e.Right,
+	})
+}
+
+func (e *UnaryExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		D     string
+		T     Type
+		Op    string
+		Right Expr
+	}{
+		D:     "una",
+		T:     e.Type(),
+		Op:    e.Op.String(),
+		Right: e.Right,
+	})
+}
+
+// unexported fields are unavailable to the json
+// package, and thus aren't visible in tests...
+func (t *IntType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		T string
+	}{
+		T: "int",
+	})
+}
+
+func (t *FloatType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		T string
+	}{
+		T: "float",
+	})
+}
+
+func (t *BoolType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		T string
+	}{
+		T: "bool",
+	})
+}
+
+func (t *UnitType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		T string
+	}{
+		T: "unit",
+	})
+}
+
+func (t *ArrowType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		T     string
+		Left  Type
+		Right Type
+	}{
+		T:     "arrow",
+		Left:  t.Left,
+		Right: t.Right,
+	})
+}
+
+func (t *ProductType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		T     string
+		Left  Type
+		Right Type
+	}{
+		T:     "product",
+		Left:  t.Left,
+		Right: t.Right,
+	})
+}
+
+func (t *VarType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		T    string
+		Name string
+	}{
+		T:    "var",
+		Name: t.Name,
+	})
+}
+
+// Spell out the token kinds.
+func (t Token) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind   string
+		LN, CN uint
+		Raw    string
+	}{
+		Kind: (t.Kind).String(),
+		LN:   t.Ln,
+		CN:   t.Cn,
+		Raw:  t.Raw,
+	})
+}
@@ -0,0 +1,1469 @@
+package syntax
+
+// TODO: parser method naming conventions are irregular
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	_ = iota
+	precBool
+	precCmp
+	precAdd
+	precMul
+)
+
+var opPrecs = map[TokenKind]int{
+	TokenPlus:  precAdd,
+	TokenMinus: precAdd,
+	TokenStar:  precMul,
+	TokenSlash: precMul,
+
+	TokenLess: precCmp,
+	TokenMore: precCmp,
+
+	TokenLessEq: precCmp,
+	TokenMoreEq: precCmp,
+
+	TokenFPlus:  precAdd,
+	TokenFMinus: precAdd,
+	TokenFStar:  precMul,
+	TokenFSlash: precMul,
+
+	TokenFMore: precCmp,
+	TokenFLess: precCmp,
+
+	TokenFLessEq: precCmp,
+	TokenFMoreEq: precCmp,
+
+	TokenAndAnd: precBool,
+	TokenOrOr:   precBool,
+}
+
+// Simple type (no polymorphism). Maybe there's a more
+// efficient way to encode all that.
+type Type interface {
+	aType()
+	// NOTE/TODO: the string representation of types
+	// is indirectly tested in typing_test.go and might
+	// need adjustments / dedicated tests.
+	String() string
+}
+
+// A yet unknown type, e.g. that of an unannotated abstraction's
+// bound variable, before typing
+type UnknownType struct{}
+
+type MissingType struct{}
+
+type ArrowType struct {
+	Left, Right Type
+}
+
+type ProductType struct {
+	Left, Right Type
+}
+
+type SumType struct {
+	Left, Right Type
+}
+
+// linear arrow (⊸)
+type LinArrowType struct {
+	Left, Right Type
+}
+
+// tensor product (⊗)
+type TensorType struct {
+	Left, Right Type
+}
+
+// exponential (!): duplicable values of type t
+type BangType struct {
+	T Type
+}
+
+type UnitType struct{}
+
+type BoolType struct{}
+
+type IntType struct{}
+
+type FloatType struct{}
+
+// type variable
+type VarType struct {
+	Name string
+}
+
+// ∀name. t (System F), binding name in t
+type ForallType struct {
+	Name string
+	T    Type
+}
+
+func (t *UnknownType) aType()  {}
+func (t *MissingType) aType()  {}
+func (t *ArrowType) aType()    {}
+func (t *ProductType) aType()  {}
+func (t *SumType) aType()      {}
+func (t *LinArrowType) aType() {}
+func (t *TensorType) aType()   {}
+func (t *BangType) aType()     {}
+func (t *UnitType) aType()     {}
+func (t *BoolType) aType()     {}
+func (t *IntType) aType()      {}
+func (t *FloatType) aType()    {}
+func (t *VarType) aType()      {}
+func (t *ForallType) aType()   {}
+
+func (t *UnknownType) String() string { return "" }
+
+func (t *MissingType) String() string {
+	return "<missing>"
+}
+
+func (t *ArrowType) String() string {
+	return printer{}.typ(t)
+}
+
+func (t *ProductType) String() string {
+	return printer{}.typ(t)
+}
+
+func (t *SumType) String() string {
+	return printer{}.typ(t)
+}
+
+func (t *LinArrowType) String() string {
+	return printer{}.typ(t)
+}
+
+func (t *TensorType) String() string {
+	return printer{}.typ(t)
+}
+
+func (t *BangType) String() string {
+	return printer{}.typ(t)
+}
+
+func (t *UnitType) String() string {
+	return "unit"
+}
+
+func (t *BoolType) String() string {
+	return "bool"
+}
+
+func (t *IntType) String() string {
+	return "int"
+}
+
+func (t *FloatType) String() string {
+	return "float"
+}
+
+func (t *VarType) String() string {
+	return t.Name
+}
+
+func (t *ForallType) String() string {
+	return printer{}.typ(t)
+}
+
+// NOTE: I'm not sure we can implement a recursive union type
+// easily with a generic: the compiler complains about recursivity,
+// and we need our sub-types depending on Expr (e.g. AbsExpr) to be
+// parametrized as well. But, I haven't digged too deep either.
+//
+// NOTE: the dummy aExpr() feels now useless because of Type()/SetType().
+//
+// NOTE: this feels clumsy anyway.
+type Expr interface {
+	aExpr()
+	Type() Type
+	SetType(Type)
+	Span() *Span
+	SetSpan(*Span)
+
+	String() string
+}
+
+// span is nil for expressions which don't come from the source
+// (e.g. built by the evaluator); it's shared by copies.
+type Node struct {
+	Typ Type
+	Loc *Span
+}
+
+func (e *Node) aExpr()           {}
+func (e *Node) Type() Type       { return e.Typ }
+func (e *Node) SetType(typ Type) { e.Typ = typ }
+func (e *Node) Span() *Span      { return e.Loc }
+func (e *Node) SetSpan(s *Span)  { e.Loc = s }
+func (e *Node) String() string   { return "" }
+
+type IntExpr struct {
+	Node
+	Value int64
+}
+
+type UnitExpr struct {
+	Node
+}
+
+type FloatExpr struct {
+	Node
+	Value float64
+}
+
+type BoolExpr struct {
+	Node
+	Value bool
+}
+
+type VarExpr struct {
+	Node
+	Name string
+	// fresh bool
+}
+
+type AbsExpr struct {
+	Node
+	// The only type information we parse optional,
+	// and pertaining to an abstraction's bounded variable.
+	//
+	// That type however is merely the left part of
+	// an ArrowType{} which'll make the type of the AbsExpr,
+	// so we can't fit it in Node.Typ
+	Typ   Type
+	Name  string
+	Right Expr
+}
+
+type AppExpr struct {
+	Node
+	Left, Right Expr
+}
+
+// TODO: have a specifc Operator type instead of TokenKind?
+type UnaryExpr struct {
+	Node
+	Op    TokenKind
+	Right Expr
+}
+
+type BinaryExpr struct {
+	Node
+	Op          TokenKind
+	Left, Right Expr
+}
+
+// NOTE/TODO: probably better with a []Expr, len ≥ 2
+type ProductExpr struct {
+	Node
+	Left, Right Expr
+}
+
+// let 〈x1, ..., xn〉 = left in M, n ≥ 2; as for products,
+// 〈x, y, z〉 matches 〈a, 〈b, c〉〉. right is λx1. ... λxn. M,
+// so that the names are bound as by regular abstractions
+// (substitution, renaming, de Bruijn indices, etc.).
+type LetProductExpr struct {
+	Node
+	N           int
+	Left, Right Expr
+}
+
+// let x = left [: T] in M; right is λx:T. M, as for LetProductExpr.
+// It's kept as such, rather than (λx:T. M) left, so that printing,
+// errors, traces show the let, and so that it can be generalized
+// (let-polymorphism); see Desugar() for passes which'd rather not
+// bother.
+type LetExpr struct {
+	Node
+	Left, Right Expr
+}
+
+// let * = left in right
+type LetUnitExpr struct {
+	Node
+	Left, Right Expr
+}
+
+// if cond then left else right
+type IfExpr struct {
+	Node
+	Cond, Left, Right Expr
+}
+
+// inl right, or inr right
+type InjExpr struct {
+	Node
+	Inr   bool
+	Right Expr
+}
+
+// match x with inl y → N | inr z → P; as for let 〈...〉, the
+// branches are abstractions: left is λy. N, right is λz. P.
+type MatchExpr struct {
+	Node
+	X, Left, Right Expr
+}
+
+// (x : typ), a type ascription: x is checked against typ,
+// which is then x's type (see styping.go). The parentheses
+// are optional at the top-level, e.g. f 1 : int.
+type AnnotExpr struct {
+	Node
+	X   Expr
+	Typ Type
+}
+
+// Λname. right, a type abstraction (System F): name is a
+// type variable, bound in right's annotations.
+type TypeAbsExpr struct {
+	Node
+	Name  string
+	Right Expr
+}
+
+// left [typ], a type application (System F): left's ∀ is
+// instantiated with typ.
+type TypeAppExpr struct {
+	Node
+	Left Expr
+	Typ  Type
+}
+
+func (e *IntExpr) String() string {
+	return fmt.Sprintf("%d", e.Value)
+}
+
+func (e *UnitExpr) String() string {
+	return "*"
+}
+
+func (e *FloatExpr) String() string {
+	return formatFloat(e.Value)
+}
+
+func (e *BoolExpr) String() string {
+	return fmt.Sprintf("%t", e.Value)
+}
+
+func (e *VarExpr) String() string {
+	return fmt.Sprintf("%s", e.Name)
+}
+
+func (e *AbsExpr) String() string {
+	return printer{}.expr(e)
+}
+
+func (e *AppExpr) String() string {
+	return printer{}.expr(e)
+}
+
+func (e *UnaryExpr) String() string {
+	return printer{}.expr(e)
+}
+
+func (e *BinaryExpr) String() string {
+	return printer{}.expr(e)
+}
+
+func (e *ProductExpr) String() string {
+	return printer{}.expr(e)
+}
+
+func (e *LetExpr) String() string {
+	return printer{}.expr(e)
+}
+
+func (e *LetProductExpr) String() string {
+	return printer{}.expr(e)
+}
+
+func (e *LetUnitExpr) String() string {
+	return printer{}.expr(e)
+}
+
+func (e *IfExpr) String() string {
+	return printer{}.expr(e)
+}
+
+func (e *InjExpr) String() string {
+	return printer{}.expr(e)
+}
+
+func (e *MatchExpr) String() string {
+	return printer{}.expr(e)
+}
+
+func (e *AnnotExpr) String() string {
+	return printer{}.expr(e)
+}
+
+func (e *TypeAbsExpr) String() string {
+	return printer{}.expr(e)
+}
+
+func (e *TypeAppExpr) String() string {
+	return printer{}.expr(e)
+}
+
+// Name bound by x (empty if nameless), its type, and x's body
+func letBinder(x *LetExpr) (string, Type, Expr) {
+	switch x.Right.(type) {
+	case *AbsExpr:
+		y := x.Right.(*AbsExpr)
+		return y.Name, y.Typ, y.Right
+	case *DeBruijnAbsExpr:
+		y := x.Right.(*DeBruijnAbsExpr)
+		return "", y.Typ, y.Right
+	}
+	panic("assert")
+}
+
+// Names bound by x, from its abstractions; those of a nameless
+// (de Bruijn) let are empty. Also returns the let's body.
+func letNames(x *LetProductExpr) ([]string, Expr) {
+	var ns []string
+
+	y := x.Right
+	for i := 0; i < x.N; i++ {
+		switch y.(type) {
+		case *AbsExpr:
+			ns = append(ns, y.(*AbsExpr).Name)
+			y = y.(*AbsExpr).Right
+		case *DeBruijnAbsExpr:
+			ns = append(ns, "")
+			y = y.(*DeBruijnAbsExpr).Right
+		default:
+			panic("assert")
+		}
+	}
+	return ns, y
+}
+
+// Name bound by a match's branch (empty if nameless), and its body
+func branchName(x Expr) (string, Expr) {
+	switch x.(type) {
+	case *AbsExpr:
+		return x.(*AbsExpr).Name, x.(*AbsExpr).Right
+	case *DeBruijnAbsExpr:
+		return "", x.(*DeBruijnAbsExpr).Right
+	}
+	panic("assert")
+}
+
+type parser struct {
+	scanner
+	tok   Token
+	end   Pos  // end of the previous token
+	decls bool // parsing top-level declarations (see skipTo())
+}
+
+// Thrown (panic) after an error has been reported, to give up
+// parsing the current construct; see parser.sync().
+type bailout struct{}
+
+// Give up after that many errors
+const maxErrors = 10
+
+func (p *parser) init(src string, fn string) {
+	p.scanner.init([]byte(src), fn)
+}
+
+// Report an error on the current token
+func (p *parser) errHere(notes []string, m string, args ...interface{}) {
+	ecn := p.tok.Cn + uint(utf8.RuneCountInString(p.tok.Raw))
+	p.error(p.tok.Ln, p.tok.Cn, ecn, fmt.Sprintf(m, args...), notes...)
+}
+
+// Report an error on the current token, and bail out
+func (p *parser) errf(m string, args ...interface{}) {
+	p.errHere(nil, m, args...)
+	panic(bailout{})
+}
+
+// Run f, recovering from the errors it bails out on: tokens are
+// then skipped up to one of the closing tokens to, which is left
+// for the caller. Returns false on error.
+//
+// If none can be found (EOF, or a closing token belonging to an
+// enclosing construct), we keep bailing out.
+func (p *parser) sync(f func(), to ...TokenKind) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, isb := r.(bailout); !isb || len(p.diags) >= maxErrors || !p.skipTo(to) {
+				panic(r)
+			}
+			ok = false
+		}
+	}()
+
+	f()
+	return true
+}
+
+// Tokens opening/closing nested constructs, skipped as a whole
+// by parser.skipTo()
+var openTokens = map[TokenKind]bool{
+	TokenLParen:   true,
+	TokenLBracket: true,
+	TokenLSquare:  true,
+	TokenLet:      true,
+	TokenIf:       true,
+	TokenMatch:    true,
+}
+
+var closeTokens = map[TokenKind]bool{
+	TokenRParen:   true,
+	TokenRBracket: true,
+	TokenRSquare:  true,
+	TokenIn:       true,
+	TokenElse:     true,
+}
+
+// Skip tokens up to one of to (unnested); false if none can
+// be found.
+//
+// Within top-level declarations, a ';' always ends the current
+// one, even in an unclosed construct, and an unnested let starts
+// the next one: both are left for the declarations' loop.
+func (p *parser) skipTo(to []TokenKind) bool {
+	// top-level declarations
+	top := false
+	for _, k := range to {
+		top = top || k == TokenSemicolon
+	}
+
+	for n := 0; !p.has(TokenEOF); p.next() {
+		for _, k := range to {
+			if n == 0 && p.has(k) {
+				return true
+			}
+		}
+		switch {
+		case p.decls && p.has(TokenSemicolon):
+			return top
+		case top && n == 0 && p.has(TokenLet):
+			return true
+		case openTokens[p.tok.Kind]:
+			n++
+		case closeTokens[p.tok.Kind] && n > 0:
+			n--
+		// stray closing token, skipped at the top-level only
+		case closeTokens[p.tok.Kind] && !top:
+			return false
+		}
+	}
+	return false
+}
+
+func (p *parser) next() Token {
+	p.end = Pos{p.tok.Ln, p.tok.Cn + uint(utf8.RuneCountInString(p.tok.Raw))}
+	p.tok = p.scanner.scan()
+	return p.tok
+}
+
+// Position of the current token
+func (p *parser) pos() Pos {
+	return Pos{p.tok.Ln, p.tok.Cn}
+}
+
+// From start up to the end of the previous token
+func (p *parser) span(start Pos) *Span {
+	return &Span{p.fn, start, p.end}
+}
+
+// shortcut; trying to avoid the parsing code to dig through
+// p.tok directly.
+func (p *parser) has(t TokenKind) bool {
+	return p.tok.Kind == t
+}
+
+func (p *parser) PrimitiveType() Type {
+	switch k := p.tok.Kind; k {
+	case TokenTBool:
+		p.next()
+		return &BoolType{}
+	case TokenTInt:
+		p.next()
+		return &IntType{}
+	case TokenTFloat:
+		p.next()
+		return &FloatType{}
+	case TokenTUnit:
+		p.next()
+		return &UnitType{}
+	case TokenName:
+		n := p.tok.Raw
+		p.next()
+		return &VarType{n}
+	// extends as far right as possible, as abstractions
+	case TokenForall:
+		p.next()
+		if !p.has(TokenName) {
+			p.errf("Expecting type variable name after ∀, got: %s", p.tok.Kind)
+		}
+		n := p.tok.Raw
+		p.next()
+		if !p.has(TokenDot) {
+			p.errf("Expecting dot after ∀%s, got: %s", n, p.tok.Kind)
+		}
+		p.next()
+		return &ForallType{n, p.Type()}
+	// binds stronger than everything else: !A ⊸ B is (!A) ⊸ B
+	case TokenExcl:
+		p.next()
+		return &BangType{p.PrimitiveType()}
+	case TokenLParen:
+		p.next()
+		t := p.Type()
+		if !p.has(TokenRParen) {
+			p.errf("Expecting left paren, got: %s", k.String())
+		}
+		p.next()
+		return t
+	default:
+		p.errf("Unexpected token: %s", k.String())
+	}
+	return nil
+}
+
+// NOTE: in qlambdabook.pdf, <M1, M2, ... > := <M1, <M2, ...>>,
+// hence it's only natural for × to be right associative as well
+// (I didn't saw such a shortcut being articulated in the λ-calculus
+// notes)
+//
+// The tensor product (⊗) lives at the same level as ×.
+func (p *parser) ProductType() Type {
+	l := p.PrimitiveType()
+
+	for p.has(TokenProduct) || p.has(TokenOMult) {
+		k := p.tok.Kind
+		p.next()
+		r := p.ProductType()
+		if k == TokenOMult {
+			l = &TensorType{l, r}
+		} else {
+			l = &ProductType{l, r}
+		}
+	}
+
+	return l
+}
+
+// product (×) binds stronger than sums (⊕), and ⊕ is right
+// associative as well
+func (p *parser) SumType() Type {
+	l := p.ProductType()
+
+	for p.has(TokenOPlus) {
+		p.next()
+		r := p.SumType()
+		l = &SumType{l, r}
+	}
+
+	return l
+}
+
+// sum (⊕) binds stronger than arrows; arrow is right
+// associative. Linear arrows (⊸) live at the same level as →.
+func (p *parser) ArrowType() Type {
+	l := p.SumType()
+
+	for p.has(TokenArrow) || p.has(TokenRMultiMap) {
+		k := p.tok.Kind
+		p.next()
+		r := p.ArrowType()
+		if k == TokenRMultiMap {
+			l = &LinArrowType{l, r}
+		} else {
+			l = &ArrowType{l, r}
+		}
+	}
+
+	return l
+}
+
+func (p *parser) Type() Type {
+	return p.ArrowType()
+}
+
+// TODO: Rename IntExpr to IntLit & cie?
+//
+// Malformed literals have already been reported by the scanner;
+// out of range ones are reported here, and evaluate to 0.
+func (p *parser) number() Expr {
+	raw, k, s := p.tok.Raw, p.tok.Kind, p.pos()
+	lit := strings.ReplaceAll(raw, "_", "")
+
+	if k == TokenFloat {
+		// correctly rounded; ParseFloat silently underflows
+		// to 0, which we report as we do overflows
+		v, err := strconv.ParseFloat(lit, 64)
+		if err == nil && v == 0 && strings.ContainsAny(mantissa(lit), "123456789") {
+			err = strconv.ErrRange
+		}
+		if errors.Is(err, strconv.ErrRange) {
+			p.errHere(nil, "Float literal out of range: %s", raw)
+		}
+		if err != nil {
+			v = 0
+		}
+		p.next()
+		return &FloatExpr{Node{&FloatType{}, p.span(s)}, v}
+	}
+
+	base := 10
+	if len(lit) > 1 && lit[0] == '0' {
+		switch lower(rune(lit[1])) {
+		case 'x':
+			base = 16
+		case 'o':
+			base = 8
+		case 'b':
+			base = 2
+		}
+		if base != 10 {
+			lit = lit[2:]
+		}
+	}
+
+	v, err := strconv.ParseInt(lit, base, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		p.errHere(nil, "Integer literal out of range: %s", raw)
+	}
+	if err != nil {
+		v = 0
+	}
+	p.next()
+	return &IntExpr{Node{&IntType{}, p.span(s)}, v}
+}
+
+// mantissa returns the part of a decimal float literal
+// before its exponent.
+func mantissa(lit string) string {
+	if n := strings.IndexAny(lit, "eE"); n >= 0 {
+		return lit[:n]
+	}
+	return lit
+}
+
+func (p *parser) bool() *BoolExpr {
+	v := true
+	if p.tok.Raw == "false" {
+		v = false
+	}
+	s := p.pos()
+	p.next()
+	return &BoolExpr{Node{&BoolType{}, p.span(s)}, v}
+}
+
+func (p *parser) star() *UnitExpr {
+	s := p.pos()
+	p.next()
+	return &UnitExpr{Node{&UnitType{}, p.span(s)}}
+}
+
+func (p *parser) parenExpr() Expr {
+	ln, cn := p.tok.Ln, p.tok.Cn
+	p.next()
+
+	var x Expr
+	var t Type
+	p.sync(func() {
+		x = p.appExpr()
+
+		// ($M : $T)
+		if p.has(TokenColon) {
+			p.next()
+			t = p.Type()
+		}
+	}, TokenRParen)
+
+	if !p.has(TokenRParen) {
+		p.errHere([]string{fmt.Sprintf("%d:%d: unclosed '('", ln, cn)},
+			"Expecting left paren, got: %s", p.tok.Kind.String())
+		panic(bailout{})
+	}
+	p.next()
+
+	if t != nil {
+		return &AnnotExpr{Node{nil, p.span(Pos{ln, cn})}, x, t}
+	}
+	return x
+}
+
+// $M [: $T]: at the top-level, ascriptions need no parentheses
+func (p *parser) topExpr() Expr {
+	s := p.pos()
+	x := p.appExpr()
+	if !p.has(TokenColon) {
+		return x
+	}
+	p.next()
+	t := p.Type()
+	return &AnnotExpr{Node{nil, p.span(s)}, x, t}
+}
+
+func (p *parser) unaryOpExpr() *UnaryExpr {
+	o, s := p.tok.Kind, p.pos()
+	p.next()
+	x := p.binaryExprs()
+	return &UnaryExpr{Node{nil, p.span(s)}, o, x}
+}
+
+// As for unary operators: inl $M + 1 is inl ($M + 1)
+func (p *parser) injExpr() *InjExpr {
+	r, s := p.has(TokenInr), p.pos()
+	p.next()
+	x := p.binaryExprs()
+	return &InjExpr{Node{nil, p.span(s)}, r, x}
+}
+
+func (p *parser) varExpr() *VarExpr {
+	n, s := p.tok.Raw, p.pos()
+	p.next()
+	return &VarExpr{Node{nil, p.span(s)}, n}
+}
+
+// NOTE: we're using 〈〉 over <> to avoid confusion with < as an operator
+// (e.g. <x, 1> will mess things up: parseBinary will expects something after
+// the 1, and not consider it the end of a product)
+func (p *parser) productExpr() Expr {
+	s := p.pos()
+	p.next()
+
+	var x Expr
+	if !p.sync(func() { x = p.productElems(s) }, TokenRBracket) {
+		p.next()
+	}
+	return x
+}
+
+// Elements of a product, up to the closing bracket; the
+// opening one is at start.
+func (p *parser) productElems(start Pos) Expr {
+	var xs []Expr
+
+	for {
+		xs = append(xs, p.appExpr())
+		if p.has(TokenRBracket) {
+			p.next()
+			break
+		}
+		if p.has(TokenComa) {
+			p.next()
+		}
+	}
+
+	// <Y> parsed as Y
+	x := xs[len(xs)-1]
+
+	// <X, Y, Z> parsed as <X, <Y, Z>>
+	for i := len(xs) - 2; i >= 0; i-- {
+		s := &Span{p.fn, xs[i].Span().Start, x.Span().End}
+		if i == 0 {
+			s = p.span(start)
+		}
+		x = &ProductExpr{Node{nil, s}, xs[i], x}
+	}
+
+	return x
+}
+
+func (p *parser) unaryExpr() Expr {
+	switch k := p.tok.Kind; k {
+	case TokenInt, TokenFloat:
+		return p.number()
+	case TokenStar:
+		return p.star()
+	case TokenBool:
+		return p.bool()
+	case TokenLParen:
+		return p.parenExpr()
+	case TokenMinus, TokenPlus, TokenFMinus, TokenFPlus, TokenExcl:
+		return p.unaryOpExpr()
+	case TokenName:
+		return p.varExpr()
+	case TokenLBracket:
+		return p.productExpr()
+	case TokenInl, TokenInr:
+		return p.injExpr()
+	default:
+		p.unexpected()
+	}
+	return nil
+}
+
+func (p *parser) hasOp() int {
+	x, ok := opPrecs[p.tok.Kind]
+	if !ok {
+		x = -1
+	}
+	return x
+}
+
+func (p *parser) binaryExpr(prec int) Expr {
+	s := p.pos()
+	left := p.unaryExpr()
+
+	// we start the recursive parsing with prec == 0, so we'll
+	// have to get there again and slurp the whole expression
+	// (all genuine operators have an precedence > 0)
+	for x := p.hasOp(); x > prec; x = p.hasOp() {
+		op := p.tok.Kind
+		p.next()
+		right := p.binaryExpr(x)
+		left = &BinaryExpr{Node{nil, p.span(s)}, op, left, right}
+	}
+
+	return left
+}
+
+func (p *parser) binaryExprs() Expr {
+	return p.binaryExpr(0)
+}
+
+// Names bound by a let: $x, 〈$x1, ..., $xn〉, or * (none).
+// As for products, 〈$x〉 is $x.
+func (p *parser) letNames() []string {
+	switch {
+	case p.has(TokenName):
+		return []string{p.varExpr().Name}
+
+	case p.has(TokenStar):
+		p.next()
+		return []string{}
+
+	case p.has(TokenLBracket):
+		var ns []string
+		p.next()
+		for {
+			if !p.has(TokenName) {
+				p.errf("Expecting variable name in let 〈...〉, got: %s", p.tok.Kind)
+			}
+			for _, n := range ns {
+				if n == p.tok.Raw {
+					p.errf("'%s' bound twice in let 〈...〉", n)
+				}
+			}
+			ns = append(ns, p.tok.Raw)
+			p.next()
+
+			if p.has(TokenRBracket) {
+				p.next()
+				return ns
+			}
+			if !p.has(TokenComa) {
+				p.errf("Expecting ',' or '〉' in let 〈...〉, got: %s", p.tok.Kind)
+			}
+			p.next()
+		}
+	}
+
+	p.errf("Expecting variable name after let, got: %s", p.tok.Kind)
+	return nil
+}
+
+// let $x = $M [: $T], let 〈$x1, ..., $xn〉 = $M or let * = $M;
+// shared by let/in and the REPL's top-level definitions, which
+// only accept the first form.
+func (p *parser) letDecl() ([]string, Expr, Type) {
+	p.next()
+
+	ns := p.letNames()
+
+	if !p.has(TokenEqual) {
+		p.errf("Expecting equal after let $x, got: %s", p.tok.Kind)
+	}
+
+	p.next()
+
+	x := p.appExpr()
+
+	t := Type(&UnknownType{})
+
+	if p.has(TokenColon) {
+		if len(ns) != 1 {
+			p.errf("Type annotations are only allowed in let $x = $M")
+		}
+		p.next()
+		t = p.Type()
+	}
+
+	return ns, x, t
+}
+
+// XXX naming convention is confusing
+//
+// TODO: no rec
+func (p *parser) letIn() Expr {
+	var ns []string
+	var x Expr
+	var t Type
+
+	s := p.pos()
+	p.sync(func() { ns, x, t = p.letDecl() }, TokenIn)
+
+	return p.letBody(s, ns, x, t)
+}
+
+// "in $N", following a let (see letDecl()) starting at start
+func (p *parser) letBody(start Pos, ns []string, x Expr, t Type) Expr {
+	if !p.has(TokenIn) {
+		p.errf("Expecting 'in' after let $x = $M, got %s", p.tok.Kind)
+	}
+
+	p.next()
+
+	y := p.appExpr()
+
+	// Nodes built here span the whole let/in.
+	s := p.span(start)
+
+	switch len(ns) {
+	case 0:
+		return &LetUnitExpr{Node{nil, s}, x, y}
+	case 1:
+	default:
+		for i := len(ns) - 1; i >= 0; i-- {
+			y = &AbsExpr{Node{nil, s}, &UnknownType{}, ns[i], y}
+		}
+		return &LetProductExpr{Node{nil, s}, len(ns), x, y}
+	}
+
+	return &LetExpr{Node{nil, s}, x, &AbsExpr{Node{nil, s}, t, ns[0], y}}
+}
+
+// if $M then $N else $P
+func (p *parser) ifExpr() Expr {
+	var c, l Expr
+
+	s := p.pos()
+	p.next()
+
+	p.sync(func() { c = p.appExpr() }, TokenThen)
+	if !p.has(TokenThen) {
+		p.errf("Expecting 'then' after if $M, got %s", p.tok.Kind)
+	}
+	p.next()
+
+	p.sync(func() { l = p.appExpr() }, TokenElse)
+	if !p.has(TokenElse) {
+		p.errf("Expecting 'else' after if $M then $N, got %s", p.tok.Kind)
+	}
+	p.next()
+
+	r := p.appExpr()
+	return &IfExpr{Node{nil, p.span(s)}, c, l, r}
+}
+
+// match $M with inl $x → $N | inr $y → $P; the branches may
+// come in any order, the first one being optionally preceded
+// by a |.
+func (p *parser) matchExpr() Expr {
+	var x Expr
+	var bs [2]Expr
+
+	s := p.pos()
+	p.next()
+
+	p.sync(func() { x = p.appExpr() }, TokenWith)
+	if !p.has(TokenWith) {
+		p.errf("Expecting 'with' after match $M, got %s", p.tok.Kind)
+	}
+	p.next()
+
+	if p.has(TokenOr) {
+		p.next()
+	}
+
+	for i := 0; i < len(bs); i++ {
+		if i > 0 {
+			if !p.has(TokenOr) {
+				p.errf("Expecting '|' after match's first branch, got %s", p.tok.Kind)
+			}
+			p.next()
+		}
+
+		k, b := p.tok.Kind, p.pos()
+		if k != TokenInl && k != TokenInr {
+			p.errf("Expecting inl or inr in match's branch, got %s", k)
+		}
+		j := 0
+		if k == TokenInr {
+			j = 1
+		}
+		if bs[j] != nil {
+			p.errf("%s matched twice", k)
+		}
+		p.next()
+
+		if !p.has(TokenName) {
+			p.errf("Expecting variable name after %s, got: %s", k, p.tok.Kind)
+		}
+		n := p.tok.Raw
+		p.next()
+
+		if !p.has(TokenArrow) {
+			p.errf("Expecting '→' after %s %s, got %s", k, n, p.tok.Kind)
+		}
+		p.next()
+
+		var y Expr
+		if i == 0 {
+			p.sync(func() { y = p.appExpr() }, TokenOr)
+		} else {
+			y = p.appExpr()
+		}
+		bs[j] = &AbsExpr{Node{nil, p.span(b)}, &UnknownType{}, n, y}
+	}
+
+	return &MatchExpr{Node{nil, p.span(s)}, x, bs[0], bs[1]}
+}
+
+func (p *parser) absExpr(head bool) Expr {
+	var n string
+
+	s := p.pos()
+
+	// TODO: hopefully this is good enough to insert it here
+	if p.has(TokenLet) {
+		return p.letIn()
+	}
+	if p.has(TokenIf) {
+		return p.ifExpr()
+	}
+	if p.has(TokenMatch) {
+		return p.matchExpr()
+	}
+	if p.has(TokenBigLambda) {
+		return p.typeAbsExpr()
+	}
+
+	if !p.has(TokenLambda) {
+		named := p.has(TokenName)
+		x := p.binaryExprs()
+
+		// is this the short form: "x. [...]" instead of "λx. [...]"
+		// (eventually with a type annotation)
+		y, ok := x.(*VarExpr)
+
+		// not a VarExpr: definitely not a short form; nor is
+		// a parenthesized one, e.g. let x = (y) : int in ...
+		// not followed by either a dot or a colon: not a short form either;
+		// nor is an argument followed by a colon, which is rather the
+		// application's annotation (e.g. (f x : int)), nor a colon
+		// and a type not followed by a dot (e.g. (x : int)).
+		if !ok || !named || (!p.has(TokenDot) && !(head && p.hasTypeDot())) {
+			return x
+		}
+
+		n = y.Name
+	} else {
+		p.next()
+		if !p.has(TokenName) {
+			p.errf("Expecting variable name after lambda, got: %s", p.tok.Kind.String())
+		}
+		n = p.tok.Raw
+		p.next()
+	}
+
+	// a type information may be supplied
+	//	t := Type(&MissingType{})
+	t := Type(&UnknownType{})
+	if p.has(TokenColon) {
+		p.next()
+		t = p.Type()
+	}
+
+	if !p.has(TokenDot) {
+		p.errf("Expecting dot after lambda variable name, got: %s", p.tok.Kind.String())
+	}
+	p.next()
+
+	x := p.appExpr()
+	return &AbsExpr{Node{nil, p.span(s)}, t, n, x}
+}
+
+// true if a colon, a type and a dot come next: "x : T. M" is
+// a short form abstraction, while "(x : T)" is an ascription.
+// The parser's state is restored, types being parsed again.
+func (p *parser) hasTypeDot() (ok bool) {
+	if !p.has(TokenColon) {
+		return false
+	}
+
+	q := *p
+	defer func() {
+		if r := recover(); r != nil {
+			if _, isb := r.(bailout); !isb {
+				panic(r)
+			}
+		}
+		*p = q
+	}()
+
+	p.next()
+	p.Type()
+	return p.has(TokenDot)
+}
+
+// Λ$a. $M
+func (p *parser) typeAbsExpr() Expr {
+	s := p.pos()
+	p.next()
+
+	if !p.has(TokenName) {
+		p.errf("Expecting type variable name after Λ, got: %s", p.tok.Kind)
+	}
+	n := p.tok.Raw
+	p.next()
+
+	if !p.has(TokenDot) {
+		p.errf("Expecting dot after Λ%s, got: %s", n, p.tok.Kind)
+	}
+	p.next()
+
+	x := p.appExpr()
+	return &TypeAbsExpr{Node{nil, p.span(s)}, n, x}
+}
+
+// [$T], following the expression l starting at start: type
+// applications are parsed as regular ones (f [int] 1 is
+// (f [int]) 1).
+func (p *parser) typeAppExpr(start Pos, l Expr) Expr {
+	o := p.pos()
+	p.next()
+
+	t := p.Type()
+	if !p.has(TokenRSquare) {
+		p.errHere([]string{fmt.Sprintf("%d:%d: unclosed '['", o.Ln, o.Cn)},
+			"Expecting ']', got: %s", p.tok.Kind)
+		panic(bailout{})
+	}
+	p.next()
+
+	return &TypeAppExpr{Node{nil, p.span(start)}, l, t}
+}
+
+// tokens marking the end of an application. parser.appExpr()
+// is the parsing entry point: we get back there again in a few
+// cases (parser.parenExpr(), parser.productExpr(), parser.letIn())
+// and need to detect the end of such cases.
+var endAppExpr = map[TokenKind]bool{
+	// nothing else to parse
+	TokenEOF: true,
+
+	// we were parsing something between parenthesis
+	TokenRParen: true,
+
+	// we were parsing something between brackets (product)
+	TokenRBracket: true,
+
+	// we're parsing something between brackets (product)
+	TokenComa: true,
+
+	// we just parsed the expression $expr associated to a bound
+	// name $x of a let/in construct (let $x = $expr in ...)
+	TokenIn: true,
+
+	TokenColon: true,
+
+	// end of a top-level declaration (let $x = $expr;)
+	TokenSemicolon: true,
+
+	// a let/in can't be an argument without parenthesis (as
+	// in OCaml): this helps reporting a missing semicolon
+	// between two top-level declarations.
+	TokenLet: true,
+
+	// if $M then $N else $P; same as let/in for if
+	TokenThen: true,
+	TokenElse: true,
+	TokenIf:   true,
+
+	// match $M with inl $x → $N | inr $y → $P; same as let/in
+	TokenMatch: true,
+	TokenWith:  true,
+	TokenOr:    true,
+}
+
+func (p *parser) appExpr() Expr {
+	s := p.pos()
+	l := p.absExpr(true)
+
+	for {
+		if _, stop := endAppExpr[p.tok.Kind]; stop {
+			break
+		}
+		if p.has(TokenLSquare) {
+			l = p.typeAppExpr(s, l)
+			continue
+		}
+		r := p.absExpr(false)
+		l = &AppExpr{Node{nil, p.span(s)}, l, r}
+	}
+
+	return l
+}
+
+// Parsing entry point: run f, and turn the errors reported
+// along the way (diagnostics) into an error.
+func (p *parser) run(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(bailout); !ok {
+				panic(r)
+			}
+		}
+		err = p.diags.err()
+	}()
+
+	p.next()
+	f()
+	return nil
+}
+
+// Unexpected trailing input
+func (p *parser) eof() {
+	if !p.has(TokenEOF) {
+		p.unexpected()
+	}
+}
+
+// Report the current token as unexpected, and bail out
+func (p *parser) unexpected() {
+	var notes []string
+
+	// & used to be scanned on its own, but was never parsed
+	if p.has(TokenProduct) && p.tok.Raw == "&" {
+		notes = append(notes, "'&' is the ASCII spelling of '×'; boolean and is '&&'")
+	}
+	p.errHere(notes, "Unexpected token: %s", p.tok.Kind)
+	panic(bailout{})
+}
+
+// The returned expression is nil on error, unless it has
+// been fully parsed but followed by unexpected tokens.
+func parse(src string, fn string) (x Expr, err error) {
+	var p parser
+	p.init(src, fn)
+
+	err = p.run(func() {
+		y := p.topExpr()
+		if len(p.diags) == 0 {
+			x = y
+		}
+		p.eof()
+	})
+	return x, err
+}
+
+// Parse a top-level definition, "let $x = $M [: $T][;]" with no "in"
+// (REPL). The returned name is empty if src isn't such a definition,
+// in which case it should be parsed as a regular expression.
+func parseDef(src string, fn string) (n string, x Expr, t Type, err error) {
+	var p parser
+	p.init(src, fn)
+
+	def := false
+
+	err = p.run(func() {
+		if !p.has(TokenLet) {
+			return
+		}
+
+		var ns []string
+		ns, x, t = p.letDecl()
+
+		// let/in
+		if p.has(TokenIn) {
+			return
+		}
+		def = true
+
+		if len(ns) != 1 {
+			p.errf("Expecting 'in' after a let 〈...〉 or let *, got %s", p.tok.Kind)
+		}
+		n = ns[0]
+
+		// optional, as in program files
+		if p.has(TokenSemicolon) {
+			p.next()
+		}
+
+		p.eof()
+	})
+
+	if !def && err == nil {
+		return "", nil, nil, nil
+	}
+	return n, x, t, err
+}
+
+// Drop x's spans, in-place
+func Unlocate(x Expr) Expr {
+	if x == nil {
+		return nil
+	}
+	x.SetSpan(nil)
+
+	switch x.(type) {
+	case *UnitExpr, *IntExpr, *FloatExpr, *BoolExpr, *VarExpr, *DeBruijnBVarExpr:
+	case *ProductExpr:
+		Unlocate(x.(*ProductExpr).Left)
+		Unlocate(x.(*ProductExpr).Right)
+	case *UnaryExpr:
+		Unlocate(x.(*UnaryExpr).Right)
+	case *BinaryExpr:
+		Unlocate(x.(*BinaryExpr).Left)
+		Unlocate(x.(*BinaryExpr).Right)
+	case *AppExpr:
+		Unlocate(x.(*AppExpr).Left)
+		Unlocate(x.(*AppExpr).Right)
+	case *AbsExpr:
+		Unlocate(x.(*AbsExpr).Right)
+	case *DeBruijnAbsExpr:
+		Unlocate(x.(*DeBruijnAbsExpr).Right)
+	case *LetProductExpr:
+		Unlocate(x.(*LetProductExpr).Left)
+		Unlocate(x.(*LetProductExpr).Right)
+	case *LetExpr:
+		Unlocate(x.(*LetExpr).Left)
+		Unlocate(x.(*LetExpr).Right)
+	case *LetUnitExpr:
+		Unlocate(x.(*LetUnitExpr).Left)
+		Unlocate(x.(*LetUnitExpr).Right)
+	case *IfExpr:
+		Unlocate(x.(*IfExpr).Cond)
+		Unlocate(x.(*IfExpr).Left)
+		Unlocate(x.(*IfExpr).Right)
+	case *InjExpr:
+		Unlocate(x.(*InjExpr).Right)
+	case *AnnotExpr:
+		Unlocate(x.(*AnnotExpr).X)
+	case *TypeAbsExpr:
+		Unlocate(x.(*TypeAbsExpr).Right)
+	case *TypeAppExpr:
+		Unlocate(x.(*TypeAppExpr).Left)
+	case *MatchExpr:
+		Unlocate(x.(*MatchExpr).X)
+		Unlocate(x.(*MatchExpr).Left)
+		Unlocate(x.(*MatchExpr).Right)
+	default:
+		panic("assert")
+	}
+
+	return x
+}
@@ -0,0 +1,1697 @@
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/mbivert/ftests"
+
+	"github.com/mbivert/golc/internal/testutil"
+	. "github.com/mbivert/golc/syntax"
+)
+
+/*
+ * Many of those tests are recycled from
+ *	https://github.com/mbivert/nix-series-code/blob/master/lambda/parse_test.nix
+ *	https://github.com/mbivert/nix-series-code/blob/master/exprs_test.nix
+ *
+ * More tests to import from:
+ *	https://github.com/mbivert/nix-series-code/blob/master/lambda_test.nix
+ *
+ * We're focusing here on basic lambda calculus extended with some
+ * scalar types (eg. bool, int, float) and basic arithmetic operations.
+ */
+
+func TestParserMathExprs(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"empty input",
+			parseNoSpan,
+			[]any{"", ""},
+			[]any{nil, diagErr(1, 1, 1, "Unexpected token: EOF")},
+		},
+		{
+			"single int",
+			parseNoSpan,
+			[]any{"  1234", ""},
+			[]any{&IntExpr{Node{&IntType{}, nil}, 1234}, nil},
+		},
+		{
+			"single (int)",
+			parseNoSpan,
+			[]any{"  (1234)", ""},
+			[]any{&IntExpr{Node{&IntType{}, nil}, 1234}, nil},
+		},
+		{
+			"single ((int))",
+			parseNoSpan,
+			[]any{"  ((1234))", ""},
+			[]any{&IntExpr{Node{&IntType{}, nil}, 1234}, nil},
+		},
+		{
+			"single float",
+			parseNoSpan,
+			[]any{"  1234.45 ", ""},
+			[]any{&FloatExpr{Node{&FloatType{}, nil}, 1234.45}, nil},
+		},
+		{
+			"single boolean",
+			parseNoSpan,
+			[]any{"  true ", ""},
+			[]any{&BoolExpr{Node{&BoolType{}, nil}, true}, nil},
+		},
+		{
+			"single boolean (bis)",
+			parseNoSpan,
+			[]any{"  false ", ""},
+			[]any{&BoolExpr{Node{&BoolType{}, nil}, false}, nil},
+		},
+		// NOTE: this will be rejected during the type inference/checking phase
+		{
+			"two consecutives ints: 'bad' function call, still parses OK",
+			parseNoSpan,
+			[]any{"  1234 12", ""},
+			[]any{
+				&AppExpr{Node{}, &IntExpr{Node{&IntType{}, nil}, 1234}, &IntExpr{Node{&IntType{}, nil}, 12}},
+				nil,
+			},
+		},
+		{
+			"unary expression: -12",
+			parseNoSpan,
+			[]any{"  - 12", ""},
+			[]any{
+				&UnaryExpr{Node{}, TokenMinus, &IntExpr{Node{&IntType{}, nil}, 12}},
+				nil,
+			},
+		},
+		{
+			"unary expression: +.12",
+			parseNoSpan,
+			[]any{"  +. 12", ""},
+			[]any{
+				&UnaryExpr{Node{}, TokenFPlus, &IntExpr{Node{&IntType{}, nil}, 12}},
+				nil,
+			},
+		},
+		{
+			"unary expressions: ++.12",
+			parseNoSpan,
+			[]any{"  ++. 12", ""},
+			[]any{
+				&UnaryExpr{
+					Node{},
+					TokenPlus,
+					&UnaryExpr{Node{}, TokenFPlus, &IntExpr{Node{&IntType{}, nil}, 12}},
+				},
+				nil,
+			},
+		},
+		{
+			"single float in parentheses",
+			parseNoSpan,
+			[]any{"  (1234.45) ", ""},
+			[]any{&FloatExpr{Node{&FloatType{}, nil}, 1234.45}, nil},
+		},
+		{
+			"single float in two pairs of parentheses",
+			parseNoSpan,
+			[]any{"  (  (1234.45)\t) ", ""},
+			[]any{&FloatExpr{Node{&FloatType{}, nil}, 1234.45}, nil},
+		},
+		{
+			"Missing parenthesis",
+			parseNoSpan,
+			[]any{"  (  (1234.45)\t ", ""},
+			[]any{
+				nil,
+				diagErr(1, 17, 17, "Expecting left paren, got: EOF", "1:3: unclosed '('"),
+			},
+		},
+		{
+			"single float in two pairs of parentheses, many unary operators",
+			parseNoSpan,
+			[]any{"  +.(  -  (-.-1234.45)\t) ", ""},
+			[]any{
+				&UnaryExpr{
+					Node{},
+					TokenFPlus,
+					&UnaryExpr{
+						Node{},
+						TokenMinus,
+						&UnaryExpr{
+							Node{},
+							TokenFMinus,
+							&UnaryExpr{
+								Node{},
+								TokenMinus,
+								&FloatExpr{Node{&FloatType{}, nil}, 1234.45},
+							},
+						},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"left-associativy, addition",
+			parseNoSpan,
+			[]any{"1+2+ 3 ", ""},
+			[]any{
+				&BinaryExpr{
+					Node{},
+					TokenPlus,
+					&BinaryExpr{
+						Node{},
+						TokenPlus,
+						&IntExpr{Node{&IntType{}, nil}, 1},
+						&IntExpr{Node{&IntType{}, nil}, 2},
+					},
+					&IntExpr{Node{&IntType{}, nil}, 3},
+				},
+				nil,
+			},
+		},
+		{
+			"left-associativy, addition/substraction: 1-42+12 ≠ 1-(42+12)",
+			parseNoSpan,
+			[]any{"1-42+12", ""},
+			[]any{
+				&BinaryExpr{
+					Node{},
+					TokenPlus,
+					&BinaryExpr{
+						Node{},
+						TokenMinus,
+						&IntExpr{Node{&IntType{}, nil}, 1},
+						&IntExpr{Node{&IntType{}, nil}, 42},
+					},
+					&IntExpr{Node{&IntType{}, nil}, 12},
+				},
+				nil,
+			},
+		},
+		{
+			"multiplication has precedence over addition",
+			parseNoSpan,
+			[]any{"1.*.2.+. 3. ", ""},
+			[]any{
+				&BinaryExpr{
+					Node{},
+					TokenFPlus,
+					&BinaryExpr{
+						Node{},
+						TokenFStar,
+						&FloatExpr{Node{&FloatType{}, nil}, 1.},
+						&FloatExpr{Node{&FloatType{}, nil}, 2.},
+					},
+					&FloatExpr{Node{&FloatType{}, nil}, 3.},
+				},
+				nil,
+			},
+		},
+		{
+			"random expression",
+			parseNoSpan,
+			[]any{"1.*.(2.+. 3.)", ""},
+			[]any{
+				&BinaryExpr{
+					Node{},
+					TokenFStar,
+					&FloatExpr{Node{&FloatType{}, nil}, 1.},
+					&BinaryExpr{
+						Node{},
+						TokenFPlus,
+						&FloatExpr{Node{&FloatType{}, nil}, 2.},
+						&FloatExpr{Node{&FloatType{}, nil}, 3.},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"Comparison: x < 3",
+			parseNoSpan,
+			[]any{"x < 3", ""},
+			[]any{
+				&BinaryExpr{
+					Node{},
+					TokenLess,
+					&VarExpr{Node{}, "x"},
+					&IntExpr{Node{&IntType{}, nil}, 3},
+				},
+				nil,
+			},
+		},
+		{
+			"Comparison: x < (3+67)",
+			parseNoSpan,
+			[]any{"x < (3 +67)", ""},
+			[]any{
+				&BinaryExpr{
+					Node{},
+					TokenLess,
+					&VarExpr{Node{}, "x"},
+					&BinaryExpr{
+						Node{},
+						TokenPlus,
+						&IntExpr{Node{&IntType{}, nil}, 3},
+						&IntExpr{Node{&IntType{}, nil}, 67},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"1- 3 * 5 + (1 + 34  )/ 3.",
+			parseNoSpan,
+			[]any{"1- 3 * 5 + (1 + 34  )/ 3.", ""},
+			[]any{
+				&BinaryExpr{
+					Node{},
+					TokenPlus,
+					&BinaryExpr{
+						Node{},
+						TokenMinus,
+						&IntExpr{Node{&IntType{}, nil}, 1},
+						&BinaryExpr{
+							Node{},
+							TokenStar,
+							&IntExpr{Node{&IntType{}, nil}, 3},
+							&IntExpr{Node{&IntType{}, nil}, 5},
+						},
+					},
+					&BinaryExpr{
+						Node{},
+						TokenSlash,
+						&BinaryExpr{
+							Node{},
+							TokenPlus,
+							&IntExpr{Node{&IntType{}, nil}, 1},
+							&IntExpr{Node{&IntType{}, nil}, 34},
+						},
+						&FloatExpr{Node{&FloatType{}, nil}, 3.},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"0	/ 78 * 12",
+			parseNoSpan,
+			[]any{"0	/ 78 * 12", ""},
+			[]any{
+				&BinaryExpr{
+					Node{},
+					TokenStar,
+					&BinaryExpr{
+						Node{},
+						TokenSlash,
+						&IntExpr{Node{&IntType{}, nil}, 0},
+						&IntExpr{Node{&IntType{}, nil}, 78},
+					},
+					&IntExpr{Node{&IntType{}, nil}, 12},
+				},
+				nil,
+			},
+		},
+		{
+			"!(true)",
+			parseNoSpan,
+			[]any{"!(true)", ""},
+			[]any{
+				&UnaryExpr{
+					Node{},
+					TokenExcl,
+					&BoolExpr{Node{&BoolType{}, nil}, true},
+				},
+				nil,
+			},
+		},
+		{
+			"3. ≤. 5.",
+			parseNoSpan,
+			[]any{"3. ≤. 5.", ""},
+			[]any{
+				&BinaryExpr{
+					Node{},
+					TokenFLessEq,
+					&FloatExpr{Node{&FloatType{}, nil}, 3.},
+					&FloatExpr{Node{&FloatType{}, nil}, 5.},
+				},
+				nil,
+			},
+		},
+	})
+}
+
+func TestParserUntypedλCalc(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"basic abstraction",
+			parseNoSpan,
+			[]any{"λx.x", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&UnknownType{},
+					"x",
+					&VarExpr{Node{}, "x"},
+				},
+				nil,
+			},
+		},
+		{
+			"basic abstraction (optional λ)",
+			parseNoSpan,
+			[]any{"x.x", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&UnknownType{},
+					"x",
+					&VarExpr{Node{}, "x"},
+				},
+				nil,
+			},
+		},
+		{
+			"abstraction: syntax error (missing dot)",
+			parseNoSpan,
+			[]any{"\nλx x", ""},
+			[]any{
+				nil,
+				diagErr(2, 4, 5, "Expecting dot after lambda variable name, got: name"),
+			},
+		},
+		{
+			"abstraction: syntax error (missing variable name)",
+			parseNoSpan,
+			[]any{"\nλ.x x", ""},
+			[]any{
+				nil,
+				diagErr(2, 2, 3, "Expecting variable name after lambda, got: ."),
+			},
+		},
+		{
+			"abstraction: syntax error (missing variable name)",
+			parseNoSpan,
+			[]any{"λx. ", ""},
+			[]any{
+				nil,
+				diagErr(1, 5, 5, "Unexpected token: EOF"),
+			},
+		},
+		{
+			"(λx. x x) (λx. x x)",
+			parseNoSpan,
+			[]any{"(λx. x x) (λx. x x)", ""},
+			[]any{
+				&AppExpr{
+					Node{},
+					&AbsExpr{
+						Node{},
+						&UnknownType{},
+						"x",
+						&AppExpr{
+							Node{},
+							&VarExpr{Node{}, "x"},
+							&VarExpr{Node{}, "x"},
+						},
+					},
+					&AbsExpr{
+						Node{},
+						&UnknownType{},
+						"x",
+						&AppExpr{
+							Node{},
+							&VarExpr{Node{}, "x"},
+							&VarExpr{Node{}, "x"},
+						},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"(λx. (λy. x))",
+			parseNoSpan,
+			[]any{"(λx. (λy. x))", ""},
+			[]any{testutil.T, nil},
+		},
+		{
+			"(λx. λy. x)",
+			parseNoSpan,
+			[]any{"(λx. λy. x)", ""},
+			[]any{testutil.T, nil},
+		},
+		{
+			"λx.λy.x",
+			parseNoSpan,
+			[]any{"λx.λy.x", ""},
+			[]any{testutil.T, nil},
+		},
+		{
+			"x. (one (two (three (four five))))",
+			parseNoSpan,
+			[]any{"x. (one (two (three (four five))))", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&UnknownType{},
+					"x",
+					&AppExpr{
+						Node{},
+						&VarExpr{Node{}, "one"},
+						&AppExpr{
+							Node{},
+							&VarExpr{Node{}, "two"},
+							&AppExpr{
+								Node{},
+								&VarExpr{Node{}, "three"},
+								&AppExpr{
+									Node{},
+									&VarExpr{Node{}, "four"},
+									&VarExpr{Node{}, "five"},
+								},
+							},
+						},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"x. one two three four five",
+			parseNoSpan,
+			[]any{"x. one two three four five", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&UnknownType{},
+					"x",
+					&AppExpr{
+						Node{},
+						&AppExpr{
+							Node{},
+							&AppExpr{
+								Node{},
+								&AppExpr{
+									Node{},
+									&VarExpr{Node{}, "one"},
+									&VarExpr{Node{}, "two"},
+								},
+								&VarExpr{Node{}, "three"},
+							},
+							&VarExpr{Node{}, "four"},
+						},
+						&VarExpr{Node{}, "five"},
+					},
+				},
+				nil,
+			},
+		},
+	})
+}
+
+func TestParserPrimitiveType(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"boolean",
+			parseNoSpan,
+			[]any{"λx : bool . x && y", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&BoolType{},
+					"x",
+					&BinaryExpr{
+						Node{},
+						TokenAndAnd,
+						&VarExpr{Node{}, "x"},
+						&VarExpr{Node{}, "y"},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"int",
+			parseNoSpan,
+			[]any{"λx : int . x + y", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&IntType{},
+					"x",
+					&BinaryExpr{
+						Node{},
+						TokenPlus,
+						&VarExpr{Node{}, "x"},
+						&VarExpr{Node{}, "y"},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"float",
+			parseNoSpan,
+			[]any{"λx : float . x +. y", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&FloatType{},
+					"x",
+					&BinaryExpr{
+						Node{},
+						TokenFPlus,
+						&VarExpr{Node{}, "x"},
+						&VarExpr{Node{}, "y"},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"float",
+			parseNoSpan,
+			[]any{"λx : unit. *", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&UnitType{},
+					"x",
+					&UnitExpr{Node{&UnitType{}, nil}},
+				},
+				nil,
+			},
+		},
+	})
+}
+
+func TestParserArrowType(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"bool → bool",
+			parseNoSpan,
+			[]any{"λx : bool → bool . x y", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&ArrowType{&BoolType{}, &BoolType{}},
+					"x",
+					&AppExpr{
+						Node{},
+						&VarExpr{Node{}, "x"},
+						&VarExpr{Node{}, "y"},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"bool → bool → bool (right associative: bool → (bool → bool))",
+			parseNoSpan,
+			[]any{"λx : bool → bool → bool . x y z", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&ArrowType{
+						&BoolType{}, &ArrowType{
+							&BoolType{}, &BoolType{},
+						},
+					},
+					"x",
+					&AppExpr{
+						Node{},
+						&AppExpr{
+							Node{},
+							&VarExpr{Node{}, "x"},
+							&VarExpr{Node{}, "y"},
+						},
+						&VarExpr{Node{}, "z"},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"bool → bool → bool → int",
+			parseNoSpan,
+			[]any{"λx : bool → bool → bool → int . (x y z) + 3", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&ArrowType{
+						&BoolType{}, &ArrowType{
+							&BoolType{}, &ArrowType{
+								&BoolType{}, &IntType{},
+							},
+						},
+					},
+					"x",
+					&BinaryExpr{
+						Node{},
+						TokenPlus,
+						&AppExpr{
+							Node{},
+							&AppExpr{
+								Node{},
+								&VarExpr{Node{}, "x"},
+								&VarExpr{Node{}, "y"},
+							},
+							&VarExpr{Node{}, "z"},
+						},
+						&IntExpr{Node{&IntType{}, nil}, 3},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"(bool → bool) → bool (manually altered associativity)",
+			parseNoSpan,
+			[]any{"λx : (bool → bool) → bool . x y z", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&ArrowType{
+						&ArrowType{
+							&BoolType{}, &BoolType{},
+						},
+						&BoolType{},
+					},
+					"x",
+					&AppExpr{
+						Node{},
+						&AppExpr{
+							Node{},
+							&VarExpr{Node{}, "x"},
+							&VarExpr{Node{}, "y"},
+						},
+						&VarExpr{Node{}, "z"},
+					},
+				},
+				nil,
+			},
+		},
+	})
+}
+
+// same as TestArrowType(), but systematically using the short
+// form (x: $type . $expr instead of λx: $type . $expr
+func TestParserArrowTypeShort(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"bool → bool",
+			parseNoSpan,
+			[]any{"x : bool → bool . x y", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&ArrowType{&BoolType{}, &BoolType{}},
+					"x",
+					&AppExpr{
+						Node{},
+						&VarExpr{Node{}, "x"},
+						&VarExpr{Node{}, "y"},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"bool → bool → bool (right associative: bool → (bool → bool))",
+			parseNoSpan,
+			[]any{"x : bool → bool → bool . x y z", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&ArrowType{
+						&BoolType{}, &ArrowType{
+							&BoolType{}, &BoolType{},
+						},
+					},
+					"x",
+					&AppExpr{
+						Node{},
+						&AppExpr{
+							Node{},
+							&VarExpr{Node{}, "x"},
+							&VarExpr{Node{}, "y"},
+						},
+						&VarExpr{Node{}, "z"},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"bool → bool → bool → int",
+			parseNoSpan,
+			[]any{"x : bool → bool → bool → int . (x y z) + 3", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&ArrowType{
+						&BoolType{}, &ArrowType{
+							&BoolType{}, &ArrowType{
+								&BoolType{}, &IntType{},
+							},
+						},
+					},
+					"x",
+					&BinaryExpr{
+						Node{},
+						TokenPlus,
+						&AppExpr{
+							Node{},
+							&AppExpr{
+								Node{},
+								&VarExpr{Node{}, "x"},
+								&VarExpr{Node{}, "y"},
+							},
+							&VarExpr{Node{}, "z"},
+						},
+						&IntExpr{Node{&IntType{}, nil}, 3},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"(bool → bool) → bool (manually altered associativity)",
+			parseNoSpan,
+			[]any{"x : (bool → bool) → bool . x y z", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&ArrowType{
+						&ArrowType{
+							&BoolType{}, &BoolType{},
+						},
+						&BoolType{},
+					},
+					"x",
+					&AppExpr{
+						Node{},
+						&AppExpr{
+							Node{},
+							&VarExpr{Node{}, "x"},
+							&VarExpr{Node{}, "y"},
+						},
+						&VarExpr{Node{}, "z"},
+					},
+				},
+				nil,
+			},
+		},
+	})
+}
+
+// "we adopt the convention that × binds stronger than →"
+func TestParserArrowProductType(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"bool × int → bool := (bool×int) → bool",
+			parseNoSpan,
+			[]any{"λx : bool×int → bool . x y", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&ArrowType{&ProductType{
+						&BoolType{}, &IntType{},
+					}, &BoolType{}},
+					"x",
+					&AppExpr{
+						Node{},
+						&VarExpr{Node{}, "x"},
+						&VarExpr{Node{}, "y"},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"bool × (int → bool)",
+			parseNoSpan,
+			[]any{"λx : bool×(int → bool) . x y", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&ProductType{&BoolType{}, &ArrowType{
+						&IntType{}, &BoolType{},
+					}},
+					"x",
+					&AppExpr{
+						Node{},
+						&VarExpr{Node{}, "x"},
+						&VarExpr{Node{}, "y"},
+					},
+				},
+				nil,
+			},
+		},
+	})
+}
+
+// ⊗ and ⊸ are at the × and → levels; ! binds stronger
+// than all of them.
+func TestParserLinearType(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"!int ⊸ int ⊗ bool := (!int) ⊸ (int ⊗ bool)",
+			parseNoSpan,
+			[]any{"λx : !int ⊸ int ⊗ bool . x", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&LinArrowType{&BangType{&IntType{}}, &TensorType{
+						&IntType{}, &BoolType{},
+					}},
+					"x",
+					&VarExpr{Node{}, "x"},
+				},
+				nil,
+			},
+		},
+		{
+			"!(int -* int) -> int ** int, ASCII",
+			parseNoSpan,
+			[]any{"\\x : !(int -* int) -> int ** int . x", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&ArrowType{&BangType{&LinArrowType{
+						&IntType{}, &IntType{},
+					}}, &TensorType{&IntType{}, &IntType{}}},
+					"x",
+					&VarExpr{Node{}, "x"},
+				},
+				nil,
+			},
+		},
+	})
+}
+
+// again, given what's in qlambdabook.pdf, we assume ×
+// to be right-associative.
+func TestParserProductType(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"bool × int × bool := bool×(int×bool)",
+			parseNoSpan,
+			[]any{"λx : bool×int×bool . x y", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&ProductType{&BoolType{}, &ProductType{
+						&IntType{}, &BoolType{},
+					}},
+					"x",
+					&AppExpr{
+						Node{},
+						&VarExpr{Node{}, "x"},
+						&VarExpr{Node{}, "y"},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"(bool × int) × bool",
+			parseNoSpan,
+			[]any{"λx : (bool×int)×bool . x y", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&ProductType{&ProductType{
+						&BoolType{}, &IntType{},
+					}, &BoolType{}},
+					"x",
+					&AppExpr{
+						Node{},
+						&VarExpr{Node{}, "x"},
+						&VarExpr{Node{}, "y"},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"& spells ×",
+			parseNoSpan,
+			[]any{"λx : bool&int . x", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&ProductType{&BoolType{}, &IntType{}},
+					"x",
+					&VarExpr{Node{}, "x"},
+				},
+				nil,
+			},
+		},
+		{
+			"& isn't a boolean and",
+			parseNoSpan,
+			[]any{"true & false", ""},
+			[]any{nil, diagErr(1, 6, 7, "Unexpected token: ×",
+				"'&' is the ASCII spelling of '×'; boolean and is '&&'")},
+		},
+	})
+}
+
+func TestParserProduct(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"<>",
+			parseNoSpan,
+			[]any{"〈〉", ""},
+			[]any{
+				nil,
+				diagErr(1, 2, 3, "Unexpected token: 〉"),
+			},
+		},
+		{
+			"<X>",
+			parseNoSpan,
+			[]any{"〈X〉", ""},
+			[]any{
+				&VarExpr{Node{}, "X"},
+				nil,
+			},
+		},
+		{
+			"<X, Y>",
+			parseNoSpan,
+			[]any{"〈X, Y〉", ""},
+			[]any{
+				&ProductExpr{Node{},
+					&VarExpr{Node{}, "X"},
+					&VarExpr{Node{}, "Y"},
+				},
+				nil,
+			},
+		},
+		{
+			"<X, Y, Z>",
+			parseNoSpan,
+			[]any{"〈X, Y, Z〉", ""},
+			[]any{
+				&ProductExpr{Node{},
+					&VarExpr{Node{}, "X"},
+					&ProductExpr{Node{},
+						&VarExpr{Node{}, "Y"},
+						&VarExpr{Node{}, "Z"},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"<X, <Y, Z>>",
+			parseNoSpan,
+			[]any{"〈X, 〈Y, Z〉〉", ""},
+			[]any{
+				&ProductExpr{Node{},
+					&VarExpr{Node{}, "X"},
+					&ProductExpr{Node{},
+						&VarExpr{Node{}, "Y"},
+						&VarExpr{Node{}, "Z"},
+					},
+				},
+				nil,
+			},
+		},
+	})
+}
+
+func TestParserBasicLetIn(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"let",
+			parseNoSpan,
+			[]any{"let", ""},
+			[]any{
+				nil,
+				diagErr(1, 4, 4, "Expecting variable name after let, got: EOF"),
+			},
+		},
+		{
+			"let 42",
+			parseNoSpan,
+			[]any{"let 42", ""},
+			[]any{
+				nil,
+				diagErr(1, 5, 7, "Expecting variable name after let, got: int64"),
+			},
+		},
+		{
+			"let x 42",
+			parseNoSpan,
+			[]any{"let x 42", ""},
+			[]any{
+				nil,
+				diagErr(1, 7, 9, "Expecting equal after let $x, got: int64"),
+			},
+		},
+		{
+			"let/in as an argument",
+			parseNoSpan,
+			[]any{"f let x = 42 in x", ""},
+			[]any{
+				testutil.MustParse("f"),
+				diagErr(1, 3, 6, "Unexpected token: let"),
+			},
+		},
+		{
+			"let x = 42",
+			parseNoSpan,
+			[]any{"let x = 42", ""},
+			[]any{
+				nil,
+				diagErr(1, 11, 11, "Expecting 'in' after let $x = $M, got EOF"),
+			},
+		},
+		{
+			"let x = 42 in",
+			parseNoSpan,
+			[]any{"let x = 42 in", ""},
+			[]any{
+				nil,
+				diagErr(1, 14, 14, "Unexpected token: EOF"),
+			},
+		},
+		{
+			"let x = 42 in x",
+			parseNoSpan,
+			[]any{"let x = 42 in x", ""},
+			[]any{
+				&LetExpr{Node{},
+					&IntExpr{Node{&IntType{}, nil}, 42},
+					&AbsExpr{Node{},
+						&UnknownType{},
+						"x",
+						&VarExpr{Node{}, "x"},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"let x = 42 in x + 3",
+			parseNoSpan,
+			[]any{"let x = 42 in x + 3", ""},
+			[]any{
+				&LetExpr{Node{},
+					&IntExpr{Node{&IntType{}, nil}, 42},
+					&AbsExpr{Node{},
+						&UnknownType{},
+						"x",
+						&BinaryExpr{Node{},
+							TokenPlus,
+							&VarExpr{Node{}, "x"},
+							&IntExpr{Node{&IntType{}, nil}, 3},
+						},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"let x = 42:int in x + 3",
+			parseNoSpan,
+			[]any{"let x = 42 : int in x + 3", ""},
+			[]any{
+				&LetExpr{Node{},
+					&IntExpr{Node{&IntType{}, nil}, 42},
+					&AbsExpr{Node{},
+						&IntType{},
+						"x",
+						&BinaryExpr{Node{},
+							TokenPlus,
+							&VarExpr{Node{}, "x"},
+							&IntExpr{Node{&IntType{}, nil}, 3},
+						},
+					},
+				},
+				nil,
+			},
+		},
+	})
+}
+
+func TestParserLetPatterns(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"let 〈x, y〉 = p in x",
+			parseNoSpan,
+			[]any{"let 〈x, y〉 = p in x", ""},
+			[]any{
+				&LetProductExpr{Node{}, 2,
+					&VarExpr{Node{}, "p"},
+					&AbsExpr{Node{}, &UnknownType{}, "x",
+						&AbsExpr{Node{}, &UnknownType{}, "y",
+							&VarExpr{Node{}, "x"},
+						},
+					},
+				},
+				nil,
+			},
+		},
+		{
+			"〈x〉 is x",
+			parseNoSpan,
+			[]any{"let 〈x〉 = 1 in x", ""},
+			[]any{testutil.MustParse("let x = 1 in x"), nil},
+		},
+		{
+			"let * = u in 1",
+			parseNoSpan,
+			[]any{"let * = u in 1", ""},
+			[]any{
+				&LetUnitExpr{Node{},
+					&VarExpr{Node{}, "u"},
+					&IntExpr{Node{&IntType{}, nil}, 1},
+				},
+				nil,
+			},
+		},
+		{
+			"name bound twice",
+			parseNoSpan,
+			[]any{"let 〈x, y, x〉 = p in x", ""},
+			[]any{nil, diagErr(1, 12, 13, "'x' bound twice in let 〈...〉")},
+		},
+		{
+			"not a name",
+			parseNoSpan,
+			[]any{"let 〈x, 1〉 = p in x", ""},
+			[]any{nil, diagErr(1, 9, 10, "Expecting variable name in let 〈...〉, got: int64")},
+		},
+		{
+			"missing coma",
+			parseNoSpan,
+			[]any{"let 〈x y〉 = p in x", ""},
+			[]any{nil, diagErr(1, 8, 9, "Expecting ',' or '〉' in let 〈...〉, got: name")},
+		},
+		{
+			"no type annotation",
+			parseNoSpan,
+			[]any{"let * = * : unit in 1", ""},
+			[]any{nil, diagErr(1, 11, 12, "Type annotations are only allowed in let $x = $M")},
+		},
+	})
+}
+
+func TestParserIf(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"if/then/else",
+			parseNoSpan,
+			[]any{"if x < 1 then f x else 2", ""},
+			[]any{
+				&IfExpr{Node{},
+					&BinaryExpr{Node{}, TokenLess,
+						&VarExpr{Node{}, "x"},
+						&IntExpr{Node{&IntType{}, nil}, 1},
+					},
+					&AppExpr{Node{},
+						&VarExpr{Node{}, "f"},
+						&VarExpr{Node{}, "x"},
+					},
+					&IntExpr{Node{&IntType{}, nil}, 2},
+				},
+				nil,
+			},
+		},
+		{
+			"else branch extends to the right",
+			parseNoSpan,
+			[]any{"if a then b else if c then d else λx. x", ""},
+			[]any{testutil.MustParse("if a then b else (if c then d else (λx. x))"), nil},
+		},
+		{
+			"missing then",
+			parseNoSpan,
+			[]any{"if a b else c", ""},
+			[]any{nil, diagErr(1, 8, 12, "Expecting 'then' after if $M, got else")},
+		},
+		{
+			"missing else",
+			parseNoSpan,
+			[]any{"if a then b", ""},
+			[]any{nil, diagErr(1, 12, 12, "Expecting 'else' after if $M then $N, got EOF")},
+		},
+		{
+			"if as an argument",
+			parseNoSpan,
+			[]any{"f if a then b else c", ""},
+			[]any{testutil.MustParse("f"), diagErr(1, 3, 5, "Unexpected token: if")},
+		},
+	})
+}
+
+func TestParserSums(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"× binds stronger than ⊕, which binds stronger than →",
+			parseNoSpan,
+			[]any{"λx : int \\/ bool×float → int. x", ""},
+			[]any{
+				&AbsExpr{
+					Node{},
+					&ArrowType{
+						&SumType{&IntType{}, &ProductType{
+							&BoolType{}, &FloatType{},
+						}},
+						&IntType{},
+					},
+					"x",
+					&VarExpr{Node{}, "x"},
+				},
+				nil,
+			},
+		},
+		{
+			"injections, as unary operators",
+			parseNoSpan,
+			[]any{"〈inl x + 1, inr f〉", ""},
+			[]any{
+				&ProductExpr{Node{},
+					&InjExpr{Node{}, false, &BinaryExpr{Node{}, TokenPlus,
+						&VarExpr{Node{}, "x"},
+						&IntExpr{Node{&IntType{}, nil}, 1},
+					}},
+					&InjExpr{Node{}, true, &VarExpr{Node{}, "f"}},
+				},
+				nil,
+			},
+		},
+		{
+			"match",
+			parseNoSpan,
+			[]any{"match x with inl y → y | inr z → f z", ""},
+			[]any{
+				&MatchExpr{Node{},
+					&VarExpr{Node{}, "x"},
+					&AbsExpr{Node{}, &UnknownType{}, "y", &VarExpr{Node{}, "y"}},
+					&AbsExpr{Node{}, &UnknownType{}, "z", &AppExpr{Node{},
+						&VarExpr{Node{}, "f"},
+						&VarExpr{Node{}, "z"},
+					}},
+				},
+				nil,
+			},
+		},
+		{
+			"branches in any order, optional leading |",
+			parseNoSpan,
+			[]any{"match x with | inr z -> 1 | inl y -> 2", ""},
+			[]any{testutil.MustParse("match x with inl y → 2 | inr z → 1"), nil},
+		},
+		{
+			"a match is complete after two branches",
+			parseNoSpan,
+			[]any{"match x with inl y → match y with inl u → u | inr v → v | inr z → z", ""},
+			[]any{testutil.MustParse("match x with inl y → (match y with inl u → u | inr v → v) | inr z → z"), nil},
+		},
+		{
+			"missing with",
+			parseNoSpan,
+			[]any{"match x", ""},
+			[]any{nil, diagErr(1, 8, 8, "Expecting 'with' after match $M, got EOF")},
+		},
+		{
+			"branch matched twice",
+			parseNoSpan,
+			[]any{"match x with inl y → y | inl z → z", ""},
+			[]any{nil, diagErr(1, 26, 29, "inl matched twice")},
+		},
+		{
+			"missing second branch",
+			parseNoSpan,
+			[]any{"match x with inl y → y", ""},
+			[]any{nil, diagErr(1, 23, 23, "Expecting '|' after match's first branch, got EOF")},
+		},
+	})
+}
+
+func TestParserParseDef(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"not a definition",
+			parseDefNoSpan,
+			[]any{"x + 3", ""},
+			[]any{"", nil, nil, nil},
+		},
+		{
+			"let/in isn't a definition",
+			parseDefNoSpan,
+			[]any{"let x = 42 in x + 3", ""},
+			[]any{"", nil, nil, nil},
+		},
+		{
+			"let x = 42",
+			parseDefNoSpan,
+			[]any{"let x = 42", ""},
+			[]any{"x", &IntExpr{Node{&IntType{}, nil}, 42}, nil, nil},
+		},
+		{
+			"let x = 42 : int",
+			parseDefNoSpan,
+			[]any{"let x = 42 : int", ""},
+			[]any{"x", &IntExpr{Node{&IntType{}, nil}, 42}, &IntType{}, nil},
+		},
+		{
+			"let x = (y) : int: annotated variable, not a short form λ",
+			parseDefNoSpan,
+			[]any{"let x = (y) : int", ""},
+			[]any{"x", &VarExpr{Node{}, "y"}, &IntType{}, nil},
+		},
+		{
+			"let x = 42 43: application",
+			parseDefNoSpan,
+			[]any{"let x = 42 43", ""},
+			[]any{"x", &AppExpr{Node{},
+				&IntExpr{Node{&IntType{}, nil}, 42},
+				&IntExpr{Node{&IntType{}, nil}, 43},
+			}, nil, nil},
+		},
+		{
+			"let x = 42 : int int",
+			parseDefNoSpan,
+			[]any{"let x = 42 : int int", ""},
+			[]any{"x", &IntExpr{Node{&IntType{}, nil}, 42}, &IntType{},
+				diagErr(1, 18, 21, "Unexpected token: int"),
+			},
+		},
+		{
+			"let id = λx:int. x : int → int: annotated abstraction",
+			parseDefNoSpan,
+			[]any{"let id = λx:int. x : int → int;", ""},
+			[]any{"id", &AbsExpr{Node{}, &IntType{}, "x", &VarExpr{Node{}, "x"}},
+				&ArrowType{&IntType{}, &IntType{}}, nil},
+		},
+		{
+			"let = 42",
+			parseDefNoSpan,
+			[]any{"let = 42", ""},
+			[]any{"", nil, nil,
+				diagErr(1, 5, 6, "Expecting variable name after let, got: ="),
+			},
+		},
+	})
+}
+
+func TestParserAscriptions(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"ascribed variable",
+			parseNoSpan,
+			[]any{"(x : int)", ""},
+			[]any{&AnnotExpr{Node{}, &VarExpr{Node{}, "x"}, &IntType{}}, nil},
+		},
+		{
+			"ascribed abstraction, whose body is a variable",
+			parseNoSpan,
+			[]any{"(λx. x : int → int)", ""},
+			[]any{&AnnotExpr{Node{},
+				&AbsExpr{Node{}, &UnknownType{}, "x", &VarExpr{Node{}, "x"}},
+				&ArrowType{&IntType{}, &IntType{}},
+			}, nil},
+		},
+		{
+			"ascribed variable, in an abstraction",
+			parseNoSpan,
+			[]any{"λx. (x : int)", ""},
+			[]any{&AbsExpr{Node{}, &UnknownType{}, "x",
+				&AnnotExpr{Node{}, &VarExpr{Node{}, "x"}, &IntType{}},
+			}, nil},
+		},
+		{
+			"applied ascribed variable",
+			parseNoSpan,
+			[]any{"(f : int → int) 1", ""},
+			[]any{&AppExpr{Node{},
+				&AnnotExpr{Node{}, &VarExpr{Node{}, "f"},
+					&ArrowType{&IntType{}, &IntType{}}},
+				&IntExpr{Node{&IntType{}, nil}, 1},
+			}, nil},
+		},
+		{
+			"short form abstraction, parenthesized",
+			parseNoSpan,
+			[]any{"(x : int. x)", ""},
+			[]any{&AbsExpr{Node{}, &IntType{}, "x", &VarExpr{Node{}, "x"}}, nil},
+		},
+		{
+			"top-level ascription, unparenthesized",
+			parseNoSpan,
+			[]any{"(λf. f 1) : (int → int) → int", ""},
+			[]any{&AnnotExpr{Node{},
+				&AbsExpr{Node{}, &UnknownType{}, "f", &AppExpr{Node{},
+					&VarExpr{Node{}, "f"},
+					&IntExpr{Node{&IntType{}, nil}, 1},
+				}},
+				&ArrowType{&ArrowType{&IntType{}, &IntType{}}, &IntType{}},
+			}, nil},
+		},
+		{
+			"only at the top-level",
+			parseNoSpan,
+			[]any{"f x : int + 1", ""},
+			[]any{&AnnotExpr{Node{},
+				&AppExpr{Node{}, &VarExpr{Node{}, "f"}, &VarExpr{Node{}, "x"}},
+				&IntType{},
+			}, diagErr(1, 11, 12, "Unexpected token: +")},
+		},
+		{
+			"invalid type, still reported",
+			parseNoSpan,
+			[]any{"(x : λ)", ""},
+			[]any{nil, diagErr(1, 6, 7, "Unexpected token: λ")},
+		},
+	})
+}
+
+// Spans are tested separately (TestParserSpans)
+func parseNoSpan(src, fn string) (Expr, error) {
+	x, err := Parse(src, fn)
+	return Unlocate(x), err
+}
+
+func parseDefNoSpan(src, fn string) (string, Expr, Type, error) {
+	n, x, t, err := ParseDef(src, fn)
+	return n, Unlocate(x), t, err
+}
+
+// Single error diagnostic, on line ln, from column cn to ecn
+func diagErr(ln, cn, ecn uint, m string, notes ...string) error {
+	return Diagnostics{{SeverityError, "", Pos{ln, cn}, Pos{ln, ecn}, m, notes}}
+}
+
+// Spans of x's nodes, in prefix order
+func dumpSpans(x Expr) []string {
+	var xs []string
+	var aux func(Expr)
+
+	aux = func(x Expr) {
+		s := x.Span()
+		if s == nil {
+			xs = append(xs, "?")
+		} else {
+			xs = append(xs, s.File+":"+s.Start.String()+"-"+s.End.String())
+		}
+
+		switch x.(type) {
+		case *ProductExpr:
+			aux(x.(*ProductExpr).Left)
+			aux(x.(*ProductExpr).Right)
+		case *UnaryExpr:
+			aux(x.(*UnaryExpr).Right)
+		case *BinaryExpr:
+			aux(x.(*BinaryExpr).Left)
+			aux(x.(*BinaryExpr).Right)
+		case *AppExpr:
+			aux(x.(*AppExpr).Left)
+			aux(x.(*AppExpr).Right)
+		case *AbsExpr:
+			aux(x.(*AbsExpr).Right)
+		case *LetExpr:
+			aux(x.(*LetExpr).Left)
+			aux(x.(*LetExpr).Right)
+		case *AnnotExpr:
+			aux(x.(*AnnotExpr).X)
+		}
+	}
+
+	aux(x)
+	return xs
+}
+
+func parseSpans(src string) []string {
+	x, err := Parse(src, "f.lc")
+	if err != nil {
+		panic(err)
+	}
+	return dumpSpans(x)
+}
+
+func TestParserSpans(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"literals, operators",
+			parseSpans,
+			[]any{"1 + -2.5 * x"},
+			[]any{[]string{"f.lc:1:1-1:13", "f.lc:1:1-1:2",
+				"f.lc:1:5-1:13", "f.lc:1:6-1:13", "f.lc:1:6-1:9", "f.lc:1:12-1:13"}},
+		},
+		{
+			"parenthesis aren't part of the span",
+			parseSpans,
+			[]any{"( x )"},
+			[]any{[]string{"f.lc:1:3-1:4"}},
+		},
+		{
+			"ascriptions span their parenthesis",
+			parseSpans,
+			[]any{"(f x : int)"},
+			[]any{[]string{"f.lc:1:1-1:12", "f.lc:1:2-1:5", "f.lc:1:2-1:3", "f.lc:1:4-1:5"}},
+		},
+		{
+			"abstraction, application over several lines",
+			parseSpans,
+			[]any{"λx:int.\n  f x\n  true"},
+			[]any{[]string{"f.lc:1:1-3:7", "f.lc:2:3-3:7", "f.lc:2:3-2:6",
+				"f.lc:2:3-2:4", "f.lc:2:5-2:6", "f.lc:3:3-3:7"}},
+		},
+		{
+			"short abstraction",
+			parseSpans,
+			[]any{"x. x"},
+			[]any{[]string{"f.lc:1:1-1:5", "f.lc:1:4-1:5"}},
+		},
+		{
+			"products",
+			parseSpans,
+			[]any{"〈1, *, x〉"},
+			[]any{[]string{"f.lc:1:1-1:10", "f.lc:1:2-1:3",
+				"f.lc:1:5-1:9", "f.lc:1:5-1:6", "f.lc:1:8-1:9"}},
+		},
+		{
+			"let/in: both nodes span the whole construct",
+			parseSpans,
+			[]any{"let x = 1 in x"},
+			[]any{[]string{"f.lc:1:1-1:15", "f.lc:1:9-1:10", "f.lc:1:1-1:15", "f.lc:1:14-1:15"}},
+		},
+		{
+			"substitution carries the spans through",
+			func(src string) []string {
+				x, _ := Parse(src, "f.lc")
+				return dumpSpans(testutil.MustEval(x))
+			},
+			[]any{"(λx. x y) (z w)"},
+			[]any{[]string{"f.lc:1:6-1:9", "f.lc:1:12-1:15",
+				"f.lc:1:12-1:13", "f.lc:1:14-1:15", "f.lc:1:8-1:9"}},
+		},
+		{
+			"δ-reduction results don't come from the source",
+			func(src string) []string {
+				x, _ := Parse(src, "f.lc")
+				return dumpSpans(testutil.MustEval(x))
+			},
+			[]any{"(λx. x + 1) 2"},
+			[]any{[]string{"?"}},
+		},
+	})
+}
+
+func parseNumber(src string) (Expr, error) {
+	return parseNoSpan(src, "")
+}
+
+func TestParserNumbers(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"bases, separators, leading zeros",
+			func(src string) (int64, int64, int64, int64, int64) {
+				x := testutil.MustParse(src).(*ProductExpr)
+				y := x.Right.(*ProductExpr)
+				z := y.Right.(*ProductExpr)
+				w := z.Right.(*ProductExpr)
+				return x.Left.(*IntExpr).Value, y.Left.(*IntExpr).Value, z.Left.(*IntExpr).Value,
+					w.Left.(*IntExpr).Value, w.Right.(*IntExpr).Value
+			},
+			[]any{"〈0x_ff, 0o17, 0b101, 1_000_000, 0123〉"},
+			[]any{int64(255), int64(15), int64(5), int64(1000000), int64(123)},
+		},
+		{
+			"largest integer",
+			parseNumber,
+			[]any{"9223372036854775807"},
+			[]any{&IntExpr{Node{&IntType{}, nil}, 9223372036854775807}, nil},
+		},
+		{
+			"integer overflow",
+			parseNumber,
+			[]any{"1 + 9223372036854775808"},
+			[]any{nil, diagErr(1, 5, 24, "Integer literal out of range: 9223372036854775808")},
+		},
+		{
+			"hexadecimal overflow",
+			parseNumber,
+			[]any{"0x1_0000_0000_0000_0000"},
+			[]any{nil, diagErr(1, 1, 24, "Integer literal out of range: 0x1_0000_0000_0000_0000")},
+		},
+		{
+			"correctly rounded floats",
+			parseNumber,
+			[]any{"0.1"},
+			[]any{&FloatExpr{Node{&FloatType{}, nil}, 0.1}, nil},
+		},
+		{
+			"many digits",
+			parseNumber,
+			[]any{"3.14159265358979323846264338327950288"},
+			[]any{&FloatExpr{Node{&FloatType{}, nil}, 3.14159265358979323846264338327950288}, nil},
+		},
+		{
+			"scientific notation",
+			parseNumber,
+			[]any{"1.5e-3"},
+			[]any{&FloatExpr{Node{&FloatType{}, nil}, 1.5e-3}, nil},
+		},
+		{
+			"float overflow",
+			parseNumber,
+			[]any{"1e400"},
+			[]any{nil, diagErr(1, 1, 6, "Float literal out of range: 1e400")},
+		},
+		{
+			"float underflow",
+			parseNumber,
+			[]any{"1e-400"},
+			[]any{nil, diagErr(1, 1, 7, "Float literal out of range: 1e-400")},
+		},
+		{
+			"smallest denormal",
+			parseNumber,
+			[]any{"5e-324"},
+			[]any{&FloatExpr{Node{&FloatType{}, nil}, 5e-324}, nil},
+		},
+		{
+			"zero doesn't underflow",
+			parseNumber,
+			[]any{"0.0e-400"},
+			[]any{&FloatExpr{Node{&FloatType{}, nil}, 0}, nil},
+		},
+		{
+			"leading zero is decimal, not octal",
+			parseNumber,
+			[]any{"017"},
+			[]any{&IntExpr{Node{&IntType{}, nil}, 17}, nil},
+		},
+	})
+}
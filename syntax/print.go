@@ -0,0 +1,649 @@
+/*
+ * Printing expressions and types, either with the Unicode
+ * syntax (String()), or with its ASCII spelling (see
+ * asciiTokens), for those who can't comfortably type, or
+ * display, the former.
+ *
+ * Both can be mixed freely when parsing.
+ *
+ * Printing is canonical: parentheses are only added where the
+ * parser's precedences (opPrecs, application, parser.Type())
+ * require them, so that parsing a printed expression gives back
+ * an α-equivalent one. For readability though, binary operations
+ * are parenthesized as arguments and operands, even if the parser
+ * has them bind tighter (f x + 1 is f (x + 1), -x + 1 is -(x + 1)).
+ *
+ * Given a width, an expression which doesn't fit is broken on
+ * several lines: at abstractions' bodies, applications' arguments,
+ * products' components, let/if/match's sub-expressions, ascribed
+ * expressions. Others (e.g. binary operations) are kept on a
+ * single line.
+ *
+ * Source files are formatted (golc fmt) along with their
+ * comments (see printer.program()).
+ */
+package syntax
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type Style int
+
+const (
+	UnicodeStyle Style = iota // λ, 〈 〉, ×, →, ≤, ...
+	ASCIIStyle                // \, << >>, &, ->, <=, ...
+)
+
+// ASCII spelling of the tokens whose usual spelling (their
+// String()) isn't ASCII; keywords (e.g. lambda) are alternatives
+// to some of them.
+var asciiTokens = map[TokenKind]string{
+	TokenLambda:    `\`,
+	TokenLBracket:  "<<",
+	TokenRBracket:  ">>",
+	TokenMoreEq:    ">=",
+	TokenFMoreEq:   ">=.",
+	TokenLessEq:    "<=",
+	TokenFLessEq:   "<=.",
+	TokenPi:        "pi",
+	TokenArrow:     "->",
+	TokenProduct:   "&",
+	TokenOPlus:     `\/`,
+	TokenRMultiMap: "-*",
+	TokenOMult:     "**",
+	TokenBigLambda: `/\`,
+	TokenForall:    "forall",
+}
+
+// k's spelling in the given style
+func (k TokenKind) spell(s Style) string {
+	if a, ok := asciiTokens[k]; ok && s == ASCIIStyle {
+		return a
+	}
+	return k.String()
+}
+
+type printer struct {
+	style Style
+	width int // maximum line width; 0 for none
+}
+
+// Where a sub-expression is printed (see parens())
+type position int
+
+const (
+	posTop     position = iota // wherever an application is parsed
+	posFun                     // function of an application
+	posArg                     // argument of an application
+	posOperand                 // operand of a unary operator, injection
+	posLeft                    // left operand of a binary operator
+	posRight                   // right operand of a binary operator
+)
+
+// Does x need parentheses at pos? prec is the precedence of
+// the binary operator x is an operand of, if any.
+func parens(x Expr, pos position, prec int) bool {
+	switch x.(type) {
+	// they extend as far right as possible
+	case *AbsExpr, *DeBruijnAbsExpr, *TypeAbsExpr, *LetExpr, *LetProductExpr, *LetUnitExpr, *IfExpr, *MatchExpr:
+		return pos != posTop
+
+	case *AppExpr, *TypeAppExpr:
+		return pos != posTop && pos != posFun
+
+	// binary operators are left associative
+	case *BinaryExpr:
+		q := opPrecs[x.(*BinaryExpr).Op]
+		switch pos {
+		case posTop:
+			return false
+		case posLeft:
+			return q < prec
+		case posRight:
+			return q <= prec
+		}
+		// f (x + 1), -(x + 1): f x + 1 would be parsed
+		// as the former, but that's confusing.
+		return true
+
+	case *UnaryExpr, *InjExpr:
+		return pos != posTop && pos != posOperand
+
+	// printed as a negation
+	case *IntExpr:
+		return x.(*IntExpr).Value < 0 && pos != posTop && pos != posOperand
+	case *FloatExpr:
+		return math.Signbit(x.(*FloatExpr).Value) && pos != posTop && pos != posOperand
+	}
+
+	// variables, literals, products, ascriptions
+	return false
+}
+
+func (p printer) expr(x Expr) string {
+	return p.node(x, 0, 0)
+}
+
+// x at pos (see parens()), starting on column col
+func (p printer) at(x Expr, pos position, prec, col, ind int) string {
+	if parens(x, pos, prec) {
+		return "(" + p.node(x, col+1, col+1) + ")"
+	}
+	return p.node(x, col, ind)
+}
+
+// x, starting on column col; should it not fit in the width,
+// its subsequent lines are indented relatively to ind.
+func (p printer) node(x Expr, col, ind int) string {
+	if p.width > 0 {
+		s := printer{p.style, 0}.node(x, col, ind)
+		if col+utf8.RuneCountInString(s) <= p.width {
+			return s
+		}
+		if t, ok := p.broken(x, col, ind); ok {
+			return t
+		}
+		return s
+	}
+
+	switch x.(type) {
+	case *AbsExpr, *DeBruijnAbsExpr, *TypeAbsExpr:
+		h, y := p.binders(x)
+		return h + " " + p.at(y, posTop, 0, 0, 0)
+
+	case *AppExpr:
+		f, xs := spine(x)
+		s := p.at(f, posFun, 0, 0, 0)
+		for _, y := range xs {
+			s += " " + p.at(y, posArg, 0, 0, 0)
+		}
+		return s
+
+	case *UnaryExpr:
+		return x.(*UnaryExpr).Op.spell(p.style) +
+			p.at(x.(*UnaryExpr).Right, posOperand, 0, 0, 0)
+
+	case *BinaryExpr:
+		q := opPrecs[x.(*BinaryExpr).Op]
+		return p.at(x.(*BinaryExpr).Left, posLeft, q, 0, 0) + " " +
+			x.(*BinaryExpr).Op.spell(p.style) + " " +
+			p.at(x.(*BinaryExpr).Right, posRight, q, 0, 0)
+
+	case *ProductExpr:
+		var ss []string
+		for _, y := range components(x.(*ProductExpr)) {
+			ss = append(ss, p.at(y, posTop, 0, 0, 0))
+		}
+		return TokenLBracket.spell(p.style) + strings.Join(ss, ", ") +
+			TokenRBracket.spell(p.style)
+
+	case *LetExpr, *LetProductExpr, *LetUnitExpr:
+		h, m, t, y := p.let(x)
+		return h + p.bound(m, t, 0, 0) + " in " + p.at(y, posTop, 0, 0, 0)
+
+	case *IfExpr:
+		return "if " + p.at(x.(*IfExpr).Cond, posTop, 0, 0, 0) +
+			" then " + p.at(x.(*IfExpr).Left, posTop, 0, 0, 0) +
+			" else " + p.at(x.(*IfExpr).Right, posTop, 0, 0, 0)
+
+	case *InjExpr:
+		return p.inj(x.(*InjExpr)) + " " + p.at(x.(*InjExpr).Right, posOperand, 0, 0, 0)
+
+	case *AnnotExpr:
+		return "(" + p.at(x.(*AnnotExpr).X, posTop, 0, 0, 0) + " : " + p.typ(x.(*AnnotExpr).Typ) + ")"
+
+	case *TypeAppExpr:
+		return p.at(x.(*TypeAppExpr).Left, posFun, 0, 0, 0) + " " +
+			TokenLSquare.spell(p.style) + p.typ(x.(*TypeAppExpr).Typ) +
+			TokenRSquare.spell(p.style)
+
+	case *MatchExpr:
+		s := "match " + p.at(x.(*MatchExpr).X, posTop, 0, 0, 0) + " with"
+		for i, b := range p.branches(x.(*MatchExpr)) {
+			if i > 0 {
+				s += " |"
+			}
+			s += " " + b.head + p.at(b.body, posTop, 0, 0, 0)
+		}
+		return s
+
+	case *IntExpr:
+		return strconv.FormatInt(x.(*IntExpr).Value, 10)
+
+	case *FloatExpr:
+		return formatFloat(x.(*FloatExpr).Value)
+
+	case nil:
+		return fmt.Sprintf("%s", x)
+	}
+
+	// other literals, variables
+	return x.String()
+}
+
+// x laid out on several lines, if it can be
+func (p printer) broken(x Expr, col, ind int) (string, bool) {
+	nl := func(n int) string {
+		return "\n" + strings.Repeat(" ", n)
+	}
+
+	switch x.(type) {
+	case *AbsExpr, *DeBruijnAbsExpr, *TypeAbsExpr:
+		h, y := p.binders(x)
+		return h + nl(ind+2) + p.at(y, posTop, 0, ind+2, ind+2), true
+
+	// one argument per line
+	case *AppExpr:
+		f, xs := spine(x)
+		s := p.at(f, posFun, 0, col, ind)
+		for _, y := range xs {
+			s += nl(ind+2) + p.at(y, posArg, 0, ind+2, ind+2)
+		}
+		return s, true
+
+	case *ProductExpr:
+		o := TokenLBracket.spell(p.style)
+		c := col + utf8.RuneCountInString(o)
+		var ss []string
+		for _, y := range components(x.(*ProductExpr)) {
+			ss = append(ss, p.at(y, posTop, 0, c, c))
+		}
+		return o + strings.Join(ss, ","+nl(c)) + TokenRBracket.spell(p.style), true
+
+	case *LetExpr, *LetProductExpr, *LetUnitExpr:
+		h, m, t, y := p.let(x)
+		return h + p.bound(m, t, col+utf8.RuneCountInString(h), ind+2) +
+			" in" + nl(ind) + p.at(y, posTop, 0, ind, ind), true
+
+	// else if chains are kept flat
+	case *IfExpr:
+		s := "if " + p.at(x.(*IfExpr).Cond, posTop, 0, col+3, ind+2) + " then" +
+			nl(ind+2) + p.at(x.(*IfExpr).Left, posTop, 0, ind+2, ind+2) +
+			nl(ind) + "else"
+		if _, ok := x.(*IfExpr).Right.(*IfExpr); ok {
+			return s + " " + p.at(x.(*IfExpr).Right, posTop, 0, ind+5, ind), true
+		}
+		return s + nl(ind+2) + p.at(x.(*IfExpr).Right, posTop, 0, ind+2, ind+2), true
+
+	case *MatchExpr:
+		s := "match " + p.at(x.(*MatchExpr).X, posTop, 0, col+6, ind+2) + " with"
+		for _, b := range p.branches(x.(*MatchExpr)) {
+			h := "| " + b.head
+			s += nl(ind) + h + p.at(b.body, posTop, 0,
+				ind+utf8.RuneCountInString(h), ind+2)
+		}
+		return s, true
+
+	case *AnnotExpr:
+		return "(" + p.bound(x.(*AnnotExpr).X, x.(*AnnotExpr).Typ, col+1, ind+1) + ")", true
+	}
+
+	// binary expressions, etc. are kept on a single line
+	return "", false
+}
+
+// λ's (and Λ's) at the head of x, e.g. "λx:int. λy.", and
+// their body
+func (p printer) binders(x Expr) (string, Expr) {
+	var hs []string
+
+	for {
+		switch x.(type) {
+		case *AbsExpr:
+			hs = append(hs, p.binder(x.(*AbsExpr).Name, x.(*AbsExpr).Typ))
+			x = x.(*AbsExpr).Right
+			continue
+		case *DeBruijnAbsExpr:
+			hs = append(hs, p.binder("", x.(*DeBruijnAbsExpr).Typ))
+			x = x.(*DeBruijnAbsExpr).Right
+			continue
+		case *TypeAbsExpr:
+			hs = append(hs, TokenBigLambda.spell(p.style)+x.(*TypeAbsExpr).Name+".")
+			x = x.(*TypeAbsExpr).Right
+			continue
+		}
+		return strings.Join(hs, " "), x
+	}
+}
+
+// λn:t., or λn. if t is unknown
+func (p printer) binder(n string, t Type) string {
+	s := TokenLambda.spell(p.style) + n
+	if Known(t) {
+		s += ":" + p.typ(t)
+	}
+	return s + "."
+}
+
+// true if t isn't a placeholder for a yet unknown type
+func Known(t Type) bool {
+	switch t.(type) {
+	case *UnknownType, nil:
+		return false
+	}
+	return true
+}
+
+// let's head (up to the =), bound expression, its type
+// annotation (nil if none) and body
+func (p printer) let(x Expr) (string, Expr, Type, Expr) {
+	switch x.(type) {
+	case *LetExpr:
+		n, t, y := letBinder(x.(*LetExpr))
+		return "let " + n + " = ", x.(*LetExpr).Left, t, y
+	case *LetUnitExpr:
+		return "let * = ", x.(*LetUnitExpr).Left, nil, x.(*LetUnitExpr).Right
+	}
+	ns, y := letNames(x.(*LetProductExpr))
+	return "let " + TokenLBracket.spell(p.style) + strings.Join(ns, ", ") +
+		TokenRBracket.spell(p.style) + " = ", x.(*LetProductExpr).Left, nil, y
+}
+
+// x, bound by a let (or a declaration), with its type
+// annotation t, if known.
+func (p printer) bound(x Expr, t Type, col, ind int) string {
+	if !Known(t) {
+		return p.at(x, posTop, 0, col, ind)
+	}
+
+	s := p.at(x, posTop, 0, col, ind)
+	if endsInVar(x) {
+		s = "(" + p.node(x, col+1, ind+1) + ")"
+	}
+	return s + " : " + p.typ(t)
+}
+
+func (p printer) inj(x *InjExpr) string {
+	if x.Inr {
+		return TokenInr.String()
+	}
+	return TokenInl.String()
+}
+
+// match's branch: "inl x → " and its body
+type branch struct {
+	head string
+	body Expr
+}
+
+func (p printer) branches(x *MatchExpr) []branch {
+	var bs []branch
+	for i, b := range []Expr{x.Left, x.Right} {
+		k := TokenInl
+		if i > 0 {
+			k = TokenInr
+		}
+		n, y := branchName(b)
+		bs = append(bs, branch{k.String() + " " + n + " " + TokenArrow.spell(p.style) + " ", y})
+	}
+	return bs
+}
+
+// f x1 ... xn as f and x1 ... xn
+func spine(x Expr) (Expr, []Expr) {
+	var xs []Expr
+	for {
+		y, ok := x.(*AppExpr)
+		if !ok {
+			break
+		}
+		xs = append([]Expr{y.Right}, xs...)
+		x = y.Left
+	}
+	return x, xs
+}
+
+// 〈x1, 〈x2, x3〉〉 as x1, x2, x3
+func components(x *ProductExpr) []Expr {
+	var xs []Expr
+	for {
+		xs = append(xs, x.Left)
+		y, ok := x.Right.(*ProductExpr)
+		if !ok {
+			return append(xs, x.Right)
+		}
+		x = y
+	}
+}
+
+// Shortest representation parsed back as v, always with a
+// dot or an exponent.
+func formatFloat(v float64) string {
+	if a := math.Abs(v); a != 0 && (a < 1e-6 || a >= 1e21) {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	if !strings.ContainsAny(s, ".NI") {
+		s += ".0"
+	}
+	return s
+}
+
+// Type levels, from the loosest: an arrow's left-hand side must
+// be at least a sum, a sum's a product, a product's an atom.
+// Linear arrows and tensors are at their unrestricted counterparts'
+// levels; ! is a prefix on atoms. As abstractions, ∀ extends as
+// far right as possible: it's parenthesized unless at the top.
+func typeLevel(t Type) int {
+	switch t.(type) {
+	case *ForallType:
+		return -1
+	case *ArrowType, *LinArrowType:
+		return 0
+	case *SumType:
+		return 1
+	case *ProductType, *TensorType:
+		return 2
+	}
+	return 3
+}
+
+// t, within a type of level l; yet unknown types are
+// printed as ?
+func (p printer) typAt(t Type, l int) string {
+	if !Known(t) {
+		return "?"
+	}
+	if typeLevel(t) < l {
+		return "(" + p.typ(t) + ")"
+	}
+	return p.typ(t)
+}
+
+func (p printer) typ(t Type) string {
+	switch t.(type) {
+	case *ArrowType:
+		return p.typAt(t.(*ArrowType).Left, 1) + " " + TokenArrow.spell(p.style) +
+			" " + p.typAt(t.(*ArrowType).Right, 0)
+
+	case *SumType:
+		return p.typAt(t.(*SumType).Left, 2) + " " + TokenOPlus.spell(p.style) +
+			" " + p.typAt(t.(*SumType).Right, 1)
+
+	case *ProductType:
+		return p.typAt(t.(*ProductType).Left, 3) + " " + TokenProduct.spell(p.style) +
+			" " + p.typAt(t.(*ProductType).Right, 2)
+
+	case *LinArrowType:
+		return p.typAt(t.(*LinArrowType).Left, 1) + " " + TokenRMultiMap.spell(p.style) +
+			" " + p.typAt(t.(*LinArrowType).Right, 0)
+
+	case *TensorType:
+		return p.typAt(t.(*TensorType).Left, 3) + " " + TokenOMult.spell(p.style) +
+			" " + p.typAt(t.(*TensorType).Right, 2)
+
+	case *BangType:
+		return TokenExcl.spell(p.style) + p.typAt(t.(*BangType).T, 3)
+
+	// forall a. in ASCII
+	case *ForallType:
+		s := TokenForall.spell(p.style)
+		if p.style == ASCIIStyle {
+			s += " "
+		}
+		return s + t.(*ForallType).Name + ". " + p.typ(t.(*ForallType).T)
+
+	case nil:
+		return fmt.Sprintf("%s", t)
+	}
+
+	// primitive types, type variables
+	return t.String()
+}
+
+// true if x's last token is a variable heading an application,
+// which would then be parsed as a short form λ if followed by a
+// type annotation (e.g. let f = λy. y : int → int).
+func endsInVar(x Expr) bool {
+	switch x.(type) {
+	case *VarExpr:
+		return true
+	case *AbsExpr:
+		return endsInVar(x.(*AbsExpr).Right)
+	case *TypeAbsExpr:
+		return endsInVar(x.(*TypeAbsExpr).Right)
+	case *LetExpr, *LetProductExpr, *LetUnitExpr:
+		_, _, _, y := printer{}.let(x)
+		return endsInVar(y)
+	case *IfExpr:
+		return endsInVar(x.(*IfExpr).Right)
+	case *MatchExpr:
+		_, y := branchName(x.(*MatchExpr).Right)
+		return endsInVar(y)
+	}
+	return false
+}
+
+// let n = $X [: $T];, n being d's name, eventually padded
+func (p printer) decl(d Decl, n string) string {
+	h := "let " + n + " = "
+	return h + p.bound(d.X, d.T, utf8.RuneCountInString(h), 0) + ";"
+}
+
+// Something to print, located in the source
+type item struct {
+	span *Span
+	text string
+}
+
+// q, whose source contains the comments cs, formatted: comments
+// following a declaration on its last line are kept there, the
+// others get a line of their own, before the first declaration
+// they precede (or are within). Blank lines between declarations
+// are kept (though not repeated), and the = of consecutive
+// single-line declarations aligned.
+func (p printer) program(q *Program, cs []comment) string {
+	var xs []item
+
+	for _, d := range q.Decls {
+		xs = append(xs, item{d.X.Span(), p.decl(d, d.Name)})
+	}
+	p.align(q, xs, cs)
+
+	if q.Main != nil {
+		xs = append(xs, item{q.Main.Span(), p.node(q.Main, 0, 0)})
+	}
+
+	var b strings.Builder
+	last := uint(0)
+
+	// s, starting on line ln
+	add := func(ln uint, s string) {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+			if ln > last+1 {
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString(s)
+	}
+
+	// does c follow x, on its last line?
+	after := func(c comment, x *item) bool {
+		return x != nil && c.ln == x.span.End.Ln && c.cn >= x.span.End.Cn
+	}
+
+	var prev *item
+	for i := 0; i <= len(xs); i++ {
+		for len(cs) > 0 {
+			c := cs[0]
+			if i < len(xs) && (c.ln > xs[i].span.End.Ln || after(c, &xs[i])) {
+				break
+			}
+			if after(c, prev) {
+				b.WriteString(" " + c.text)
+			} else {
+				add(c.ln, c.text)
+				prev = nil
+			}
+			last, cs = c.eln, cs[1:]
+		}
+		if i < len(xs) {
+			add(xs[i].span.Start.Ln, xs[i].text)
+			last, prev = xs[i].span.End.Ln, &xs[i]
+		}
+	}
+
+	if b.Len() == 0 {
+		return ""
+	}
+	return b.String() + "\n"
+}
+
+// Align the = of runs of consecutive single-line declarations
+// xs of q, not separated by comments cs, e.g.
+//
+//	let one  = λf. λx. f x;
+//	let succ = λn. λf. λx. f (n f x);
+func (p printer) align(q *Program, xs []item, cs []comment) {
+	// is there a comment between x and y?
+	between := func(x, y item) bool {
+		for _, c := range cs {
+			if c.ln > x.span.End.Ln && c.ln < y.span.Start.Ln ||
+				c.ln == y.span.Start.Ln && c.cn < y.span.Start.Cn {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := 0; i < len(xs); {
+		j := i + 1
+		for j < len(xs) && !strings.Contains(xs[j-1].text, "\n") &&
+			!strings.Contains(xs[j].text, "\n") &&
+			xs[j].span.Start.Ln == xs[j-1].span.End.Ln+1 &&
+			!between(xs[j-1], xs[j]) {
+			j++
+		}
+
+		if j-i > 1 {
+			w := 0
+			for k := i; k < j; k++ {
+				w = max(w, utf8.RuneCountInString(q.Decls[k].Name))
+			}
+			for k := i; k < j; k++ {
+				n := q.Decls[k].Name
+				n += strings.Repeat(" ", w-utf8.RuneCountInString(n))
+				xs[k].text = p.decl(q.Decls[k], n)
+			}
+		}
+		i = j
+	}
+}
+
+// Parse and format the program src (see printer.program())
+func FormatSource(src, fn string, s Style, width int) (string, error) {
+	var p parser
+	var q *Program
+
+	p.init(src, fn)
+	if err := p.run(func() { q = p.program() }); err != nil {
+		return "", err
+	}
+	return printer{s, width}.program(q, p.comments), nil
+}
@@ -0,0 +1,367 @@
+package syntax_test
+
+import (
+	"fmt"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mbivert/ftests"
+	"github.com/mbivert/golc/lib"
+
+	"github.com/mbivert/golc/internal/testutil"
+	. "github.com/mbivert/golc/syntax"
+)
+
+// Tokens whose spelling isn't ASCII, and have no ASCII one
+func missingASCII() []string {
+	var xs []string
+	for k := TokenEOF; k <= TokenMeas; k++ {
+		s := k.Spell(ASCIIStyle)
+		for _, c := range s {
+			if c >= utf8.RuneSelf {
+				xs = append(xs, s)
+				break
+			}
+		}
+	}
+	return xs
+}
+
+// Kinds of the tokens of the ASCII spelling of the tokens
+// having one
+func scanASCII() bool {
+	for k, s := range ASCIITokens {
+		toks, err := Scan(s, "")
+		if err != nil || len(toks) != 2 || toks[0].Kind != k {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse src, print it in both styles, parse those back
+func formatBoth(src string) (string, string, bool) {
+	x := testutil.MustParse(src)
+	a, u := Format(x, ASCIIStyle), Format(x, UnicodeStyle)
+	return a, u, AlphaEqual(testutil.MustParse(a), testutil.MustParse(u))
+}
+
+func TestPrintASCII(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"all tokens can be spelled in ASCII",
+			missingASCII,
+			[]any{},
+			[]any{[]string(nil)},
+		},
+		{
+			"ASCII spellings are scanned as such",
+			scanASCII,
+			[]any{},
+			[]any{true},
+		},
+		{
+			"lambda, products, comparisons",
+			formatBoth,
+			[]any{"λx:int × (float → bool). 〈x, 1 ≤ 2, 1. ≥. 2.〉"},
+			[]any{
+				"\\x:int & (float -> bool). <<x, 1 <= 2, 1.0 >=. 2.0>>",
+				"λx:int × (float → bool). 〈x, 1 ≤ 2, 1.0 ≥. 2.0〉",
+				true,
+			},
+		},
+		{
+			"ASCII and Unicode can be mixed",
+			formatBoth,
+			[]any{"\\x:int -> int. λy:int → int. <<x, y〉"},
+			[]any{
+				"\\x:int -> int. \\y:int -> int. <<x, y>>",
+				"λx:int → int. λy:int → int. 〈x, y〉",
+				true,
+			},
+		},
+		{
+			"sums",
+			formatBoth,
+			[]any{"λs:int ⊕ (bool → bool). match s with inl n → inl (λx:int. x) | inr f → inr (f true)"},
+			[]any{
+				"\\s:int \\/ (bool -> bool). match s with inl n -> inl (\\x:int. x) | inr f -> inr (f true)",
+				"λs:int ⊕ (bool → bool). match s with inl n → inl (λx:int. x) | inr f → inr (f true)",
+				true,
+			},
+		},
+		{
+			"linear types",
+			formatBoth,
+			[]any{"λf:!(int ⊸ int) ⊸ int ⊗ !int. f"},
+			[]any{
+				"\\f:!(int -* int) -* int ** !int. f",
+				"λf:!(int ⊸ int) ⊸ int ⊗ !int. f",
+				true,
+			},
+		},
+		{
+			"System F",
+			formatBoth,
+			[]any{"Λa. λx:∀b. b → a. x [a] /\\c. λy:forall d. d. y"},
+			[]any{
+				"/\\a. \\x:forall b. b -> a. x [a] (/\\c. \\y:forall d. d. y)",
+				"Λa. λx:∀b. b → a. x [a] (Λc. λy:∀d. d. y)",
+				true,
+			},
+		},
+		{
+			"types",
+			func(t Type) (string, string) {
+				return FormatType(t, ASCIIStyle), FormatType(t, UnicodeStyle)
+			},
+			[]any{&ArrowType{&ProductType{&IntType{}, &ArrowType{&UnitType{}, &BoolType{}}}, &FloatType{}}},
+			[]any{"int & (unit -> bool) -> float", "int × (unit → bool) → float"},
+		},
+	})
+}
+
+func TestPrintCanonical(t *testing.T) {
+	str := func(x Expr) string { return x.String() }
+
+	ftests.Run(t, []ftests.Test{
+		{
+			"bare int",
+			str,
+			[]any{testutil.MustParse("123")},
+			[]any{"123"},
+		},
+		{
+			"bare float",
+			str,
+			[]any{testutil.MustParse("123.42")},
+			[]any{"123.42"},
+		},
+		{
+			"floats keep a dot",
+			str,
+			[]any{testutil.MustParse("〈3., 1e3, 1e-9〉")},
+			[]any{"〈3.0, 1000.0, 1e-09〉"},
+		},
+		{
+			"bare bool",
+			str,
+			[]any{testutil.MustParse("true")},
+			[]any{"true"},
+		},
+		{
+			"bare variable",
+			str,
+			[]any{testutil.MustParse("someVar")},
+			[]any{"someVar"},
+		},
+		{
+			"simple abstraction (id)",
+			str,
+			[]any{testutil.MustParse("λ x. x")},
+			[]any{"λx. x"},
+		},
+		{
+			"arithmetic",
+			str,
+			[]any{testutil.MustParse("(2+2)*3")},
+			[]any{"(2 + 2) * 3"},
+		},
+		{
+			"left associativity",
+			str,
+			[]any{testutil.MustParse("(1 - 2) - (3 - 4)")},
+			[]any{"1 - 2 - (3 - 4)"},
+		},
+		{
+			"imbricated abstraction + application",
+			str,
+			[]any{testutil.MustParse("λx. y. x y")},
+			[]any{"λx. λy. x y"},
+		},
+		{
+			"and",
+			str,
+			[]any{testutil.MustParseChurch("land")},
+			[]any{"λx. λy. x y (λx. λy. y)"},
+		},
+		{
+			"(((x y) q) (z p))",
+			str,
+			[]any{testutil.MustParse("(((x y) q) (z p))")},
+			[]any{"x y q (z p)"},
+		},
+		{
+			"operators' operands, arguments",
+			str,
+			[]any{testutil.MustParse("f (x + 1) (-(y * 2)) (-3)")},
+			[]any{"f (x + 1) (-(y * 2)) (-3)"},
+		},
+		{
+			"binders are parenthesized but at the end",
+			str,
+			[]any{testutil.MustParse("(λx. x) (if b then 1 else 2) (let * = * in 3)")},
+			[]any{"(λx. x) (if b then 1 else 2) (let * = * in 3)"},
+		},
+		{
+			"ascriptions",
+			str,
+			[]any{testutil.MustParse("((x) : int) + (f 1 : int)")},
+			[]any{"(x : int) + (f 1 : int)"},
+		},
+		{
+			"nested products are flattened",
+			str,
+			[]any{testutil.MustParse("〈1, 〈2, 3〉〉")},
+			[]any{"〈1, 2, 3〉"},
+		},
+		{
+			"injections, match",
+			str,
+			[]any{testutil.MustParse("match inl (f x) with inr y → y | inl z → inr z")},
+			[]any{"match inl (f x) with inl z → inr z | inr y → y"},
+		},
+		{
+			"types",
+			func(t Type) string { return t.String() },
+			[]any{&ArrowType{
+				&ArrowType{&IntType{}, &IntType{}},
+				&ProductType{
+					&ProductType{&IntType{}, &IntType{}},
+					&SumType{&IntType{}, &UnknownType{}}}}},
+			[]any{"(int → int) → (int × int) × (int ⊕ ?)"},
+		},
+		{
+			"linear types",
+			func(t Type) string { return t.String() },
+			[]any{&LinArrowType{
+				&BangType{&LinArrowType{&IntType{}, &IntType{}}},
+				&TensorType{
+					&TensorType{&IntType{}, &BangType{&IntType{}}},
+					&IntType{}}}},
+			[]any{"!(int ⊸ int) ⊸ (int ⊗ !int) ⊗ int"},
+		},
+	})
+}
+
+// Print x, with the given width, in both styles, and parse it
+// back.
+func roundTrip(src string, width int) bool {
+	x := testutil.MustParse(src)
+	for _, s := range []Style{UnicodeStyle, ASCIIStyle} {
+		if !AlphaEqual(x, testutil.MustParse(FormatWidth(x, s, width))) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPrintRoundTrip(t *testing.T) {
+	var ts []ftests.Test
+
+	for _, src := range []string{
+		"λx:(int → int) → int. x (λy:int. y)",
+		"λp:(int × int) × bool. p",
+		"λs:(int ⊕ bool) → unit × int ⊕ unit. s",
+		"λp:!(int ⊸ int) ⊗ int. let 〈f, x〉 = p in f x",
+		"1 - (2 - 3) * 4 / (5 / 6) + -7",
+		"f (g x) (-x) (inl x) 〈x, y〉",
+		"-(1 + 2) < - 3",
+		"!(!b)",
+		"inl (inr 〈1, 2〉)",
+		"let 〈a, b〉 = 〈1, 2〉 in let * = * in a + b",
+		"let f = λx:int. x + 1 in f (f 1)",
+		"let x = (f y) : int in x",
+		"(f x : int) + 1",
+		"((x) : int) + 1",
+		"(λf. f 1 : (int → int) → int) (λx. x)",
+		"let y = ((λx. x) : bool → bool) in y",
+		"(let x = 1 in x) + 1",
+		"if if a then b else c then λx. x else (λx. x) 1",
+		"match m with inl a → match a with inl b → b | inr c → c | inr d → d",
+		"(match m with inl a → a | inr b → b) 1",
+		"λf. λx. f (f (f (f (f (f (f (f x)))))))",
+		"λx:(∀a. a → a) → ∀b. b. x [int] (Λc. x)",
+		"(Λa. λx:a. x) [∀a. a → a] (f [int → int])",
+	} {
+		for _, w := range []int{0, 10} {
+			ts = append(ts, ftests.Test{
+				fmt.Sprintf("%s, width %d", src, w),
+				roundTrip,
+				[]any{src, w},
+				[]any{true},
+			})
+		}
+	}
+
+	ts = append(ts, ftests.Test{
+		"Church encodings",
+		func() bool {
+			for _, d := range testutil.Church.Decls {
+				if !AlphaEqual(d.X, testutil.MustParse(d.X.String())) {
+					return false
+				}
+			}
+			return true
+		},
+		[]any{},
+		[]any{true},
+	})
+
+	ftests.Run(t, ts)
+}
+
+func TestPrintWidth(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"fits",
+			FormatWidth,
+			[]any{testutil.MustParse("λx. f x y"), UnicodeStyle, 9},
+			[]any{"λx. f x y"},
+		},
+		{
+			"abstraction's body, arguments",
+			FormatWidth,
+			[]any{testutil.MustParse("λx. f (g x) (h x)"), UnicodeStyle, 10},
+			[]any{"λx.\n  f\n    (g x)\n    (h x)"},
+		},
+		{
+			"if/else if, match",
+			FormatWidth,
+			[]any{testutil.MustParse("if a then match x with inl y → y | inr z → z else if b then 1 else 2"), UnicodeStyle, 20},
+			[]any{"if a then\n  match x with\n  | inl y → y\n  | inr z → z\nelse if b then\n  1\nelse\n  2"},
+		},
+		{
+			"let, products",
+			FormatWidth,
+			[]any{testutil.MustParse("let 〈a, b〉 = 〈1 + 2, 3 + 4〉 in a"), UnicodeStyle, 20},
+			[]any{"let 〈a, b〉 = 〈1 + 2,\n              3 + 4〉 in\na"},
+		},
+	})
+}
+
+func TestPrintSource(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"comments, blank lines, alignment",
+			FormatSource,
+			[]any{"# header\n\nlet one = 1;  # one\nlet three = 1+(1 + 1);\n\n\n/* a\n   b */\nlet f = (λx:int. x) : int → int;\nf three", "", UnicodeStyle, 0},
+			[]any{"# header\n\nlet one   = 1; # one\nlet three = 1 + (1 + 1);\n\n/* a\n   b */\nlet f = (λx:int. x) : int → int;\nf three\n", nil},
+		},
+		{
+			"comments within a declaration",
+			FormatSource,
+			[]any{"let f = λx.\n  # identity\n  x;", "", UnicodeStyle, 0},
+			[]any{"# identity\nlet f = λx. x;\n", nil},
+		},
+		{
+			"idempotent on lib/",
+			func() bool {
+				s, err := FormatSource(lib.Church, "", UnicodeStyle, 80)
+				return err == nil && s == lib.Church
+			},
+			[]any{},
+			[]any{true},
+		},
+	})
+}
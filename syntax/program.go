@@ -0,0 +1,129 @@
+/*
+ * Programs (source files): a sequence of top-level declarations,
+ * each terminated by a semicolon, optionally followed by an
+ * expression, e.g.
+ *
+ *	# Church booleans
+ *	let T = λx. λy. x;
+ *	let F = λx. λy. y;
+ *
+ *	T F T # F
+ *
+ * A declaration may refer to earlier ones, and may be annotated
+ * with a type (let $x = $M : $T;). Comments run from # to the
+ * end of the line, or are C-style block comments (which don't
+ * nest).
+ *
+ * Declarations are then bound to the expression with let/in,
+ * only keeping the ones it (transitively) needs.
+ */
+package syntax
+
+// A top-level declaration, let $Name = $X [: $T];
+// T is nil if unknown.
+type Decl struct {
+	Name string
+	X    Expr
+	T    Type
+}
+
+// Main is nil if there's nothing to evaluate (e.g. libraries)
+type Program struct {
+	Decls []Decl
+	Main  Expr
+}
+
+func (p *parser) program() *Program {
+	var q Program
+
+	p.decls = true
+	for p.has(TokenLet) {
+		var ns []string
+		var x Expr
+		var t Type
+
+		s := p.pos()
+
+		// on error, skip to the next declaration
+		ok := p.sync(func() {
+			ns, x, t = p.letDecl()
+			if len(ns) != 1 && !p.has(TokenIn) {
+				p.errf("Expecting 'in' after a let 〈...〉 or let *, got %s", p.tok.Kind)
+			}
+			if !p.has(TokenIn) && !p.has(TokenSemicolon) {
+				p.errf("Expecting ';' after let $x = $M, got %s", p.tok.Kind)
+			}
+		}, TokenSemicolon, TokenIn)
+
+		// let/in: we're parsing the final expression
+		if p.has(TokenIn) {
+			p.decls = false
+			q.Main = p.letBody(s, ns, x, t)
+			break
+		}
+
+		// a let, after an error: next declaration
+		if !p.has(TokenLet) {
+			p.next()
+		}
+
+		if _, isTyp := t.(*UnknownType); isTyp {
+			t = nil
+		}
+		if ok {
+			q.Decls = append(q.Decls, Decl{ns[0], x, t})
+		}
+	}
+
+	p.decls = false
+
+	if q.Main == nil && !p.has(TokenEOF) {
+		q.Main = p.topExpr()
+	}
+
+	// remaining input is unexpected
+	p.eof()
+
+	return &q
+}
+
+// q is nil on error
+func parseProgram(src string, fn string) (q *Program, err error) {
+	var p parser
+	p.init(src, fn)
+
+	if err = p.run(func() { q = p.program() }); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Wrap x in the declarations it (transitively) uses, i.e.
+//
+//	let x0 = M0 in let x1 = M1 in ... x
+//
+// The declarations are copied, x isn't.
+func (q *Program) Bind(x Expr) Expr {
+	fv := FreeVars(x)
+
+	for i := len(q.Decls) - 1; i >= 0; i-- {
+		d := q.Decls[i]
+		if !fv[d.Name] {
+			continue
+		}
+
+		// d.X refers to previous declarations only
+		delete(fv, d.Name)
+		for n := range FreeVars(d.X) {
+			fv[n] = true
+		}
+
+		t := Type(&UnknownType{})
+		if d.T != nil {
+			t = CopyType(d.T)
+		}
+
+		x = &LetExpr{Node{}, Copy(d.X), &AbsExpr{Node{}, t, d.Name, x}}
+	}
+	return x
+}
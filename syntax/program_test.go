@@ -0,0 +1,211 @@
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/mbivert/ftests"
+	"github.com/mbivert/golc/lib"
+
+	"github.com/mbivert/golc/internal/testutil"
+	. "github.com/mbivert/golc/syntax"
+	"github.com/mbivert/golc/types"
+)
+
+// Parse and check src; errors are compared as strings
+func checkProgramStr(src string) (string, string) {
+	q, err := ParseProgram(src, "test.lc")
+	if err != nil {
+		return "", err.Error()
+	}
+	t, err := types.CheckProgramWith(q, types.ImplicitLinearity)
+	if err != nil {
+		return "", err.Error()
+	}
+	if t == nil {
+		return "", ""
+	}
+	return t.String(), ""
+}
+
+// Parse src, evaluate its main expression, bound to the
+// declarations
+func evalProgramStr(src string) string {
+	q := testutil.MustParseProgram(src, "test.lc")
+	return testutil.MustEval(q.Bind(q.Main)).String()
+}
+
+func TestProgramParse(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"declarations and expression",
+			testutil.MustParseProgram,
+			[]any{"# comment\nlet x = 1;\nlet f = λy:int. y + x : int → int;\n/* main */ f x", ""},
+			[]any{&Program{
+				[]Decl{
+					{"x", testutil.MustParse("1"), nil},
+					{"f", testutil.MustParse("λy:int. y + x"), &ArrowType{&IntType{}, &IntType{}}},
+				},
+				testutil.MustParse("f x"),
+			}},
+		},
+		{
+			"library: no expression",
+			testutil.MustParseProgram,
+			[]any{"let id = λx. x;", ""},
+			[]any{&Program{[]Decl{{"id", testutil.MustParse("λx. x"), nil}}, nil}},
+		},
+		{
+			"let/in as the expression",
+			testutil.MustParseProgram,
+			[]any{"let x = 1; let y = 2 in x + y", ""},
+			[]any{&Program{
+				[]Decl{{"x", testutil.MustParse("1"), nil}},
+				testutil.MustParse("let y = 2 in x + y"),
+			}},
+		},
+		{
+			"single expression",
+			testutil.MustParseProgram,
+			[]any{"(λx. x) y", ""},
+			[]any{&Program{nil, testutil.MustParse("(λx. x) y")}},
+		},
+		{
+			"missing semicolon",
+			checkProgramStr,
+			[]any{"let x = 1\nlet y = 2;"},
+			[]any{"", "test.lc:2:1: Expecting ';' after let $x = $M, got let"},
+		},
+		{
+			"missing semicolon, next declarations are parsed",
+			checkProgramStr,
+			[]any{"let x = 1\nlet y = 2;\nlet z = );"},
+			[]any{"", "test.lc:2:1: Expecting ';' after let $x = $M, got let\n" +
+				"test.lc:3:9: Unexpected token: )"},
+		},
+		{
+			"broken declarations after an unclosed parenthesis",
+			checkProgramStr,
+			[]any{"let a = (1 + ;\nlet b = 1 +;\nlet c = (λx. x) );\nlet d = 〈1, ;\nlet e = λ. e;\nd"},
+			[]any{"", "test.lc:1:14: Unexpected token: ;\n" +
+				"test.lc:2:12: Unexpected token: ;\n" +
+				"test.lc:3:17: Expecting ';' after let $x = $M, got )\n" +
+				"test.lc:4:13: Unexpected token: ;\n" +
+				"test.lc:5:10: Expecting variable name after lambda, got: ."},
+		},
+		{
+			"trailing input",
+			checkProgramStr,
+			[]any{"let x = 1; x; x"},
+			[]any{"", "test.lc:1:13: Unexpected token: ;"},
+		},
+		{
+			"unterminated comment",
+			checkProgramStr,
+			[]any{"let x = 1;\n /* x"},
+			[]any{"", "test.lc:2:2: Unterminated comment"},
+		},
+	})
+}
+
+func TestProgramCheck(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"declarations typed in order",
+			checkProgramStr,
+			[]any{"let x = 1; let inc = λy:int. y + x; inc (inc x)"},
+			[]any{"int", ""},
+		},
+		{
+			"matching annotation",
+			checkProgramStr,
+			[]any{"let inc = λy:int. y + 1 : int → int; inc"},
+			[]any{"int → int", ""},
+		},
+		{
+			"annotated declaration, whose body is a variable",
+			checkProgramStr,
+			[]any{"let id = λx:int. x : int → int; id"},
+			[]any{"int → int", ""},
+		},
+		{
+			"mismatching annotation",
+			checkProgramStr,
+			[]any{"let x = 1 : bool; x"},
+			[]any{"", "test.lc:1:9: Expecting 'bool', got 'int'\n\tnote: in declaration 'x'"},
+		},
+		{
+			"annotation completing an injection's type",
+			checkProgramStr,
+			[]any{"let b = inr true : int ⊕ bool; b"},
+			[]any{"int ⊕ bool", ""},
+		},
+		{
+			"annotation pushed into an abstraction",
+			checkProgramStr,
+			[]any{"let g = (λf. f 1) : (int → int) → int; g (λx. x + 1)"},
+			[]any{"int", ""},
+		},
+		{
+			"declarations are duplicable, whatever their type",
+			checkProgramStr,
+			[]any{"let f = (λx. x + 1) : int ⊸ int; f (f 1)"},
+			[]any{"int", ""},
+		},
+		{
+			"linearity violations are located",
+			checkProgramStr,
+			[]any{"let dup = λp:int ⊗ int. 〈p, p〉; dup"},
+			[]any{"", "test.lc:1:29: 'p' is linear ('int ⊗ int'), but used more than once\n\tnote: 1:26: first used here\n\tnote: in declaration 'dup'"},
+		},
+		{
+			"declarations can't refer to later ones",
+			checkProgramStr,
+			[]any{"let x = y; let y = 1; x"},
+			[]any{"", "test.lc:1:9: 'y' isn't bounded!\n\tnote: in declaration 'x'"},
+		},
+		{
+			"untyped declaration",
+			checkProgramStr,
+			[]any{"let id = λx. x;"},
+			[]any{"", "test.lc:1:10: Can't infer the type of 'x'; annotate it or add an ascription\n\tnote: in declaration 'id'"},
+		},
+		{
+			"library: no expression",
+			checkProgramStr,
+			[]any{"let id = λx:int. x;"},
+			[]any{"", ""},
+		},
+		{
+			"ill-typed expression",
+			checkProgramStr,
+			[]any{"let x = true; x + 1"},
+			[]any{"", "test.lc:1:15: + : (int×int) → int; got (bool×int)"},
+		},
+	})
+}
+
+func TestProgramBind(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"only the needed declarations are bound",
+			func(src string) Expr {
+				q := testutil.MustParseProgram(src, "")
+				return q.Bind(q.Main)
+			},
+			[]any{"let x = 1; let y = 2; let z = x + 1; z"},
+			[]any{testutil.MustParse("let x = 1 in let z = x + 1 in z")},
+		},
+		{
+			"redefinitions refer to previous ones",
+			evalProgramStr,
+			[]any{"let x = 1; let x = x + 1; x"},
+			[]any{"2"},
+		},
+		{
+			"church library",
+			evalProgramStr,
+			[]any{lib.Church + "fact three"},
+			[]any{testutil.MustEval(testutil.MustParseChurch("mult three two")).String()},
+		},
+	})
+}
@@ -0,0 +1,556 @@
+/*
+ * Scanner/lexer, modelled after Go's.
+ * (https://github.com/golang/go/blob/master/src/go/scanner/scanner.go)
+ */
+package syntax
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	eof = -1
+)
+
+var identifiers = map[string]TokenKind{
+	"and": TokenAndAnd,
+	"or":  TokenOrOr,
+
+	"lambda": TokenLambda,
+	"forall": TokenForall,
+	"let":    TokenLet,
+	"in":     TokenIn,
+	"match":  TokenMatch,
+	"if":     TokenIf,
+	"then":   TokenThen,
+	"else":   TokenElse,
+	"with":   TokenWith,
+	"inl":    TokenInl,
+	"inr":    TokenInr,
+	"rec":    TokenRec,
+	"pi":     TokenPi,
+	"true":   TokenBool,
+	"false":  TokenBool,
+
+	"bool":  TokenTBool,
+	"int":   TokenTInt,
+	"float": TokenTFloat,
+
+	// NOTE: we could have used an integer 1 and better
+	// categorize it during parsing, but this is just simpler.
+	"unit": TokenTUnit,
+
+	// Untested. We're also missing all our gates:
+	//	H (Hadamard) N (not) Vtheta (phase shift)
+	//	X (exchange) N_C (controlled not)
+	//	two more Pauli besides (not)?
+	//	"new"    : TokenNew,
+	//	"meas"   : TokenMeas,
+}
+
+type Token struct {
+	Kind   TokenKind
+	Ln, Cn uint // line/column numbers (1-based)
+	Raw    string
+}
+
+type scanner struct {
+	src []byte // input file, fully loaded
+
+	fn     string // filename
+	ln, cn uint   // line/column numbers (1-based; columns counted in rune)
+
+	ch      rune // current character/rune; set to eof when done
+	offset  int  // ch's offset
+	nextOff int  // offset + "len(ch)"
+
+	diags Diagnostics // reported so far (shared with the parser)
+
+	comments []comment // skipped so far, for golc fmt
+}
+
+// A comment, from line ln, column cn, to line eln
+type comment struct {
+	ln, cn, eln uint
+	text        string
+}
+
+func (s *scanner) init(src []byte, fn string) {
+	s.src = src
+	s.fn = fn
+	s.ln = 1
+	s.cn = 0
+	s.ch = ' '
+	s.offset = 0
+	s.nextOff = 0
+	s.diags = nil
+	s.comments = nil
+
+	// load first rune
+	s.next()
+}
+
+// grab next rune
+//
+// XXX/TODO: the cn/ln handling is really clumsy.
+func (s *scanner) next() {
+
+	// we still have something to read
+	if s.nextOff < len(s.src) {
+		s.offset = s.nextOff
+
+		// assume common case: ascii character
+		r, w := rune(s.src[s.offset]), 1
+
+		if r >= utf8.RuneSelf {
+			r, w = utf8.DecodeRune(s.src[s.offset:])
+		}
+
+		s.cn++
+		if r == '\n' {
+			s.ln++
+			s.cn = 0
+		}
+
+		if r == utf8.RuneError && w == 1 {
+			s.error(s.ln, s.cn, s.cn+1, "Invalid UTF-8 encoding")
+		}
+
+		s.ch = r
+		s.nextOff += w
+
+	} else if s.ch != eof {
+		s.offset = len(s.src)
+		s.ch = eof
+		if s.cn == 0 {
+			s.cn = 1
+		} else {
+			s.cn++
+		}
+	}
+}
+
+// Report an error on line ln, from column cn to ecn (exclusive);
+// errors reported at the same position as the previous one are
+// dropped, as they're most likely a consequence of it.
+func (s *scanner) error(ln, cn, ecn uint, m string, notes ...string) {
+	if n := len(s.diags); n > 0 && s.diags[n-1].Start == (Pos{ln, cn}) {
+		return
+	}
+	s.diags = append(s.diags, &Diagnostic{
+		SeverityError, s.fn, Pos{ln, cn}, Pos{ln, ecn}, m, notes,
+	})
+}
+
+func (s *scanner) peek() byte {
+	if s.nextOff < len(s.src) {
+		return s.src[s.nextOff]
+	}
+	return 0
+}
+
+// skip whitespaces and comments
+func (s *scanner) skipWhites() {
+	// NOTE: next() handles reseting ln/cn
+	for {
+		switch {
+		case s.ch == ' ' || s.ch == '\t' || s.ch == '\n' || s.ch == '\r':
+			s.next()
+		case s.ch == '#':
+			ln, cn, off := s.ln, s.cn, s.offset
+			s.skipLineComment()
+			s.comment(ln, cn, off)
+		case s.ch == '/' && s.peek() == '*':
+			ln, cn, off := s.ln, s.cn, s.offset
+			s.skipBlockComment()
+			s.comment(ln, cn, off)
+		default:
+			return
+		}
+	}
+}
+
+// Record the comment starting on line ln, column cn, at
+// offset off, which has just been skipped.
+func (s *scanner) comment(ln, cn uint, off int) {
+	t := strings.TrimRight(string(s.src[off:s.offset]), "\r")
+	// NOTE: s.ln is already past a newline following the comment
+	eln := ln + uint(strings.Count(t, "\n"))
+	s.comments = append(s.comments, comment{ln, cn, eln, t})
+}
+
+// # up to the end of the line
+func (s *scanner) skipLineComment() {
+	for s.ch != '\n' && s.ch != eof {
+		s.next()
+	}
+}
+
+// /* up to */; comments don't nest
+func (s *scanner) skipBlockComment() {
+	ln, cn := s.ln, s.cn
+	s.next()
+	s.next()
+	for !(s.ch == '*' && s.peek() == '/') {
+		if s.ch == eof {
+			s.error(ln, cn, cn+2, "Unterminated comment")
+			return
+		}
+		s.next()
+	}
+	s.next()
+	s.next()
+}
+
+func (s *scanner) switch2(tok0 TokenKind, ch1 rune, tok1 TokenKind) TokenKind {
+	if s.ch == ch1 {
+		s.next()
+		return tok1
+	}
+	return tok0
+}
+
+func (s *scanner) switch3(
+	tok0 TokenKind, ch1 rune, tok1 TokenKind, ch2 rune, tok2 TokenKind,
+) TokenKind {
+	if s.ch == ch1 {
+		s.next()
+		return tok1
+	}
+	if s.ch == ch2 {
+		s.next()
+		return tok2
+	}
+	return tok0
+}
+
+func (s *scanner) switch4(tok0, tok1, tok2, tok3 TokenKind) TokenKind {
+	b0, b1 := s.ch, s.peek()
+
+	if b0 == '=' && b1 == '.' {
+		s.next()
+		s.next()
+		return tok3
+	}
+	if b0 == '=' {
+		s.next()
+		return tok2
+	}
+	if b0 == '.' {
+		s.next()
+		return tok1
+	}
+
+	return tok0
+}
+
+// returns lower-case ch iff ch is ASCII letter
+func lower(ch rune) rune { return ('a' - 'A') | ch }
+
+func isDigit(ch rune) bool { return '0' <= ch && ch <= '9' }
+
+func isLetter(ch rune) bool {
+	return ('a' <= lower(ch) && lower(ch) <= 'z') ||
+		ch == '_' || (ch >= utf8.RuneSelf && unicode.IsLetter(ch))
+}
+
+func (s *scanner) idOrName() TokenKind {
+	off := s.offset
+
+	// we know that the first s.ch is a letter ≠ λ, Λ already,
+	// so we can look for numbers already
+	for (isLetter(s.ch) || isDigit(s.ch)) && s.ch != 'λ' && s.ch != 'Λ' {
+		s.next()
+	}
+
+	if kind, ok := identifiers[string(s.src[off:s.offset])]; ok {
+		return kind
+	}
+	return TokenName
+}
+
+// Skip digits valid in base 10 or 16, and underscores; returns
+// the first digit invalid in base, if any (0 otherwise).
+func (s *scanner) skipDigits(base int) (invalid rune) {
+	for {
+		switch {
+		case s.ch == '_':
+		case isDigit(s.ch):
+			if int(s.ch-'0') >= base && invalid == 0 {
+				invalid = s.ch
+			}
+		case base == 16 && 'a' <= lower(s.ch) && lower(s.ch) <= 'f':
+		default:
+			return invalid
+		}
+		s.next()
+	}
+}
+
+var baseNames = map[int]string{
+	16: "hexadecimal",
+	8:  "octal",
+	2:  "binary",
+}
+
+// Integers are decimal, hexadecimal (0x), octal (0o) or binary
+// (0b); leading zeros don't make an octal literal. Floats are
+// decimal, with an optional exponent (1.5e-3, 2e10, .5, 3.).
+// Underscores may separate digits (1_000_000, 0x_ff).
+//
+// Values are computed by the parser.
+func (s *scanner) number() TokenKind {
+	kind, base := TokenInt, 10
+	ln, cn, off := s.ln, s.cn, s.offset
+
+	if s.ch == '0' {
+		switch lower(rune(s.peek())) {
+		case 'x':
+			base = 16
+		case 'o':
+			base = 8
+		case 'b':
+			base = 2
+		}
+		if base != 10 {
+			s.next()
+			s.next()
+		}
+	}
+
+	invalid := s.skipDigits(base)
+
+	if base == 10 && s.ch == '.' {
+		s.next()
+		kind = TokenFloat
+		s.skipDigits(10)
+	}
+
+	if base == 10 && lower(s.ch) == 'e' {
+		s.next()
+		kind = TokenFloat
+		if s.ch == '+' || s.ch == '-' {
+			s.next()
+		}
+		if !isDigit(s.ch) {
+			s.error(ln, cn, s.cn, "Exponent has no digits")
+		}
+		s.skipDigits(10)
+	}
+
+	lit := string(s.src[off:s.offset])
+
+	switch {
+	case base != 10 && strings.Trim(lit[2:], "_") == "":
+		s.error(ln, cn, s.cn, fmt.Sprintf("No digits in %s literal", baseNames[base]))
+	case invalid != 0:
+		s.error(ln, cn, s.cn, fmt.Sprintf("Invalid digit %q in %s literal",
+			invalid, baseNames[base]))
+	case !validSeps(lit):
+		s.error(ln, cn, s.cn, "'_' must separate successive digits")
+	}
+
+	return kind
+}
+
+// true if the underscores of the number literal x all separate
+// digits (or a base prefix and a digit).
+func validSeps(x string) bool {
+	hex := len(x) > 1 && x[0] == '0' && lower(rune(x[1])) == 'x'
+
+	isd := func(i int) bool {
+		return i >= 0 && i < len(x) && (isDigit(rune(x[i])) ||
+			(hex && 'a' <= lower(rune(x[i])) && lower(rune(x[i])) <= 'f'))
+	}
+
+	for i := range x {
+		if x[i] != '_' {
+			continue
+		}
+		prefix := i == 2 && x[0] == '0' && strings.ContainsRune("xXoObB", rune(x[1]))
+		if (!isd(i-1) && !prefix) || !isd(i+1) {
+			return false
+		}
+	}
+	return true
+}
+
+// grab next token
+func (s *scanner) scan() Token {
+	s.skipWhites()
+
+	var kind TokenKind
+
+	ln, cn, off := s.ln, s.cn, s.offset
+
+	switch ch := s.ch; {
+
+	case isLetter(ch) && ch != 'λ' && ch != 'Λ':
+		kind = s.idOrName()
+	case isDigit(ch) || (ch == '.' && isDigit(rune(s.peek()))):
+		kind = s.number()
+
+	case ch == eof:
+		kind = TokenEOF
+
+	default:
+		s.next()
+
+		switch ch {
+		case 'λ':
+			kind = TokenLambda
+		case 'Λ':
+			kind = TokenBigLambda
+		// \/: ⊕
+		case '\\':
+			kind = s.switch2(TokenLambda, '/', TokenOPlus)
+		case '(':
+			kind = TokenLParen
+		case ')':
+			kind = TokenRParen
+		case '[':
+			kind = TokenLSquare
+		case ']':
+			kind = TokenRSquare
+		case '.':
+			// floats (e.g. ".3") managed by outer switch
+			kind = TokenDot
+
+		case '!':
+			kind = TokenExcl
+
+		case '+':
+			kind = s.switch2(TokenPlus, '.', TokenFPlus)
+		// -*: ⊸
+		case '-':
+			if s.ch == '*' {
+				s.next()
+				kind = TokenRMultiMap
+				break
+			}
+			kind = s.switch3(TokenMinus, '.', TokenFMinus, '>', TokenArrow)
+		// **: ⊗
+		case '*':
+			kind = s.switch3(TokenStar, '.', TokenFStar, '*', TokenOMult)
+		// /\: Λ
+		case '/':
+			kind = s.switch3(TokenSlash, '.', TokenFSlash, '\\', TokenBigLambda)
+
+		// TODO: make sure all those are tested
+		// << >>: 〈 〉
+		case '<':
+			if s.ch == '<' {
+				s.next()
+				kind = TokenLBracket
+				break
+			}
+			kind = s.switch4(
+				TokenLess,    // <
+				TokenFLess,   // <.
+				TokenLessEq,  // <=
+				TokenFLessEq, // <=.
+			)
+		case '>':
+			if s.ch == '>' {
+				s.next()
+				kind = TokenRBracket
+				break
+			}
+			kind = s.switch4(
+				TokenMore,    // >
+				TokenFMore,   // >.
+				TokenMoreEq,  // >=
+				TokenFMoreEq, // >=.
+			)
+
+		case ',':
+			kind = TokenComa
+		case '=':
+			kind = TokenEqual
+
+		case '〈':
+			kind = TokenLBracket
+		case '〉':
+			kind = TokenRBracket
+
+		case '|':
+			kind = s.switch2(TokenOr, '|', TokenOrOr)
+		// & alone is ×'s ASCII spelling (there's no bitwise and)
+		case '&':
+			kind = s.switch2(TokenProduct, '&', TokenAndAnd)
+
+		case '≤':
+			kind = s.switch2(TokenLessEq, '.', TokenFLessEq)
+		case '≥':
+			kind = s.switch2(TokenMoreEq, '.', TokenFMoreEq)
+
+		case ':':
+			kind = TokenColon
+		case ';':
+			kind = TokenSemicolon
+
+		case 'π':
+			kind = TokenPi
+
+		case '→':
+			kind = TokenArrow
+
+		case '×':
+			kind = TokenProduct
+
+		case '⊕':
+			kind = TokenOPlus
+
+		case '⊸':
+			kind = TokenRMultiMap
+
+		case '⊗':
+			kind = TokenOMult
+
+		case '∀':
+			kind = TokenForall
+
+		case eof:
+			kind = TokenEOF
+
+		// case '⊤': TokenTrue
+
+		// a single byte is an invalid encoding, already reported
+		// by next(); otherwise, that's an actual U+FFFD
+		case utf8.RuneError:
+			kind = TokenError
+			if s.offset-off > 1 {
+				s.error(ln, cn, cn+1, fmt.Sprintf("Unexpected character: %q", ch))
+			}
+
+		default:
+			kind = TokenError
+			s.error(ln, cn, cn+1, fmt.Sprintf("Unexpected character: %q", ch))
+		}
+	}
+
+	return Token{kind, ln, cn, string(s.src[off:s.offset])}
+}
+
+// grab next token
+func (s *scanner) scanAll() ([]Token, error) {
+	var toks []Token
+
+	for {
+		tok := s.scan()
+		toks = append(toks, tok)
+		if tok.Kind == TokenEOF {
+			return toks, s.diags.err()
+		}
+	}
+}
+
+// slurp all tokens
+func scanAll(src string, fn string) ([]Token, error) {
+	var s scanner
+	s.init([]byte(src), fn)
+	return s.scanAll()
+}
@@ -0,0 +1,563 @@
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/mbivert/ftests"
+
+	. "github.com/mbivert/golc/syntax"
+)
+
+func TestScannerScanAll(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"empty input",
+			Scan,
+			[]any{"", ""},
+			[]any{[]Token{Token{TokenEOF, 1, 1, ""}}, nil},
+		},
+		{
+			"spaces",
+			Scan,
+			[]any{"  \t\t\r\n", ""},
+			[]any{[]Token{Token{TokenEOF, 2, 1, ""}}, nil},
+		},
+		{
+			"single byte tokens",
+			Scan,
+			[]any{"  \t\t\r\n().  :<× >", ""},
+			[]any{[]Token{
+				Token{TokenLParen, 2, 1, "("},
+				Token{TokenRParen, 2, 2, ")"},
+				Token{TokenDot, 2, 3, "."},
+				Token{TokenColon, 2, 6, ":"},
+				Token{TokenLess, 2, 7, "<"},
+				Token{TokenProduct, 2, 8, "×"},
+				Token{TokenMore, 2, 10, ">"},
+				Token{TokenEOF, 2, 11, ""},
+			}, nil},
+		},
+		{
+			"multi-bytes words",
+			Scan,
+			[]any{"hello world", ""},
+			[]any{[]Token{
+				Token{TokenName, 1, 1, "hello"},
+				Token{TokenName, 1, 7, "world"},
+				Token{TokenEOF, 1, 12, ""},
+			}, nil},
+		},
+		{
+			"ifelse",
+			Scan,
+			[]any{"\n(λp. λx. λy. p x y)", ""},
+			[]any{[]Token{
+				Token{TokenLParen, 2, 1, "("},
+				Token{TokenLambda, 2, 2, "λ"},
+				Token{TokenName, 2, 3, "p"},
+				Token{TokenDot, 2, 4, "."},
+
+				Token{TokenLambda, 2, 6, "λ"},
+				Token{TokenName, 2, 7, "x"},
+				Token{TokenDot, 2, 8, "."},
+
+				Token{TokenLambda, 2, 10, "λ"},
+				Token{TokenName, 2, 11, "y"},
+				Token{TokenDot, 2, 12, "."},
+
+				Token{TokenName, 2, 14, "p"},
+				Token{TokenName, 2, 16, "x"},
+				Token{TokenName, 2, 18, "y"},
+				Token{TokenRParen, 2, 19, ")"},
+
+				Token{TokenEOF, 2, 20, ""},
+			}, nil},
+		},
+		{
+			"arrow",
+			Scan,
+			[]any{"(λp:bool -> bool . M)", ""},
+			[]any{[]Token{
+				Token{TokenLParen, 1, 1, "("},
+				Token{TokenLambda, 1, 2, "λ"},
+				Token{TokenName, 1, 3, "p"},
+				Token{TokenColon, 1, 4, ":"},
+				Token{TokenTBool, 1, 5, "bool"},
+				Token{TokenArrow, 1, 10, "->"},
+				Token{TokenTBool, 1, 13, "bool"},
+				Token{TokenDot, 1, 18, "."},
+				Token{TokenName, 1, 20, "M"},
+				Token{TokenRParen, 1, 21, ")"},
+				Token{TokenEOF, 1, 22, ""},
+			}, nil},
+		},
+		{
+			"arrow (bis)",
+			Scan,
+			[]any{"(λp:bool->  bool . M)", ""},
+			[]any{[]Token{
+				Token{TokenLParen, 1, 1, "("},
+				Token{TokenLambda, 1, 2, "λ"},
+				Token{TokenName, 1, 3, "p"},
+				Token{TokenColon, 1, 4, ":"},
+				Token{TokenTBool, 1, 5, "bool"},
+				Token{TokenArrow, 1, 9, "->"},
+				Token{TokenTBool, 1, 13, "bool"},
+				Token{TokenDot, 1, 18, "."},
+				Token{TokenName, 1, 20, "M"},
+				Token{TokenRParen, 1, 21, ")"},
+				Token{TokenEOF, 1, 22, ""},
+			}, nil},
+		},
+		{
+			"arrow (bis)",
+			Scan,
+			[]any{"(λp:bool →  bool→. M)", ""},
+			[]any{[]Token{
+				Token{TokenLParen, 1, 1, "("},
+				Token{TokenLambda, 1, 2, "λ"},
+				Token{TokenName, 1, 3, "p"},
+				Token{TokenColon, 1, 4, ":"},
+				Token{TokenTBool, 1, 5, "bool"},
+				Token{TokenArrow, 1, 10, "→"},
+				Token{TokenTBool, 1, 13, "bool"},
+				Token{TokenArrow, 1, 17, "→"},
+				Token{TokenDot, 1, 18, "."},
+				Token{TokenName, 1, 20, "M"},
+				Token{TokenRParen, 1, 21, ")"},
+				Token{TokenEOF, 1, 22, ""},
+			}, nil},
+		},
+		{
+			"isolated integer",
+			Scan,
+			[]any{"0123", ""},
+			[]any{[]Token{
+				Token{TokenInt, 1, 1, "0123"},
+				Token{TokenEOF, 1, 5, ""},
+			}, nil},
+		},
+		{
+			"allow unusual number parsing terminator",
+			Scan,
+			[]any{"0123aaa", ""},
+			[]any{[]Token{
+				Token{TokenInt, 1, 1, "0123"},
+				Token{TokenName, 1, 5, "aaa"},
+				Token{TokenEOF, 1, 8, ""},
+			}, nil},
+		},
+		{
+			"numbers",
+			Scan,
+			[]any{"0123 123 123.46 .10 .", ""},
+			[]any{[]Token{
+				Token{TokenInt, 1, 1, "0123"},
+				Token{TokenInt, 1, 6, "123"},
+				Token{TokenFloat, 1, 10, "123.46"},
+				Token{TokenFloat, 1, 17, ".10"},
+				Token{TokenDot, 1, 21, "."},
+				Token{TokenEOF, 1, 22, ""},
+			}, nil},
+		},
+		{
+			"two-bytes operators, slashes",
+			Scan,
+			[]any{"+. + . //.", ""},
+			[]any{[]Token{
+				Token{TokenFPlus, 1, 1, "+."},
+				Token{TokenPlus, 1, 4, "+"},
+				Token{TokenDot, 1, 6, "."},
+				Token{TokenSlash, 1, 8, "/"},
+				Token{TokenFSlash, 1, 9, "/."},
+				Token{TokenEOF, 1, 11, ""},
+			}, nil},
+		},
+		{
+			"multi-byte known tokens",
+			Scan,
+			[]any{"false let truer\ttrue", ""},
+			[]any{[]Token{
+				Token{TokenBool, 1, 1, "false"},
+				Token{TokenLet, 1, 7, "let"},
+				Token{TokenName, 1, 11, "truer"},
+				Token{TokenBool, 1, 17, "true"},
+				Token{TokenEOF, 1, 21, ""},
+			}, nil},
+		},
+		{
+			"'twos' are reckognized as a separator (was a bug)",
+			Scan,
+			[]any{"foo||bar&&", ""},
+			[]any{[]Token{
+				Token{TokenName, 1, 1, "foo"},
+				Token{TokenOrOr, 1, 4, "||"},
+				Token{TokenName, 1, 6, "bar"},
+				Token{TokenAndAnd, 1, 9, "&&"},
+				Token{TokenEOF, 1, 11, ""},
+			}, nil},
+		},
+	})
+}
+
+func TestScannerProduct(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"〈〉",
+			Scan,
+			[]any{"〈〉", ""},
+			[]any{[]Token{
+				Token{TokenLBracket, 1, 1, "〈"},
+				Token{TokenRBracket, 1, 2, "〉"},
+				Token{TokenEOF, 1, 3, ""},
+			}, nil},
+		},
+		{
+			"〈X〉",
+			Scan,
+			[]any{"〈X〉", ""},
+			[]any{[]Token{
+				Token{TokenLBracket, 1, 1, "〈"},
+				Token{TokenName, 1, 2, "X"},
+				Token{TokenRBracket, 1, 3, "〉"},
+				Token{TokenEOF, 1, 4, ""},
+			}, nil},
+		},
+		{
+			"〈X,   Y〉",
+			Scan,
+			[]any{"〈X,   Y〉", ""},
+			[]any{[]Token{
+				Token{TokenLBracket, 1, 1, "〈"},
+				Token{TokenName, 1, 2, "X"},
+				Token{TokenComa, 1, 3, ","},
+				Token{TokenName, 1, 7, "Y"},
+				Token{TokenRBracket, 1, 8, "〉"},
+				Token{TokenEOF, 1, 9, ""},
+			}, nil},
+		},
+		{
+			"×'s ASCII spelling, vs. &&",
+			Scan,
+			[]any{"int&bool a&&b", ""},
+			[]any{[]Token{
+				Token{TokenTInt, 1, 1, "int"},
+				Token{TokenProduct, 1, 4, "&"},
+				Token{TokenTBool, 1, 5, "bool"},
+				Token{TokenName, 1, 10, "a"},
+				Token{TokenAndAnd, 1, 11, "&&"},
+				Token{TokenName, 1, 13, "b"},
+				Token{TokenEOF, 1, 14, ""},
+			}, nil},
+		},
+	})
+}
+
+func TestScannerSum(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"⊕, and its ASCII spelling",
+			Scan,
+			[]any{"int⊕ bool \\/\\x", ""},
+			[]any{[]Token{
+				Token{TokenTInt, 1, 1, "int"},
+				Token{TokenOPlus, 1, 4, "⊕"},
+				Token{TokenTBool, 1, 6, "bool"},
+				Token{TokenOPlus, 1, 11, "\\/"},
+				Token{TokenLambda, 1, 13, "\\"},
+				Token{TokenName, 1, 14, "x"},
+				Token{TokenEOF, 1, 15, ""},
+			}, nil},
+		},
+	})
+}
+
+func TestScannerLinear(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"⊸, ⊗, and their ASCII spellings",
+			Scan,
+			[]any{"!int⊸ a⊗b -* a**b -.1 *.", ""},
+			[]any{[]Token{
+				Token{TokenExcl, 1, 1, "!"},
+				Token{TokenTInt, 1, 2, "int"},
+				Token{TokenRMultiMap, 1, 5, "⊸"},
+				Token{TokenName, 1, 7, "a"},
+				Token{TokenOMult, 1, 8, "⊗"},
+				Token{TokenName, 1, 9, "b"},
+				Token{TokenRMultiMap, 1, 11, "-*"},
+				Token{TokenName, 1, 14, "a"},
+				Token{TokenOMult, 1, 15, "**"},
+				Token{TokenName, 1, 17, "b"},
+				Token{TokenFMinus, 1, 19, "-."},
+				Token{TokenInt, 1, 21, "1"},
+				Token{TokenFStar, 1, 23, "*."},
+				Token{TokenEOF, 1, 25, ""},
+			}, nil},
+		},
+	})
+}
+
+func TestScannerSystemF(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"Λ, ∀, [], and their ASCII spellings",
+			Scan,
+			[]any{"Λa.∀b. x[a] /\\c forall", ""},
+			[]any{[]Token{
+				Token{TokenBigLambda, 1, 1, "Λ"},
+				Token{TokenName, 1, 2, "a"},
+				Token{TokenDot, 1, 3, "."},
+				Token{TokenForall, 1, 4, "∀"},
+				Token{TokenName, 1, 5, "b"},
+				Token{TokenDot, 1, 6, "."},
+				Token{TokenName, 1, 8, "x"},
+				Token{TokenLSquare, 1, 9, "["},
+				Token{TokenName, 1, 10, "a"},
+				Token{TokenRSquare, 1, 11, "]"},
+				Token{TokenBigLambda, 1, 13, "/\\"},
+				Token{TokenName, 1, 15, "c"},
+				Token{TokenForall, 1, 17, "forall"},
+				Token{TokenEOF, 1, 23, ""},
+			}, nil},
+		},
+	})
+}
+
+func TestScannerExcl(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"!true",
+			Scan,
+			[]any{"!true", ""},
+			[]any{[]Token{
+				Token{TokenExcl, 1, 1, "!"},
+				Token{TokenBool, 1, 2, "true"},
+				Token{TokenEOF, 1, 6, ""},
+			}, nil},
+		},
+	})
+}
+
+func TestScannerFCmpOp(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"3.≤.5.",
+			Scan,
+			[]any{"3.≤.5.", ""},
+			[]any{[]Token{
+				Token{TokenFloat, 1, 1, "3."},
+				Token{TokenFLessEq, 1, 3, "≤."},
+				Token{TokenFloat, 1, 5, "5."},
+				Token{TokenEOF, 1, 7, ""},
+			}, nil},
+		},
+		{
+			"≤x",
+			Scan,
+			[]any{"≤x", ""},
+			[]any{[]Token{
+				Token{TokenLessEq, 1, 1, "≤"},
+				Token{TokenName, 1, 2, "x"},
+				Token{TokenEOF, 1, 3, ""},
+			}, nil},
+		},
+	})
+}
+
+func TestScannerIdentifier(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"x0 y01",
+			Scan,
+			[]any{"x0 y01λ", ""},
+			[]any{[]Token{
+				Token{TokenName, 1, 1, "x0"},
+				Token{TokenName, 1, 4, "y01"},
+				Token{TokenLambda, 1, 7, "λ"},
+				Token{TokenEOF, 1, 8, ""},
+			}, nil},
+		},
+	})
+}
+
+func TestScannerComments(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"line comment",
+			Scan,
+			[]any{"x # y z\ny", ""},
+			[]any{[]Token{
+				Token{TokenName, 1, 1, "x"},
+				Token{TokenName, 2, 1, "y"},
+				Token{TokenEOF, 2, 2, ""},
+			}, nil},
+		},
+		{
+			"line comment, up to EOF",
+			Scan,
+			[]any{"x #", ""},
+			[]any{[]Token{
+				Token{TokenName, 1, 1, "x"},
+				Token{TokenEOF, 1, 4, ""},
+			}, nil},
+		},
+		{
+			"block comments",
+			Scan,
+			[]any{"x /* y\n * z */ y/**/;", ""},
+			[]any{[]Token{
+				Token{TokenName, 1, 1, "x"},
+				Token{TokenName, 2, 9, "y"},
+				Token{TokenSemicolon, 2, 14, ";"},
+				Token{TokenEOF, 2, 15, ""},
+			}, nil},
+		},
+		{
+			"division isn't a comment",
+			Scan,
+			[]any{"x / y", ""},
+			[]any{[]Token{
+				Token{TokenName, 1, 1, "x"},
+				Token{TokenSlash, 1, 3, "/"},
+				Token{TokenName, 1, 5, "y"},
+				Token{TokenEOF, 1, 6, ""},
+			}, nil},
+		},
+	})
+}
+
+func TestScannerErrors(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"unknown character",
+			Scan,
+			[]any{"x $", ""},
+			[]any{[]Token{
+				Token{TokenName, 1, 1, "x"},
+				Token{TokenError, 1, 3, "$"},
+				Token{TokenEOF, 1, 4, ""},
+			}, diagErr(1, 3, 4, "Unexpected character: '$'")},
+		},
+		{
+			"invalid UTF-8",
+			Scan,
+			[]any{"\xffx", ""},
+			[]any{[]Token{
+				Token{TokenError, 1, 1, "\xff"},
+				Token{TokenName, 1, 2, "x"},
+				Token{TokenEOF, 1, 3, ""},
+			}, diagErr(1, 1, 2, "Invalid UTF-8 encoding")},
+		},
+		{
+			"invalid UTF-8, reported once per byte",
+			Scan,
+			[]any{"\xff\xfe 1 $", ""},
+			[]any{[]Token{
+				Token{TokenError, 1, 1, "\xff"},
+				Token{TokenError, 1, 2, "\xfe"},
+				Token{TokenInt, 1, 4, "1"},
+				Token{TokenError, 1, 6, "$"},
+				Token{TokenEOF, 1, 7, ""},
+			}, Diagnostics{
+				{SeverityError, "", Pos{1, 1}, Pos{1, 2}, "Invalid UTF-8 encoding", nil},
+				{SeverityError, "", Pos{1, 2}, Pos{1, 3}, "Invalid UTF-8 encoding", nil},
+				{SeverityError, "", Pos{1, 6}, Pos{1, 7}, "Unexpected character: '$'", nil},
+			}},
+		},
+		{
+			"actual U+FFFD",
+			Scan,
+			[]any{"\ufffd", ""},
+			[]any{[]Token{
+				Token{TokenError, 1, 1, "\ufffd"},
+				Token{TokenEOF, 1, 2, ""},
+			}, diagErr(1, 1, 2, "Unexpected character: '\ufffd'")},
+		},
+	})
+}
+
+func TestScannerNumbers(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"bases, separators",
+			Scan,
+			[]any{"0x_Ff 0o17 0B101 1_000", ""},
+			[]any{[]Token{
+				Token{TokenInt, 1, 1, "0x_Ff"},
+				Token{TokenInt, 1, 7, "0o17"},
+				Token{TokenInt, 1, 12, "0B101"},
+				Token{TokenInt, 1, 18, "1_000"},
+				Token{TokenEOF, 1, 23, ""},
+			}, nil},
+		},
+		{
+			"exponents",
+			Scan,
+			[]any{"1.5e-3 2E10 .5e+1 3.e2", ""},
+			[]any{[]Token{
+				Token{TokenFloat, 1, 1, "1.5e-3"},
+				Token{TokenFloat, 1, 8, "2E10"},
+				Token{TokenFloat, 1, 13, ".5e+1"},
+				Token{TokenFloat, 1, 19, "3.e2"},
+				Token{TokenEOF, 1, 23, ""},
+			}, nil},
+		},
+		{
+			"hexadecimal literals have no exponent",
+			Scan,
+			[]any{"0x1e5", ""},
+			[]any{[]Token{
+				Token{TokenInt, 1, 1, "0x1e5"},
+				Token{TokenEOF, 1, 6, ""},
+			}, nil},
+		},
+		{
+			"no digits",
+			Scan,
+			[]any{"0x y", ""},
+			[]any{[]Token{
+				Token{TokenInt, 1, 1, "0x"},
+				Token{TokenName, 1, 4, "y"},
+				Token{TokenEOF, 1, 5, ""},
+			}, diagErr(1, 1, 3, "No digits in hexadecimal literal")},
+		},
+		{
+			"invalid digit",
+			Scan,
+			[]any{"0b1021", ""},
+			[]any{[]Token{
+				Token{TokenInt, 1, 1, "0b1021"},
+				Token{TokenEOF, 1, 7, ""},
+			}, diagErr(1, 1, 7, "Invalid digit '2' in binary literal")},
+		},
+		{
+			"misplaced separator",
+			Scan,
+			[]any{"1__0", ""},
+			[]any{[]Token{
+				Token{TokenInt, 1, 1, "1__0"},
+				Token{TokenEOF, 1, 5, ""},
+			}, diagErr(1, 1, 5, "'_' must separate successive digits")},
+		},
+		{
+			"separator before an exponent",
+			Scan,
+			[]any{"1_e5", ""},
+			[]any{[]Token{
+				Token{TokenFloat, 1, 1, "1_e5"},
+				Token{TokenEOF, 1, 5, ""},
+			}, diagErr(1, 1, 5, "'_' must separate successive digits")},
+		},
+		{
+			"exponent without digits",
+			Scan,
+			[]any{"1e+x", ""},
+			[]any{[]Token{
+				Token{TokenFloat, 1, 1, "1e+"},
+				Token{TokenName, 1, 4, "x"},
+				Token{TokenEOF, 1, 5, ""},
+			}, diagErr(1, 1, 4, "Exponent has no digits")},
+		},
+	})
+}
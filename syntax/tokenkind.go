@@ -0,0 +1,97 @@
+package syntax
+
+type TokenKind uint
+
+//go:generate go run golang.org/x/tools/cmd/stringer -type TokenKind -linecomment tokenkind.go
+
+const (
+	TokenEOF   TokenKind = iota // EOF
+	TokenError                  // error
+
+	// Standard stuff for an untyped λ-calculus
+	// XXX Rename TokenName TokenVar
+	TokenName   // name
+	TokenLambda // λ
+
+	// System F
+	TokenBigLambda // Λ
+	TokenForall    // ∀
+	TokenLSquare   // [
+	TokenRSquare   // ]
+
+	TokenLParen // (
+	TokenRParen // )
+
+	TokenDot // .
+
+	TokenFloat // float64
+	TokenInt   // int64
+	TokenBool  // bool
+
+	// XXX meh, potential confusion (stringers),
+	// hopefully benign.
+	TokenTBool  // bool
+	TokenTInt   // int
+	TokenTFloat // float
+	TokenTUnit  // unit
+
+	TokenExcl // !
+
+	TokenPlus  // +
+	TokenFPlus // +.
+
+	TokenMinus  // -
+	TokenFMinus // -.
+
+	TokenStar   // *
+	TokenFStar  // *.
+	TokenSlash  // /
+	TokenFSlash // /.
+
+	TokenLess  // <
+	TokenFLess // <.
+	TokenMore  // >
+	TokenFMore // >.
+
+	TokenComa  // ,
+	TokenEqual // =
+
+	TokenLBracket // 〈
+	TokenRBracket // 〉
+
+	TokenOr     // |
+	TokenOrOr   // ||
+	TokenAndAnd // &&
+
+	TokenMoreEq  // ≥
+	TokenFMoreEq // ≥.
+	TokenLessEq  // ≤
+	TokenFLessEq // ≤.
+
+	TokenColon     // :
+	TokenSemicolon // ;
+	TokenPi        // π
+
+	TokenArrow   // →
+	TokenProduct // ×
+	TokenOPlus   // ⊕
+
+	TokenRMultiMap // ⊸
+	TokenOMult     // ⊗
+
+	TokenLet // let
+	TokenIn  // in
+	TokenRec // rec
+
+	TokenMatch // match
+	TokenWith  // with
+	TokenInl   // inl
+	TokenInr   // inr
+
+	TokenIf   // if
+	TokenThen // then
+	TokenElse // else
+
+	TokenNew  // new
+	TokenMeas // meas
+)
@@ -0,0 +1,194 @@
+package syntax
+
+import (
+	"fmt"
+)
+
+// Compute all free variables within a given expression
+// NOTE: this is more efficient than the previous version,
+// but perhaps the previous version would still be preferable,
+// were we to store the actual free variables at each nodes where
+// we'll need it.
+func FreeVars(x Expr) map[string]bool {
+	var aux func(Expr, map[string]bool) map[string]bool
+
+	aux = func(x Expr, m map[string]bool) map[string]bool {
+		switch x.(type) {
+		case *VarExpr:
+			m[x.(*VarExpr).Name] = true
+		case *AbsExpr:
+			// If the variable being bound by the current abstraction
+			// has already been declared higher up, then we don't
+			// want to remove it. Think:
+			//	(λx. y (λy. x y z))
+			// Here, the second y is bound, but the first one is free.
+			_, hasBefore := m[x.(*AbsExpr).Name]
+			aux(x.(*AbsExpr).Right, m)
+			if !hasBefore {
+				delete(m, x.(*AbsExpr).Name)
+			}
+		case *DeBruijnAbsExpr:
+			// named variables are always free there
+			aux(x.(*DeBruijnAbsExpr).Right, m)
+		case *AppExpr:
+			aux(x.(*AppExpr).Left, m)
+			aux(x.(*AppExpr).Right, m)
+		case *UnaryExpr:
+			aux(x.(*UnaryExpr).Right, m)
+		case *BinaryExpr:
+			aux(x.(*BinaryExpr).Left, m)
+			aux(x.(*BinaryExpr).Right, m)
+		case *ProductExpr:
+			aux(x.(*ProductExpr).Left, m)
+			aux(x.(*ProductExpr).Right, m)
+		case *LetProductExpr:
+			aux(x.(*LetProductExpr).Left, m)
+			aux(x.(*LetProductExpr).Right, m)
+		case *LetExpr:
+			aux(x.(*LetExpr).Left, m)
+			aux(x.(*LetExpr).Right, m)
+		case *LetUnitExpr:
+			aux(x.(*LetUnitExpr).Left, m)
+			aux(x.(*LetUnitExpr).Right, m)
+		case *IfExpr:
+			aux(x.(*IfExpr).Cond, m)
+			aux(x.(*IfExpr).Left, m)
+			aux(x.(*IfExpr).Right, m)
+		case *InjExpr:
+			aux(x.(*InjExpr).Right, m)
+		case *AnnotExpr:
+			aux(x.(*AnnotExpr).X, m)
+		case *TypeAbsExpr:
+			aux(x.(*TypeAbsExpr).Right, m)
+		case *TypeAppExpr:
+			aux(x.(*TypeAppExpr).Left, m)
+		case *MatchExpr:
+			aux(x.(*MatchExpr).X, m)
+			aux(x.(*MatchExpr).Left, m)
+			aux(x.(*MatchExpr).Right, m)
+
+		// *IntExpr
+		// *FloatExpr
+		// *BoolExpr
+		default:
+		}
+		return m
+	}
+
+	return aux(x, map[string]bool{})
+}
+
+func IsFree(x Expr, a string) bool {
+	_, ok := FreeVars(x)[a]
+	return ok
+}
+
+func AllVars(x Expr) map[string]bool {
+	var aux func(Expr, map[string]bool) map[string]bool
+
+	aux = func(x Expr, m map[string]bool) map[string]bool {
+		switch x.(type) {
+		case *VarExpr:
+			m[x.(*VarExpr).Name] = true
+		case *AbsExpr:
+			m[x.(*AbsExpr).Name] = true
+			aux(x.(*AbsExpr).Right, m)
+		case *AppExpr:
+			aux(x.(*AppExpr).Left, m)
+			aux(x.(*AppExpr).Right, m)
+		case *UnaryExpr:
+			aux(x.(*UnaryExpr).Right, m)
+		case *BinaryExpr:
+			aux(x.(*BinaryExpr).Left, m)
+			aux(x.(*BinaryExpr).Right, m)
+		case *ProductExpr:
+			aux(x.(*ProductExpr).Left, m)
+			aux(x.(*ProductExpr).Right, m)
+		case *LetProductExpr:
+			aux(x.(*LetProductExpr).Left, m)
+			aux(x.(*LetProductExpr).Right, m)
+		case *LetExpr:
+			aux(x.(*LetExpr).Left, m)
+			aux(x.(*LetExpr).Right, m)
+		case *LetUnitExpr:
+			aux(x.(*LetUnitExpr).Left, m)
+			aux(x.(*LetUnitExpr).Right, m)
+		case *IfExpr:
+			aux(x.(*IfExpr).Cond, m)
+			aux(x.(*IfExpr).Left, m)
+			aux(x.(*IfExpr).Right, m)
+		case *InjExpr:
+			aux(x.(*InjExpr).Right, m)
+		case *AnnotExpr:
+			aux(x.(*AnnotExpr).X, m)
+		case *TypeAbsExpr:
+			aux(x.(*TypeAbsExpr).Right, m)
+		case *TypeAppExpr:
+			aux(x.(*TypeAppExpr).Left, m)
+		case *MatchExpr:
+			aux(x.(*MatchExpr).X, m)
+			aux(x.(*MatchExpr).Left, m)
+			aux(x.(*MatchExpr).Right, m)
+
+		// *IntExpr
+		// *FloatExpr
+		// *BoolExpr
+		default:
+		}
+
+		return m
+	}
+
+	return aux(x, map[string]bool{})
+}
+
+// Number of nodes in x
+func SizeExpr(x Expr) int {
+	switch x.(type) {
+	case *AbsExpr:
+		return 1 + SizeExpr(x.(*AbsExpr).Right)
+	case *DeBruijnAbsExpr:
+		return 1 + SizeExpr(x.(*DeBruijnAbsExpr).Right)
+	case *AppExpr:
+		return 1 + SizeExpr(x.(*AppExpr).Left) + SizeExpr(x.(*AppExpr).Right)
+	case *ProductExpr:
+		return 1 + SizeExpr(x.(*ProductExpr).Left) + SizeExpr(x.(*ProductExpr).Right)
+	case *UnaryExpr:
+		return 1 + SizeExpr(x.(*UnaryExpr).Right)
+	case *BinaryExpr:
+		return 1 + SizeExpr(x.(*BinaryExpr).Left) + SizeExpr(x.(*BinaryExpr).Right)
+	case *LetProductExpr:
+		return 1 + SizeExpr(x.(*LetProductExpr).Left) + SizeExpr(x.(*LetProductExpr).Right)
+	case *LetExpr:
+		return 1 + SizeExpr(x.(*LetExpr).Left) + SizeExpr(x.(*LetExpr).Right)
+	case *LetUnitExpr:
+		return 1 + SizeExpr(x.(*LetUnitExpr).Left) + SizeExpr(x.(*LetUnitExpr).Right)
+	case *IfExpr:
+		return 1 + SizeExpr(x.(*IfExpr).Cond) + SizeExpr(x.(*IfExpr).Left) + SizeExpr(x.(*IfExpr).Right)
+	case *InjExpr:
+		return 1 + SizeExpr(x.(*InjExpr).Right)
+	case *AnnotExpr:
+		return 1 + SizeExpr(x.(*AnnotExpr).X)
+	case *TypeAbsExpr:
+		return 1 + SizeExpr(x.(*TypeAbsExpr).Right)
+	case *TypeAppExpr:
+		return 1 + SizeExpr(x.(*TypeAppExpr).Left)
+	case *MatchExpr:
+		return 1 + SizeExpr(x.(*MatchExpr).X) + SizeExpr(x.(*MatchExpr).Left) + SizeExpr(x.(*MatchExpr).Right)
+	}
+	return 1
+}
+
+func GetFresh(ms ...map[string]bool) string {
+	for n := 0; ; n++ {
+		s := fmt.Sprintf("x%d", n)
+		for _, m := range ms {
+			if _, ok := m[s]; ok {
+				goto retry
+			}
+		}
+		return s
+
+	retry:
+	}
+}
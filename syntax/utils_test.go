@@ -1,55 +1,58 @@
-package main
+package syntax_test
 
 import (
 	"testing"
 
 	"github.com/mbivert/ftests"
+
+	"github.com/mbivert/golc/internal/testutil"
+	. "github.com/mbivert/golc/syntax"
 )
 
 func TestUtilsFreeVars(t *testing.T) {
 	ftests.Run(t, []ftests.Test{
 		{
 			"scalar expression: int",
-			freeVars,
-			[]any{mustParse("123")},
+			FreeVars,
+			[]any{testutil.MustParse("123")},
 			[]any{map[string]bool{}},
 		},
 		{
 			"scalar expression: bool",
-			freeVars,
-			[]any{mustParse("true")},
+			FreeVars,
+			[]any{testutil.MustParse("true")},
 			[]any{map[string]bool{}},
 		},
 		{
 			"scalar expression: float",
-			freeVars,
-			[]any{mustParse("42.42")},
+			FreeVars,
+			[]any{testutil.MustParse("42.42")},
 			[]any{map[string]bool{}},
 		},
 		{
 			"free variable",
-			freeVars,
-			[]any{mustParse("x")},
+			FreeVars,
+			[]any{testutil.MustParse("x")},
 			[]any{map[string]bool{"x": true}},
 		},
 		{
 			"simple abstraction, no free variable",
-			freeVars,
-			[]any{mustParse("x. x")},
+			FreeVars,
+			[]any{testutil.MustParse("x. x")},
 			[]any{map[string]bool{}},
 		},
 		{
 			"simple abstraction, one free variable",
-			freeVars,
-			[]any{mustParse("x. y")},
+			FreeVars,
+			[]any{testutil.MustParse("x. y")},
 			[]any{map[string]bool{
 				"y": true,
 			}},
 		},
 		{
 			"abstraction + applications",
-			freeVars,
-			[]any{mustParse("x. x y z")},
+			FreeVars,
+			[]any{testutil.MustParse("x. x y z")},
 			[]any{map[string]bool{
 				"y": true,
 				"z": true,
@@ -62,38 +65,38 @@ func TestUtilsAllVars(t *testing.T) {
 	ftests.Run(t, []ftests.Test{
 		{
 			"scalar expression: int",
-			allVars,
-			[]any{mustParse("123")},
+			AllVars,
+			[]any{testutil.MustParse("123")},
 			[]any{map[string]bool{}},
 		},
 		{
 			"scalar expression: bool",
-			allVars,
-			[]any{mustParse("true")},
+			AllVars,
+			[]any{testutil.MustParse("true")},
 			[]any{map[string]bool{}},
 		},
 		{
 			"scalar expression: float",
-			allVars,
-			[]any{mustParse("42.42")},
+			AllVars,
+			[]any{testutil.MustParse("42.42")},
 			[]any{map[string]bool{}},
 		},
 		{
 			"free variable",
-			allVars,
-			[]any{mustParse("x")},
+			AllVars,
+			[]any{testutil.MustParse("x")},
 			[]any{map[string]bool{"x": true}},
 		},
 		{
 			"simple abstraction, no free variable, one bound",
-			allVars,
-			[]any{mustParse("x. x")},
+			AllVars,
+			[]any{testutil.MustParse("x. x")},
 			[]any{map[string]bool{"x": true}},
 		},
 		{
 			"simple abstraction, one free variable, one bound",
-			allVars,
-			[]any{mustParse("x. y")},
+			AllVars,
+			[]any{testutil.MustParse("x. y")},
 			[]any{map[string]bool{
 				"x": true,
 				"y": true,
@@ -101,8 +104,8 @@ func TestUtilsAllVars(t *testing.T) {
 		},
 		{
 			"abstraction + applications",
-			allVars,
-			[]any{mustParse("x. x y z")},
+			AllVars,
+			[]any{testutil.MustParse("x. x y z")},
 			[]any{map[string]bool{
 				"x": true,
 				"y": true,
@@ -112,83 +115,23 @@ func TestUtilsAllVars(t *testing.T) {
 	})
 }
 
-// TODO: a bit light
-func TestUtilsPrettyPrint(t *testing.T) {
-	ftests.Run(t, []ftests.Test{
-		{
-			"bare int",
-			prettyPrint,
-			[]any{mustParse("123")},
-			[]any{"123"},
-		},
-		{
-			"bare float",
-			prettyPrint,
-			[]any{mustParse("123.42")},
-			[]any{"123.42"},
-		},
-		{
-			"bare bool",
-			prettyPrint,
-			[]any{mustParse("true")},
-			[]any{"true"},
-		},
-		{
-			"bare variable",
-			prettyPrint,
-			[]any{mustParse("someVar")},
-			[]any{"someVar"},
-		},
-		{
-			"simple abstraction (id)",
-			prettyPrint,
-			[]any{mustParse("λ x. x")},
-			[]any{"(λx. x)"},
-		},
-		{
-			"arithmetic",
-			prettyPrint,
-			[]any{mustParse("(2+2)*3")},
-			[]any{"((2 + 2) * 3)"},
-		},
-		{
-			"imbricated abstraction + application",
-			prettyPrint,
-			[]any{mustParse("λx. y. x y")},
-			[]any{"(λx. y. (x y))"},
-		},
-		{
-			"and",
-			prettyPrint,
-			[]any{and},
-			[]any{"(λx. y. (x y (λx. y. y)))"},
-		},
-		{
-			"(((x y) q) (z p))",
-			prettyPrint,
-			[]any{mustParse("(((x y) q) (z p))")},
-			[]any{"(x y q (z p))"},
-		},
-	})
-}
-
 func TestUtilsGetFresh(t *testing.T) {
 	ftests.Run(t, []ftests.Test{
 		{
 			"empty map",
-			getFresh,
+			GetFresh,
 			[]any{map[string]bool{}},
 			[]any{"x0"},
 		},
 		{
 			"not empty, but x0 still free",
-			getFresh,
+			GetFresh,
 			[]any{map[string]bool{"x": true, "y": true}},
 			[]any{"x0"},
 		},
 		{
 			"x0 already used",
-			getFresh,
+			GetFresh,
 			[]any{map[string]bool{"x0": true}},
 			[]any{"x1"},
 		},
@@ -1,75 +0,0 @@
-// Code generated by "stringer -type tokenKind -linecomment tokenkind.go"; DO NOT EDIT.
-
-package main
-
-import "strconv"
-
-func _() {
-	// An "invalid array index" compiler error signifies that the constant values have changed.
-	// Re-run the stringer command to generate them again.
-	var x [1]struct{}
-	_ = x[tokenEOF-0]
-	_ = x[tokenError-1]
-	_ = x[tokenName-2]
-	_ = x[tokenLambda-3]
-	_ = x[tokenLParen-4]
-	_ = x[tokenRParen-5]
-	_ = x[tokenDot-6]
-	_ = x[tokenFloat-7]
-	_ = x[tokenInt-8]
-	_ = x[tokenBool-9]
-	_ = x[tokenTBool-10]
-	_ = x[tokenTInt-11]
-	_ = x[tokenTFloat-12]
-	_ = x[tokenTUnit-13]
-	_ = x[tokenExcl-14]
-	_ = x[tokenPlus-15]
-	_ = x[tokenFPlus-16]
-	_ = x[tokenMinus-17]
-	_ = x[tokenFMinus-18]
-	_ = x[tokenStar-19]
-	_ = x[tokenFStar-20]
-	_ = x[tokenSlash-21]
-	_ = x[tokenFSlash-22]
-	_ = x[tokenLess-23]
-	_ = x[tokenFLess-24]
-	_ = x[tokenMore-25]
-	_ = x[tokenFMore-26]
-	_ = x[tokenComa-27]
-	_ = x[tokenEqual-28]
-	_ = x[tokenLBracket-29]
-	_ = x[tokenRBracket-30]
-	_ = x[tokenOr-31]
-	_ = x[tokenOrOr-32]
-	_ = x[tokenAnd-33]
-	_ = x[tokenAndAnd-34]
-	_ = x[tokenMoreEq-35]
-	_ = x[tokenFMoreEq-36]
-	_ = x[tokenLessEq-37]
-	_ = x[tokenFLessEq-38]
-	_ = x[tokenColon-39]
-	_ = x[tokenPi-40]
-	_ = x[tokenArrow-41]
-	_ = x[tokenProduct-42]
-	_ = x[tokenLet-43]
-	_ = x[tokenIn-44]
-	_ = x[tokenRec-45]
-	_ = x[tokenMatch-46]
-	_ = x[tokenWith-47]
-	_ = x[tokenIf-48]
-	_ = x[tokenThen-49]
-	_ = x[tokenElse-50]
-	_ = x[tokenNew-51]
-	_ = x[tokenMeas-52]
-}
-
-const _tokenKind_name = "EOFerrornameλ().float64int64boolboolintfloatunit!++.--.**.//.<<.>>.,=〈〉|||&&&≥≥.≤≤.:π→×letinrecmatchwithifthenelsenewmeas"
-
-var _tokenKind_index = [...]uint8{0, 3, 8, 12, 14, 15, 16, 17, 24, 29, 33, 37, 40, 45, 49, 50, 51, 53, 54, 56, 57, 59, 60, 62, 63, 65, 66, 68, 69, 70, 73, 76, 77, 79, 80, 82, 85, 89, 92, 96, 97, 99, 102, 104, 107, 109, 112, 117, 121, 123, 127, 131, 134, 138}
-
-func (i tokenKind) String() string {
-	if i >= tokenKind(len(_tokenKind_index)-1) {
-		return "tokenKind(" + strconv.FormatInt(int64(i), 10) + ")"
-	}
-	return _tokenKind_name[_tokenKind_index[i]:_tokenKind_index[i+1]]
-}
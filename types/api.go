@@ -0,0 +1,53 @@
+/*
+ * Public API: typing. Check and CheckProgram (bidirectional,
+ * see styping.go) set the types of the expressions they're
+ * given, in place; Infer (Hindley–Milner, see typing.go) leaves
+ * them untouched. None of those panic: errors are returned, type
+ * errors being *TypeError (diag.go).
+ */
+package types
+
+import (
+	"github.com/mbivert/golc/internal/panics"
+	"github.com/mbivert/golc/syntax"
+)
+
+// Typecheck each of q's declarations, in the context of the
+// previous ones, and then q.Main, whose type is returned (nil
+// if q.Main is nil). Declarations' types are set to the inferred
+// ones.
+func CheckProgram(q *syntax.Program) (t syntax.Type, err error) {
+	return CheckProgramWith(q, ImplicitLinearity)
+}
+
+// CheckProgram, with the given linearity discipline
+func CheckProgramWith(q *syntax.Program, mode Linearity) (t syntax.Type, err error) {
+	defer panics.Catch(&err)
+	return checkProgram(q, mode)
+}
+
+// Typecheck x (simply typed λ-calculus), returns its type.
+// All abstractions are expected to be annotated.
+func Check(x syntax.Expr) (t syntax.Type, err error) {
+	return CheckWith(x, ImplicitLinearity)
+}
+
+// Check, with the given linearity discipline
+func CheckWith(x syntax.Expr, mode Linearity) (t syntax.Type, err error) {
+	defer panics.Catch(&err)
+
+	if x, err = inferSTypeCtx(x, Ctx{}, mode); err != nil {
+		return nil, err
+	}
+	if !isTyped(x.Type()) {
+		return nil, syntax.ErrAt(x, "Can't fully type '%s'", x)
+	}
+	return x.Type(), nil
+}
+
+// Infer x's principal (Hindley–Milner) type; annotations are
+// optional. x is left untouched.
+func Infer(x syntax.Expr) (t syntax.Type, err error) {
+	defer panics.Catch(&err)
+	return inferType(x)
+}
@@ -0,0 +1,60 @@
+package types_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mbivert/ftests"
+
+	"github.com/mbivert/golc/syntax"
+	. "github.com/mbivert/golc/types"
+)
+
+// Type error's location, types, sub-term and trace, as
+// reported by Check (or Infer, if inferred)
+func typeError(src string, inferred bool) (string, string, string, string, []string) {
+	x, err := syntax.Parse(src, "t.lc")
+	if err != nil {
+		panic(err)
+	}
+	if inferred {
+		_, err = Infer(x)
+	} else {
+		_, err = Check(x)
+	}
+	var e *TypeError
+	if !errors.As(err, &e) {
+		return "", "", "", "", nil
+	}
+	return e.Start.String(), fmt.Sprint(e.Expected), fmt.Sprint(e.Actual), e.Term.String(), e.Trace
+}
+
+func TestAPITypeError(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"checked",
+			typeError,
+			[]any{"let f = λx:int. x in f true", false},
+			[]any{"1:24", "int", "bool", "true", []string{
+				"applying 'f' ('int → int') to 'true'",
+			}},
+		},
+		{
+			"inferred",
+			typeError,
+			[]any{"let f = λx. x + 1 in f 〈1, 2〉", true},
+			[]any{"1:22", "int", "int × int", "f 〈1, 2〉", []string{
+				"while unifying 'int → int' with 'int × int → t2'",
+				"applying 'f' ('int → int') to '〈1, 2〉' ('int × int')",
+				"'f' defined as 'λx. x + 1' at t.lc:1:9",
+			}},
+		},
+		{
+			"not a type error",
+			typeError,
+			[]any{"x", false},
+			[]any{"", "", "", "", []string(nil)},
+		},
+	})
+}
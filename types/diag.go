@@ -0,0 +1,97 @@
+/*
+ * Type errors: diagnostics (see ../syntax/diag.go) which also
+ * hold the types which couldn't be reconciled, and how typing
+ * got there.
+ */
+package types
+
+import (
+	"fmt"
+
+	"github.com/mbivert/golc/syntax"
+)
+
+// A type error, located at (or, if unlocated, about) the sub-term
+// Term: Expected and Actual are the types which couldn't be
+// reconciled, either being nil when irrelevant (e.g. applying a
+// non-arrow). Trace is the chain of constraints and unification
+// steps which led there, from the innermost.
+//
+// As a Diagnostic, the headline is the Message, while Trace (and
+// the sub-term, if the error isn't located) are rendered as notes.
+type TypeError struct {
+	*syntax.Diagnostic
+	Expected syntax.Type
+	Actual   syntax.Type
+	Term     syntax.Expr
+	Trace    []string
+}
+
+func (e *TypeError) Unwrap() error { return e.Diagnostic }
+
+// Type error located at x, x being nil for unlocated ones
+// (e.g. from mgu()).
+func typeErrAt(x syntax.Expr, expected, actual syntax.Type, m string, args ...interface{}) *TypeError {
+	e := &TypeError{
+		&syntax.Diagnostic{syntax.SeverityError, "", syntax.Pos{}, syntax.Pos{}, fmt.Sprintf(m, args...), nil},
+		expected, actual, nil, nil,
+	}
+	if x != nil {
+		e.at(x)
+	}
+	return e
+}
+
+// e, (re)located at x
+func (e *TypeError) at(x syntax.Expr) *TypeError {
+	e.Term = x
+	if s := x.Span(); s != nil {
+		e.File, e.Start, e.End = s.File, s.Start, s.End
+	}
+	e.explain()
+	return e
+}
+
+// e, with m (args) appended to the trace
+func (e *TypeError) because(m string, args ...interface{}) *TypeError {
+	e.Trace = append(e.Trace, fmt.Sprintf(m, args...))
+	e.explain()
+	return e
+}
+
+// (re)compute e's notes; the sub-term is only quoted
+// if e isn't located.
+func (e *TypeError) explain() {
+	e.Notes = nil
+	if e.Term != nil && e.Start == (syntax.Pos{}) {
+		e.Notes = append(e.Notes, fmt.Sprintf("in '%s'", brief(e.Term)))
+	}
+	e.Notes = append(e.Notes, e.Trace...)
+}
+
+// If err is a type error, err with m (args) appended to its
+// trace; err otherwise.
+func because(err error, m string, args ...interface{}) error {
+	if e, ok := err.(*TypeError); ok {
+		return e.because(m, args...)
+	}
+	return err
+}
+
+// " at file:line:col" if x is located, "" otherwise
+func located(x syntax.Expr) string {
+	if s := x.Span(); s != nil {
+		return fmt.Sprintf(" at %s:%s", s.File, s.Start)
+	}
+	return ""
+}
+
+// x, printed on a single line, shortened if too long
+// to be quoted in an error
+func brief(x syntax.Expr) string {
+	s := x.String()
+	if rs := []rune(s); len(rs) > 40 {
+		s = string(rs[:37]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,18 @@
+package types
+
+import (
+	"github.com/mbivert/golc/syntax"
+)
+
+// Internals, exposed to the (external) tests
+
+var (
+	ApplySubst    = applySubst
+	InferSType    = inferSType
+	InferSTypeCtx = inferSTypeCtx
+	InferType     = inferType
+	MGU           = mgu
+	OccursIn      = occursIn
+)
+
+func (l Linearity) SubType(a, b syntax.Type) bool { return l.subType(a, b) }
@@ -0,0 +1,428 @@
+/*
+ * Linear/non-linear typing, following the quantum λ-calculus
+ * of Selinger & Valiron (papers/qlambdabook.pdf).
+ *
+ * Linear types are those of values which can't be duplicated,
+ * nor discarded: linear arrows (A ⊸ B), tensors (A ⊗ B), and
+ * products/sums with a linear component. Non-linear types are
+ * duplicable, either explicitly (!A), or implicitly (int, A → B,
+ * etc.).
+ *
+ * The subtyping relation (subType()) has !A <: A; a duplicable
+ * value can also be used linearly (A → B <: A ⊸ B, A × B <: A ⊗ B).
+ *
+ * Once an expression has been typed (styping.go), checkLinear()
+ * ensures that the variables of a linear type are used exactly
+ * once, and that duplicable values (abstractions of type A → B,
+ * expressions of type !A) don't hold any.
+ *
+ * That's the default, implicit, discipline: non-linear types are
+ * duplicable, and λx:int. x + x is accepted. The strict one is
+ * Selinger & Valiron's: all variables but those of a !-type are
+ * used exactly once, whatever their type, and A <: !A no longer
+ * holds; a value is made duplicable by promotion only, that is,
+ * by checking it against a !-type (e.g. (λx:!int. x + x) 1).
+ * Top-level declarations are then typed as !-types.
+ */
+package types
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mbivert/golc/syntax"
+)
+
+// Linearity discipline (see above)
+type Linearity int
+
+const (
+	// Only the variables of a linear type are used exactly once
+	ImplicitLinearity Linearity = iota
+
+	// All the variables but those of a !-type are used exactly once
+	StrictLinearity
+)
+
+// true if variables of type t must be used exactly once
+func (l Linearity) linearVar(t syntax.Type) bool {
+	if l == StrictLinearity {
+		_, ok := t.(*syntax.BangType)
+		return !ok && syntax.Known(t)
+	}
+	return isLinear(t)
+}
+
+// true if values of type t can't be duplicated/discarded
+func isLinear(t syntax.Type) bool {
+	switch t.(type) {
+	case *syntax.LinArrowType, *syntax.TensorType:
+		return true
+	case *syntax.ProductType:
+		return isLinear(t.(*syntax.ProductType).Left) || isLinear(t.(*syntax.ProductType).Right)
+	case *syntax.SumType:
+		return isLinear(t.(*syntax.SumType).Left) || isLinear(t.(*syntax.SumType).Right)
+	case *syntax.ForallType:
+		return isLinear(t.(*syntax.ForallType).T)
+	}
+
+	// !A, A → B, primitive types, type variables (see
+	// systemf.go), unknown types
+	return false
+}
+
+// t, deprived of its !s, if any
+func unbang(t syntax.Type) syntax.Type {
+	for {
+		u, ok := t.(*syntax.BangType)
+		if !ok {
+			return t
+		}
+		t = u.T
+	}
+}
+
+// !t, unless t is already a !-type
+func bang(t syntax.Type) syntax.Type {
+	if _, ok := t.(*syntax.BangType); ok {
+		return t
+	}
+	return &syntax.BangType{t}
+}
+
+// t's domain and codomain, if t is an arrow (→ or ⊸)
+func arrowSides(t syntax.Type) (syntax.Type, syntax.Type, bool) {
+	switch t.(type) {
+	case *syntax.ArrowType:
+		return t.(*syntax.ArrowType).Left, t.(*syntax.ArrowType).Right, true
+	case *syntax.LinArrowType:
+		return t.(*syntax.LinArrowType).Left, t.(*syntax.LinArrowType).Right, true
+	}
+	return nil, nil, false
+}
+
+// t's components, if t is a product (× or ⊗)
+func productSides(t syntax.Type) (syntax.Type, syntax.Type, bool) {
+	switch t.(type) {
+	case *syntax.ProductType:
+		return t.(*syntax.ProductType).Left, t.(*syntax.ProductType).Right, true
+	case *syntax.TensorType:
+		return t.(*syntax.TensorType).Left, t.(*syntax.TensorType).Right, true
+	}
+	return nil, nil, false
+}
+
+// true if a value of type a can be used where one of type b is
+// expected (a <: b). Unknown types (syntax.UnknownType) match anything.
+func (l Linearity) subType(a, b syntax.Type) bool {
+	if !syntax.Known(a) || !syntax.Known(b) {
+		return true
+	}
+
+	if v, ok := b.(*syntax.BangType); ok {
+		if u, ok := a.(*syntax.BangType); ok {
+			return l.subType(u.T, v.T)
+		}
+		// non-linear types are implicitly duplicable, unless
+		// linearity is strict
+		return l == ImplicitLinearity && !isLinear(a) && l.subType(a, v.T)
+	}
+	if u, ok := a.(*syntax.BangType); ok {
+		return l.subType(u.T, b)
+	}
+
+	switch a.(type) {
+	case *syntax.ArrowType, *syntax.LinArrowType:
+		// → <: ⊸, but not the other way around
+		if _, ok := a.(*syntax.LinArrowType); ok {
+			if _, ok := b.(*syntax.LinArrowType); !ok {
+				return false
+			}
+		}
+		al, ar, _ := arrowSides(a)
+		bl, br, ok := arrowSides(b)
+		return ok && l.subType(bl, al) && l.subType(ar, br)
+
+	case *syntax.ProductType, *syntax.TensorType:
+		// × <: ⊗, but not the other way around
+		if _, ok := a.(*syntax.TensorType); ok {
+			if _, ok := b.(*syntax.TensorType); !ok {
+				return false
+			}
+		}
+		al, ar, _ := productSides(a)
+		bl, br, ok := productSides(b)
+		return ok && l.subType(al, bl) && l.subType(ar, br)
+
+	case *syntax.SumType:
+		c, ok := b.(*syntax.SumType)
+		return ok && l.subType(a.(*syntax.SumType).Left, c.Left) &&
+			l.subType(a.(*syntax.SumType).Right, c.Right)
+
+	case *syntax.ForallType:
+		c, ok := b.(*syntax.ForallType)
+		if !ok {
+			return false
+		}
+		_, al, bl := forallBodies(a.(*syntax.ForallType), c)
+		return l.subType(al, bl)
+	}
+
+	return syntax.TypeEqual(a, b)
+}
+
+// linear variables' occurrences, by name
+type uses map[string][]syntax.Expr
+
+// u, augmented with v's occurrences
+func (u uses) merge(v uses) uses {
+	for n, xs := range v {
+		u[n] = append(u[n], xs...)
+	}
+	return u
+}
+
+// u's names, sorted (for deterministic errors)
+func (u uses) names() []string {
+	var ns []string
+	for n := range u {
+		ns = append(ns, n)
+	}
+	sort.Strings(ns)
+	return ns
+}
+
+// error at x, with a note pointing at y, if located
+func errAtNote(x, y syntax.Expr, note string, m string, args ...interface{}) error {
+	err := syntax.ErrAt(x, m, args...)
+	if d, ok := err.(*syntax.Diagnostic); ok && y.Span() != nil {
+		d.Notes = append(d.Notes, fmt.Sprintf("%s: %s", y.Span().Start, note))
+	}
+	return err
+}
+
+// Ensure that linear variables are used exactly once in
+// the typed expression x; x's free variables (e.g. top-level
+// declarations) are considered duplicable.
+func checkLinear(x syntax.Expr, l Linearity) error {
+	_, err := l.linear(x, map[string]syntax.Type{})
+	return err
+}
+
+// The occurrences of the linear variables in x, lin
+// holding the types of the bound linear variables.
+func (l Linearity) linear(x syntax.Expr, lin map[string]syntax.Type) (uses, error) {
+	var u, v uses
+	var err error
+
+	switch x.(type) {
+	case *syntax.IntExpr, *syntax.FloatExpr, *syntax.BoolExpr, *syntax.UnitExpr:
+		u = uses{}
+
+	case *syntax.VarExpr:
+		u = uses{}
+		if n := x.(*syntax.VarExpr).Name; lin[n] != nil {
+			u[n] = []syntax.Expr{x}
+		}
+
+	case *syntax.UnaryExpr:
+		u, err = l.linear(x.(*syntax.UnaryExpr).Right, lin)
+
+	case *syntax.BinaryExpr:
+		u, v, err = l.linear2(x.(*syntax.BinaryExpr).Left, x.(*syntax.BinaryExpr).Right, lin)
+		u = u.merge(v)
+
+	case *syntax.AppExpr:
+		u, v, err = l.linear2(x.(*syntax.AppExpr).Left, x.(*syntax.AppExpr).Right, lin)
+		u = u.merge(v)
+
+	case *syntax.ProductExpr:
+		u, v, err = l.linear2(x.(*syntax.ProductExpr).Left, x.(*syntax.ProductExpr).Right, lin)
+		u = u.merge(v)
+
+	case *syntax.InjExpr:
+		u, err = l.linear(x.(*syntax.InjExpr).Right, lin)
+
+	case *syntax.AnnotExpr:
+		u, err = l.linear(x.(*syntax.AnnotExpr).X, lin)
+
+	// types are erased (see systemf.go)
+	case *syntax.TypeAbsExpr:
+		u, err = l.linear(x.(*syntax.TypeAbsExpr).Right, lin)
+
+	case *syntax.TypeAppExpr:
+		u, err = l.linear(x.(*syntax.TypeAppExpr).Left, lin)
+
+	// A → B can be called several times: it can't capture
+	// linear variables (A ⊸ B can).
+	case *syntax.AbsExpr:
+		if u, err = l.bind(x.(*syntax.AbsExpr), 1, lin); err != nil {
+			return nil, err
+		}
+		if _, ok := x.Type().(*syntax.ArrowType); ok && len(u) > 0 {
+			n := u.names()[0]
+			return nil, errAtNote(u[n][0], x, "in this abstraction",
+				"'%s' is linear ('%s'), it can't be captured by a '%s' abstraction (use ⊸)",
+				n, lin[n], x.Type())
+		}
+
+	// let's abstraction is immediately applied: it may
+	// capture linear variables.
+	case *syntax.LetExpr:
+		if u, err = l.linear(x.(*syntax.LetExpr).Left, lin); err != nil {
+			return nil, err
+		}
+		if v, err = l.bind(x.(*syntax.LetExpr).Right.(*syntax.AbsExpr), 1, lin); err != nil {
+			return nil, err
+		}
+		u = u.merge(v)
+
+	case *syntax.LetProductExpr:
+		if u, err = l.linear(x.(*syntax.LetProductExpr).Left, lin); err != nil {
+			return nil, err
+		}
+		v, err = l.bind(x.(*syntax.LetProductExpr).Right.(*syntax.AbsExpr), x.(*syntax.LetProductExpr).N, lin)
+		if err != nil {
+			return nil, err
+		}
+		u = u.merge(v)
+
+	case *syntax.LetUnitExpr:
+		u, v, err = l.linear2(x.(*syntax.LetUnitExpr).Left, x.(*syntax.LetUnitExpr).Right, lin)
+		u = u.merge(v)
+
+	case *syntax.IfExpr:
+		if u, err = l.linear(x.(*syntax.IfExpr).Cond, lin); err != nil {
+			return nil, err
+		}
+		ul, ur, err := l.linear2(x.(*syntax.IfExpr).Left, x.(*syntax.IfExpr).Right, lin)
+		if err != nil {
+			return nil, err
+		}
+		if v, err = branches("if", ul, ur, lin); err != nil {
+			return nil, err
+		}
+		u = u.merge(v)
+
+	case *syntax.MatchExpr:
+		if u, err = l.linear(x.(*syntax.MatchExpr).X, lin); err != nil {
+			return nil, err
+		}
+		ul, err := l.bind(x.(*syntax.MatchExpr).Left.(*syntax.AbsExpr), 1, lin)
+		if err != nil {
+			return nil, err
+		}
+		ur, err := l.bind(x.(*syntax.MatchExpr).Right.(*syntax.AbsExpr), 1, lin)
+		if err != nil {
+			return nil, err
+		}
+		if v, err = branches("match", ul, ur, lin); err != nil {
+			return nil, err
+		}
+		u = u.merge(v)
+
+	default:
+		panic("assert")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// !A can be duplicated: so would its linear variables
+	if _, ok := x.Type().(*syntax.BangType); ok && len(u) > 0 {
+		n := u.names()[0]
+		return nil, errAtNote(u[n][0], x, fmt.Sprintf("in this '%s' expression", x.Type()),
+			"'%s' is linear ('%s'), it can't be duplicated",
+			n, lin[n])
+	}
+
+	return u, nil
+}
+
+// linear() for two sub-expressions
+func (l Linearity) linear2(x, y syntax.Expr, lin map[string]syntax.Type) (uses, uses, error) {
+	u, err := l.linear(x, lin)
+	if err != nil {
+		return nil, nil, err
+	}
+	v, err := l.linear(y, lin)
+	if err != nil {
+		return nil, nil, err
+	}
+	return u, v, nil
+}
+
+// The occurrences of the linear variables of the n
+// nested abstractions starting at a, in their body,
+// each of them being used exactly once.
+func (l Linearity) bind(a *syntax.AbsExpr, n int, lin map[string]syntax.Type) (uses, error) {
+	name, t := a.Name, a.Typ
+
+	// save previous lin[name] if any
+	prev, ok := lin[name]
+	lin[name] = nil
+	if l.linearVar(t) {
+		lin[name] = t
+	}
+
+	var u uses
+	var err error
+	if n == 1 {
+		u, err = l.linear(a.Right, lin)
+	} else {
+		u, err = l.bind(a.Right.(*syntax.AbsExpr), n-1, lin)
+	}
+
+	if ok {
+		lin[name] = prev
+	} else {
+		delete(lin, name)
+	}
+
+	if err != nil || !l.linearVar(t) {
+		return u, err
+	}
+
+	switch xs := u[name]; {
+	case len(xs) == 0:
+		return nil, syntax.ErrAt(a, "'%s' is linear ('%s'), but never used", name, t)
+	case len(xs) > 1:
+		return nil, errAtNote(xs[1], xs[0], "first used here",
+			"'%s' is linear ('%s'), but used more than once", name, t)
+	}
+	delete(u, name)
+
+	return u, nil
+}
+
+// The occurrences of the linear variables used by one of
+// the two branches (l, r) of an if/match, which must use
+// the same linear variables.
+func branches(what string, l, r uses, lin map[string]syntax.Type) (uses, error) {
+	for _, n := range l.names() {
+		if _, ok := r[n]; !ok {
+			return nil, syntax.ErrAt(l[n][0],
+				"'%s' is linear ('%s'), but only used in one of %s's branches",
+				n, lin[n], what)
+		}
+	}
+	for _, n := range r.names() {
+		if _, ok := l[n]; !ok {
+			return nil, syntax.ErrAt(r[n][0],
+				"'%s' is linear ('%s'), but only used in one of %s's branches",
+				n, lin[n], what)
+		}
+	}
+
+	// the most used, so that e.g. using a variable twice in
+	// one branch gets reported.
+	u := uses{}
+	for n, xs := range l {
+		u[n] = xs
+		if len(r[n]) > len(xs) {
+			u[n] = r[n]
+		}
+	}
+	return u, nil
+}
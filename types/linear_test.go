@@ -0,0 +1,330 @@
+package types_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mbivert/ftests"
+
+	"github.com/mbivert/golc/internal/testutil"
+	"github.com/mbivert/golc/syntax"
+	. "github.com/mbivert/golc/types"
+)
+
+func TestLinearSubType(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"dereliction: !A <: A",
+			ImplicitLinearity.SubType,
+			[]any{&syntax.BangType{&syntax.IntType{}}, &syntax.IntType{}},
+			[]any{true},
+		},
+		{
+			"non-linear types are duplicable: int <: !int",
+			ImplicitLinearity.SubType,
+			[]any{&syntax.IntType{}, &syntax.BangType{&syntax.IntType{}}},
+			[]any{true},
+		},
+		{
+			"linear types aren't: int ⊸ int </: !(int ⊸ int)",
+			ImplicitLinearity.SubType,
+			[]any{
+				&syntax.LinArrowType{&syntax.IntType{}, &syntax.IntType{}},
+				&syntax.BangType{&syntax.LinArrowType{&syntax.IntType{}, &syntax.IntType{}}},
+			},
+			[]any{false},
+		},
+		{
+			"int → int <: int ⊸ int",
+			ImplicitLinearity.SubType,
+			[]any{
+				&syntax.ArrowType{&syntax.IntType{}, &syntax.IntType{}},
+				&syntax.LinArrowType{&syntax.IntType{}, &syntax.IntType{}},
+			},
+			[]any{true},
+		},
+		{
+			"int ⊸ int </: int → int",
+			ImplicitLinearity.SubType,
+			[]any{
+				&syntax.LinArrowType{&syntax.IntType{}, &syntax.IntType{}},
+				&syntax.ArrowType{&syntax.IntType{}, &syntax.IntType{}},
+			},
+			[]any{false},
+		},
+		{
+			"contravariant domains: (int ⊸ int) → int <: !(int → int) ⊸ int",
+			ImplicitLinearity.SubType,
+			[]any{
+				&syntax.ArrowType{&syntax.LinArrowType{&syntax.IntType{}, &syntax.IntType{}}, &syntax.IntType{}},
+				&syntax.LinArrowType{
+					&syntax.BangType{&syntax.ArrowType{&syntax.IntType{}, &syntax.IntType{}}},
+					&syntax.IntType{}},
+			},
+			[]any{true},
+		},
+		{
+			"int × bool <: int ⊗ bool",
+			ImplicitLinearity.SubType,
+			[]any{
+				&syntax.ProductType{&syntax.IntType{}, &syntax.BoolType{}},
+				&syntax.TensorType{&syntax.IntType{}, &syntax.BoolType{}},
+			},
+			[]any{true},
+		},
+	})
+}
+
+func TestLinearSType(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"linear identity",
+			sTypeOf,
+			[]any{"λp:int ⊗ int. p"},
+			[]any{"int ⊗ int → int ⊗ int", nil},
+		},
+		{
+			"linear variable used twice",
+			sTypeOf,
+			[]any{"λp:int ⊗ int. 〈p, p〉"},
+			[]any{"", fmt.Errorf("'p' is linear ('int ⊗ int'), but used more than once")},
+		},
+		{
+			"linear variable never used",
+			sTypeOf,
+			[]any{"λf:int ⊸ int. 1"},
+			[]any{"", fmt.Errorf("'f' is linear ('int ⊸ int'), but never used")},
+		},
+		{
+			"!-variables can be used several times",
+			sTypeOf,
+			[]any{"λf:!(int ⊸ int). f (f 1)"},
+			[]any{"!(int ⊸ int) → int", nil},
+		},
+		{
+			"non-linear variables are implicitly duplicable",
+			sTypeOf,
+			[]any{"λx:int. x + x"},
+			[]any{"int → int", nil},
+		},
+		{
+			"an abstraction's domain can be wider than expected",
+			sTypeOf,
+			[]any{"(λf:!int → int. f 1) (λx:int. x)"},
+			[]any{"int", nil},
+		},
+		{
+			"capturing a linear variable makes a linear arrow",
+			sTypeOf,
+			[]any{"λf:int ⊸ int. λx:int. f x"},
+			[]any{"(int ⊸ int) → int ⊸ int", nil},
+		},
+		{
+			"A → B can't capture linear variables",
+			sTypeOf,
+			[]any{"λf:int ⊸ int. (λx. f x : int → int)"},
+			[]any{"", fmt.Errorf("'f' is linear ('int ⊸ int'), it can't be captured by a 'int → int' abstraction (use ⊸)")},
+		},
+		{
+			"promoting a closed term",
+			sTypeOf,
+			[]any{"((λx. x + 1) : !(int ⊸ int))"},
+			[]any{"!(int ⊸ int)", nil},
+		},
+		{
+			"promoting a linear variable",
+			sTypeOf,
+			[]any{"λf:int ⊸ int. ((f) : !(int ⊸ int))"},
+			[]any{"", fmt.Errorf("'f' is linear ('int ⊸ int'), it can't be duplicated")},
+		},
+		{
+			"A → B is an A ⊸ B",
+			sTypeOf,
+			[]any{"(λf:int ⊸ int. f 1) (λx:int. x + 1)"},
+			[]any{"int", nil},
+		},
+		{
+			"A ⊸ B isn't an A → B",
+			sTypeOf,
+			[]any{"λf:int ⊸ int. (λg:int → int. g 1) f"},
+			[]any{"", fmt.Errorf("Expecting 'int → int', got 'int ⊸ int'")},
+		},
+		{
+			"tensors are destructed as products",
+			sTypeOf,
+			[]any{"let p = (〈1, 2〉 : int ⊗ int) in let 〈a, b〉 = p in a + b"},
+			[]any{"int", nil},
+		},
+		{
+			"the components of a linear tensor are linear",
+			sTypeOf,
+			[]any{"λp:(int ⊸ int) ⊗ int. let 〈f, x〉 = p in f (f x)"},
+			[]any{"", fmt.Errorf("'f' is linear ('int ⊸ int'), but used more than once")},
+		},
+		{
+			"the components of a !-tensor are duplicable",
+			sTypeOf,
+			[]any{"λp:!((int ⊸ int) ⊗ int). let 〈f, x〉 = p in f (f x)"},
+			[]any{"!((int ⊸ int) ⊗ int) → int", nil},
+		},
+		{
+			"if's branches use the same linear variables",
+			sTypeOf,
+			[]any{"λp:int ⊗ int. λb:bool. if b then p else 〈1, 2〉"},
+			[]any{"", fmt.Errorf("'p' is linear ('int ⊗ int'), but only used in one of if's branches")},
+		},
+		{
+			"if's branches consume the same linear variables",
+			sTypeOf,
+			[]any{"λp:int ⊗ int. λb:bool. if b then p else let 〈x, y〉 = p in 〈y, x〉"},
+			[]any{"int ⊗ int → bool ⊸ int ⊗ int", nil},
+		},
+		{
+			"match's branches use the same linear variables",
+			sTypeOf,
+			[]any{"λf:int ⊸ int. λs:int ⊕ int. match s with inl n → f n | inr m → m"},
+			[]any{"", fmt.Errorf("'f' is linear ('int ⊸ int'), but only used in one of match's branches")},
+		},
+		{
+			"shadowed linear variable",
+			sTypeOf,
+			[]any{"λf:int ⊸ int. f ((λf:int. f + f) 1)"},
+			[]any{"(int ⊸ int) → int", nil},
+		},
+	})
+}
+
+// Linear typing of s, whose errors are located
+func checkLinearSrc(s string) error {
+	x, err := syntax.Parse(s, "")
+	if err != nil {
+		return err
+	}
+	_, err = InferSType(x)
+	return err
+}
+
+func TestLinearErrors(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"second use, pointing at the first",
+			checkLinearSrc,
+			[]any{"λp:int ⊗ int. 〈p, p〉"},
+			[]any{&syntax.Diagnostic{syntax.SeverityError, "", syntax.Pos{1, 19}, syntax.Pos{1, 20},
+				"'p' is linear ('int ⊗ int'), but used more than once",
+				[]string{"1:16: first used here"}}},
+		},
+		{
+			"capture, pointing at the abstraction",
+			checkLinearSrc,
+			[]any{"λf:int ⊸ int. (λx. f x : int → int)"},
+			[]any{&syntax.Diagnostic{syntax.SeverityError, "", syntax.Pos{1, 20}, syntax.Pos{1, 21},
+				"'f' is linear ('int ⊸ int'), it can't be captured by a 'int → int' abstraction (use ⊸)",
+				[]string{"1:16: in this abstraction"}}},
+		},
+	})
+}
+
+// sTypeOf(), with strict linearity
+func strictTypeOf(s string) (string, error) {
+	x, err := InferSTypeCtx(testutil.MustParse(s), Ctx{}, StrictLinearity)
+	if err != nil {
+		return "", headline(err)
+	}
+	return x.Type().String(), nil
+}
+
+func TestLinearStrict(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"no implicit promotion: int </: !int",
+			StrictLinearity.SubType,
+			[]any{&syntax.IntType{}, &syntax.BangType{&syntax.IntType{}}},
+			[]any{false},
+		},
+		{
+			"dereliction still holds: !int <: int",
+			StrictLinearity.SubType,
+			[]any{&syntax.BangType{&syntax.IntType{}}, &syntax.IntType{}},
+			[]any{true},
+		},
+		{
+			"int variable used once",
+			strictTypeOf,
+			[]any{"λx:int. x + 1"},
+			[]any{"int → int", nil},
+		},
+		{
+			"int variable used twice",
+			strictTypeOf,
+			[]any{"λx:int. x + x"},
+			[]any{"", fmt.Errorf("'x' is linear ('int'), but used more than once")},
+		},
+		{
+			"int variable never used",
+			strictTypeOf,
+			[]any{"λx:int. 1"},
+			[]any{"", fmt.Errorf("'x' is linear ('int'), but never used")},
+		},
+		{
+			"→ variable used twice",
+			strictTypeOf,
+			[]any{"λf:int → int. λx:int. f (f x)"},
+			[]any{"", fmt.Errorf("'f' is linear ('int → int'), but used more than once")},
+		},
+		{
+			"!-variables can be used several times, or not at all",
+			strictTypeOf,
+			[]any{"λx:!int. λy:!int. x + x"},
+			[]any{"!int → !int → int", nil},
+		},
+		{
+			"an abstraction's domain can be wider than expected",
+			strictTypeOf,
+			[]any{"(λf:!int → int. f 1) (λx:int. x)"},
+			[]any{"int", nil},
+		},
+		{
+			"but not narrower",
+			strictTypeOf,
+			[]any{"(λf:int → int. f 1) (λx:!int. x)"},
+			[]any{"", fmt.Errorf("'x' declared as '!int', expecting 'int'")},
+		},
+		{
+			"promotion of a closed argument",
+			strictTypeOf,
+			[]any{"(λx:!int. x + x) (1 + 2)"},
+			[]any{"int", nil},
+		},
+		{
+			"no promotion of a value holding linear variables",
+			strictTypeOf,
+			[]any{"λy:int. (λx:!int. x + x) y"},
+			[]any{"", fmt.Errorf("'y' is linear ('int'), it can't be duplicated")},
+		},
+		{
+			"capturing a linear variable makes a linear abstraction",
+			strictTypeOf,
+			[]any{"λx:int. λy:int. x + y"},
+			[]any{"int → int ⊸ int", nil},
+		},
+		{
+			"let-bound variables are linear",
+			strictTypeOf,
+			[]any{"let x = 1 in x * x"},
+			[]any{"", fmt.Errorf("'x' is linear ('int'), but used more than once")},
+		},
+		{
+			"let-bound variables, promoted",
+			strictTypeOf,
+			[]any{"let x = 1 : !int in x * x"},
+			[]any{"int", nil},
+		},
+		{
+			"if's branches use the same variables",
+			strictTypeOf,
+			[]any{"λb:bool. λx:int. if b then x else 0"},
+			[]any{"", fmt.Errorf("'x' is linear ('int'), but only used in one of if's branches")},
+		},
+	})
+}
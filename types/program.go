@@ -0,0 +1,69 @@
+/*
+ * Programs' typing: each declaration is typed in the context of
+ * the previous ones (see ../syntax/program.go).
+ */
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mbivert/golc/syntax"
+)
+
+// Typecheck each declaration in the context of the previous
+// ones, and then the main expression, whose type is returned
+// (nil if there's none). Declarations' types are updated with
+// the inferred ones. mode is the linearity discipline.
+func checkProgram(q *syntax.Program, mode Linearity) (syntax.Type, error) {
+	ctx := Ctx{}
+
+	for i, d := range q.Decls {
+		// x is checked against the declared type, which may
+		// complete the inferred one (e.g. let b = inl * : unit ⊕ unit)
+		t := d.T
+		if t == nil {
+			t = &syntax.UnknownType{}
+		}
+		x, err := checkSTypeCtx(d.X, t, ctx, mode)
+		if err != nil {
+			return nil, inDecl(d.Name, err)
+		}
+		t = x.Type()
+		if !isTyped(t) {
+			return nil, inDecl(d.Name, syntax.ErrAt(x, "Can't fully type '%s'", x))
+		}
+		q.Decls[i].X, q.Decls[i].T = x, t
+
+		// declarations can be used several times
+		ctx[d.Name] = t
+		if mode == StrictLinearity {
+			ctx[d.Name] = bang(t)
+		}
+	}
+
+	if q.Main == nil {
+		return nil, nil
+	}
+
+	x, err := inferSTypeCtx(q.Main, ctx, mode)
+	if err != nil {
+		return nil, err
+	}
+	if !isTyped(x.Type()) {
+		return nil, syntax.ErrAt(x, "Can't fully type '%s'", x)
+	}
+	q.Main = x
+	return x.Type(), nil
+}
+
+// Error err occured in declaration n: located errors
+// get a note, others a prefix.
+func inDecl(n string, err error) error {
+	var d *syntax.Diagnostic
+	if errors.As(err, &d) {
+		d.Notes = append(d.Notes, fmt.Sprintf("in declaration '%s'", n))
+		return err
+	}
+	return fmt.Errorf("in declaration '%s': %s", n, err)
+}
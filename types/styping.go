@@ -0,0 +1,873 @@
+/*
+ * Inference for a simply typed extended λ-calculus: types are
+ * either synthesized, or checked against an expected type, e.g.
+ * given by an ascription (see checkSTypeCtx()).
+ *
+ * Types are linear or not (see linear.go): !A is accepted where
+ * A is expected, and so is, e.g., A → B where A ⊸ B is expected.
+ * Linearity itself is checked on the typed expression.
+ *
+ * By comparison with typing.go / typing_test.go, which infer
+ * polymorphic (Hindley–Milner) types, without annotations.
+ *
+ * Essentially, the idea is that we don't have to deal with VarType:
+ * the only type variables are System F's (see systemf.go), which
+ * aren't unified, but explicitly abstracted and instantiated.
+ */
+package types
+
+import (
+	"strings"
+
+	"github.com/mbivert/golc/syntax"
+)
+
+// map a bound variable name to its type
+type Ctx map[string]syntax.Type
+
+// Infer the types for a given expression and perform
+// typechecking when relevant.
+//
+// We modify (and return) the expression in place
+// so that it contains the relevant typing data.
+func inferSType(x syntax.Expr) (syntax.Expr, error) {
+	return inferSTypeCtx(x, Ctx{}, ImplicitLinearity)
+}
+
+// Same as inferSType(), where the free variables of x are
+// typed by ctx (e.g. earlier top-level declarations); ctx
+// is left untouched. mode is the linearity discipline.
+func inferSTypeCtx(x syntax.Expr, ctx Ctx, mode Linearity) (syntax.Expr, error) {
+	return checkSTypeCtx(x, &syntax.UnknownType{}, ctx, mode)
+}
+
+// Same as inferSTypeCtx(), where x is checked against t
+// (see check() below).
+//
+// Typing is bidirectional: expressions' types are either
+// synthesized (⇒, bottom-up), or checked against an expected
+// type (⇐, top-down), which allows to omit annotations where
+// the expected type is known (e.g. (λf. f 1 : (int → int) → int)).
+func checkSTypeCtx(x syntax.Expr, t syntax.Type, ctx Ctx, mode Linearity) (syntax.Expr, error) {
+	var synth func(syntax.Expr, Ctx) (syntax.Expr, error)
+	var check func(syntax.Expr, syntax.Type, Ctx) (syntax.Expr, error)
+
+	// names of the type variables so far, from which fresh
+	// ones are picked
+	used := typeVarsExpr(x, freeTypeVars(t, ctxTypeVars(ctx)))
+
+	// type variables in scope: bound by the Λs being typed, or
+	// free in ctx (e.g. bound where ctx comes from)
+	tvs := map[string]int{}
+	for v := range ctxTypeVars(ctx) {
+		tvs[v]++
+	}
+
+	// Rename x's type variable, should it be in avoid
+	rebind := func(x *syntax.TypeAbsExpr, avoid map[string]bool) {
+		if n := x.Name; avoid[n] {
+			x.Name = freshTypeVar(n, used, avoid)
+			used[x.Name] = true
+			x.Right = substTypeExpr(x.Right, n, &syntax.VarType{x.Name})
+		}
+	}
+
+	// Synthesize x's type (⇒)
+	synth = func(x syntax.Expr, ctx Ctx) (syntax.Expr, error) {
+		var err error
+
+		switch x.(type) {
+
+		// Those four cases have already been typed
+		// during the parsing.
+		case *syntax.IntExpr:
+		case *syntax.FloatExpr:
+		case *syntax.BoolExpr:
+		case *syntax.UnitExpr:
+
+		// We may need some typechecking here
+		case *syntax.UnaryExpr:
+			r := x.(*syntax.UnaryExpr).Right
+
+			if r, err = synth(r, ctx); err != nil {
+				return nil, err
+			}
+
+			switch x.(*syntax.UnaryExpr).Op {
+			// Right must be int
+			case syntax.TokenMinus:
+				fallthrough
+			case syntax.TokenPlus:
+				if _, rok := unbang(r.Type()).(*syntax.IntType); !rok {
+					return nil, typeErrAt(x, &syntax.IntType{}, r.Type(), "%s : int → int; got %s",
+						x.(*syntax.UnaryExpr).Op, r.Type(),
+					)
+				}
+				x.SetType(&syntax.IntType{})
+
+			// Right must be float
+			case syntax.TokenFMinus:
+				fallthrough
+			case syntax.TokenFPlus:
+				if _, rok := unbang(r.Type()).(*syntax.FloatType); !rok {
+					return nil, typeErrAt(x, &syntax.FloatType{}, r.Type(), "%s : float → float; got %s",
+						x.(*syntax.UnaryExpr).Op, r.Type(),
+					)
+				}
+				x.SetType(&syntax.FloatType{})
+
+			// Right must be bool
+			case syntax.TokenExcl:
+				if _, rok := unbang(r.Type()).(*syntax.BoolType); !rok {
+					return nil, typeErrAt(x, &syntax.BoolType{}, r.Type(), "%s : bool → bool; got %s",
+						x.(*syntax.UnaryExpr).Op, r.Type(),
+					)
+				}
+				x.SetType(&syntax.BoolType{})
+
+			default:
+				panic("assert")
+			}
+
+		// Again, we may need to typecheck things here
+		case *syntax.BinaryExpr:
+			l := x.(*syntax.BinaryExpr).Left
+			r := x.(*syntax.BinaryExpr).Right
+
+			if l, err = synth(l, ctx); err != nil {
+				return nil, err
+			}
+			if r, err = synth(r, ctx); err != nil {
+				return nil, err
+			}
+
+			// NOTE/TODO: maybe generics can help here
+			// (quick test yields an issue with the SetType(&syntax.UnknownType{}))
+
+			switch x.(*syntax.BinaryExpr).Op {
+			// (int×int) → int
+			case syntax.TokenMinus:
+				fallthrough
+			case syntax.TokenPlus:
+				fallthrough
+			case syntax.TokenStar:
+				fallthrough
+			case syntax.TokenSlash:
+				_, lok := unbang(l.Type()).(*syntax.IntType)
+				_, rok := unbang(r.Type()).(*syntax.IntType)
+				if !lok || !rok {
+					return nil, typeErrAt(x, &syntax.ProductType{&syntax.IntType{}, &syntax.IntType{}}, &syntax.ProductType{l.Type(), r.Type()},
+						"%s : (int×int) → int; got (%s×%s)",
+						x.(*syntax.BinaryExpr).Op, l.Type(), r.Type(),
+					)
+				}
+				x.SetType(&syntax.IntType{})
+
+			// (int×int) → bool
+			case syntax.TokenLessEq:
+				fallthrough
+			case syntax.TokenMoreEq:
+				fallthrough
+			case syntax.TokenLess:
+				fallthrough
+			case syntax.TokenMore:
+				_, lok := unbang(l.Type()).(*syntax.IntType)
+				_, rok := unbang(r.Type()).(*syntax.IntType)
+				if !lok || !rok {
+					return nil, typeErrAt(x, &syntax.ProductType{&syntax.IntType{}, &syntax.IntType{}}, &syntax.ProductType{l.Type(), r.Type()},
+						"%s : (int×int) → bool; got (%s×%s)",
+						x.(*syntax.BinaryExpr).Op, l.Type(), r.Type(),
+					)
+				}
+				x.SetType(&syntax.BoolType{})
+
+			// (float×float) → float
+			case syntax.TokenFMinus:
+				fallthrough
+			case syntax.TokenFPlus:
+				fallthrough
+			case syntax.TokenFStar:
+				fallthrough
+			case syntax.TokenFSlash:
+				_, lok := unbang(l.Type()).(*syntax.FloatType)
+				_, rok := unbang(r.Type()).(*syntax.FloatType)
+				if !lok || !rok {
+					return nil, typeErrAt(x, &syntax.ProductType{&syntax.FloatType{}, &syntax.FloatType{}}, &syntax.ProductType{l.Type(), r.Type()},
+						"%s : (float×float) → float; got (%s×%s)",
+						x.(*syntax.BinaryExpr).Op, l.Type(), r.Type(),
+					)
+				}
+				x.SetType(&syntax.FloatType{})
+
+			// (float×float) → bool
+			case syntax.TokenFLessEq:
+				fallthrough
+			case syntax.TokenFMoreEq:
+				fallthrough
+			case syntax.TokenFLess:
+				fallthrough
+			case syntax.TokenFMore:
+				_, lok := unbang(l.Type()).(*syntax.FloatType)
+				_, rok := unbang(r.Type()).(*syntax.FloatType)
+				if !lok || !rok {
+					return nil, typeErrAt(x, &syntax.ProductType{&syntax.FloatType{}, &syntax.FloatType{}}, &syntax.ProductType{l.Type(), r.Type()},
+						"%s : (float×float) → float; got (%s×%s)",
+						x.(*syntax.BinaryExpr).Op, l.Type(), r.Type(),
+					)
+				}
+				x.SetType(&syntax.BoolType{})
+
+			// Left/right must be bools
+			case syntax.TokenOrOr:
+				fallthrough
+			case syntax.TokenAndAnd:
+				_, lok := unbang(l.Type()).(*syntax.BoolType)
+				_, rok := unbang(r.Type()).(*syntax.BoolType)
+				if !lok || !rok {
+					return nil, typeErrAt(x, &syntax.ProductType{&syntax.BoolType{}, &syntax.BoolType{}}, &syntax.ProductType{l.Type(), r.Type()},
+						"%s : (bool×bool) → bool; got (%s×%s)",
+						x.(*syntax.BinaryExpr).Op, l.Type(), r.Type(),
+					)
+				}
+				x.SetType(&syntax.BoolType{})
+
+			default:
+				panic("assert")
+			}
+
+			x.(*syntax.BinaryExpr).Left = l
+			x.(*syntax.BinaryExpr).Right = r
+
+		// Unannotated abstractions can only be checked
+		case *syntax.AbsExpr:
+			n := x.(*syntax.AbsExpr).Name
+			t := x.(*syntax.AbsExpr).Typ
+			r := x.(*syntax.AbsExpr).Right
+
+			if _, ok := t.(*syntax.UnknownType); ok {
+				return nil, syntax.ErrAt(x, "Can't infer the type of '%s'; annotate it or add an ascription", n)
+			}
+			if err = boundTypeVars(x, t, tvs); err != nil {
+				return nil, err
+			}
+
+			// save previous ctx[n] if any
+			t2, ok := ctx[n]
+
+			// new var in env
+			ctx[n] = t
+
+			if r, err = synth(r, ctx); err != nil {
+				return nil, err
+			}
+			x.(*syntax.AbsExpr).Right = r
+
+			if ok {
+				ctx[n] = t2
+			} else {
+				delete(ctx, n)
+			}
+
+			// capturing linear variables makes it linear
+			x.SetType(&syntax.ArrowType{t, r.Type()})
+			for m := range syntax.FreeVars(x) {
+				if mode.linearVar(ctx[m]) {
+					x.SetType(&syntax.LinArrowType{t, r.Type()})
+				}
+			}
+
+		// The argument is checked against the function's domain,
+		// so that an abstraction can be passed unannotated.
+		case *syntax.AppExpr:
+			l := x.(*syntax.AppExpr).Left
+			r := x.(*syntax.AppExpr).Right
+
+			if l, err = synth(l, ctx); err != nil {
+				return nil, err
+			}
+
+			dom, cod, ok := arrowSides(unbang(l.Type()))
+			if !ok {
+				return nil, typeErrAt(x, nil, l.Type(), "Trying to apply to non-arrow: '%s'", l.Type())
+			}
+
+			if r, err = check(r, dom, ctx); err != nil {
+				return nil, because(err, "applying '%s' ('%s') to '%s'",
+					brief(l), l.Type(), brief(x.(*syntax.AppExpr).Right))
+			}
+
+			x.SetType(cod)
+			x.(*syntax.AppExpr).Left = l
+			x.(*syntax.AppExpr).Right = r
+
+		case *syntax.VarExpr:
+			n := x.(*syntax.VarExpr).Name
+			t, ok := ctx[n]
+			if !ok {
+				return nil, syntax.ErrAt(x, "'%s' isn't bounded!", n)
+			}
+			x.SetType(t)
+			return x, nil
+
+		case *syntax.ProductExpr:
+			l := x.(*syntax.ProductExpr).Left
+			r := x.(*syntax.ProductExpr).Right
+
+			if l, err = synth(l, ctx); err != nil {
+				return nil, err
+			}
+			if r, err = synth(r, ctx); err != nil {
+				return nil, err
+			}
+
+			x.SetType(&syntax.ProductType{l.Type(), r.Type()})
+			x.(*syntax.ProductExpr).Left = l
+			x.(*syntax.ProductExpr).Right = r
+
+		// Their bodies are synthesized as well (see check())
+		case *syntax.LetProductExpr, *syntax.LetExpr, *syntax.LetUnitExpr, *syntax.IfExpr, *syntax.MatchExpr:
+			return check(x, &syntax.UnknownType{}, ctx)
+
+		// The other side of the sum is unknown (see joinType())
+		case *syntax.InjExpr:
+			r := x.(*syntax.InjExpr).Right
+
+			if r, err = synth(r, ctx); err != nil {
+				return nil, err
+			}
+
+			if x.(*syntax.InjExpr).Inr {
+				x.SetType(&syntax.SumType{&syntax.UnknownType{}, r.Type()})
+			} else {
+				x.SetType(&syntax.SumType{r.Type(), &syntax.UnknownType{}})
+			}
+			x.(*syntax.InjExpr).Right = r
+
+		case *syntax.AnnotExpr:
+			y := x.(*syntax.AnnotExpr).X
+
+			if err = boundTypeVars(x, x.(*syntax.AnnotExpr).Typ, tvs); err != nil {
+				return nil, err
+			}
+			if y, err = check(y, x.(*syntax.AnnotExpr).Typ, ctx); err != nil {
+				return nil, because(err, "checking '%s' against its ascription '%s'",
+					brief(x.(*syntax.AnnotExpr).X), x.(*syntax.AnnotExpr).Typ)
+			}
+
+			x.SetType(y.Type())
+			x.(*syntax.AnnotExpr).X = y
+
+		// Λa. M : ∀a. T, where M : T; a is renamed, should it be
+		// free in ctx (e.g. λx:a. Λa. x : a → ∀a1. a).
+		case *syntax.TypeAbsExpr:
+			rebind(x.(*syntax.TypeAbsExpr), ctxTypeVars(ctx))
+			n := x.(*syntax.TypeAbsExpr).Name
+			r := x.(*syntax.TypeAbsExpr).Right
+
+			tvs[n]++
+			r, err = synth(r, ctx)
+			tvs[n]--
+			if err != nil {
+				return nil, err
+			}
+
+			x.SetType(&syntax.ForallType{x.(*syntax.TypeAbsExpr).Name, r.Type()})
+			x.(*syntax.TypeAbsExpr).Right = r
+
+		// M [U] : T[a := U], where M : ∀a. T; U can be any
+		// (non-linear) type, ∀ types included (impredicativity).
+		case *syntax.TypeAppExpr:
+			l := x.(*syntax.TypeAppExpr).Left
+			u := x.(*syntax.TypeAppExpr).Typ
+
+			if err = boundTypeVars(x, u, tvs); err != nil {
+				return nil, err
+			}
+			if l, err = synth(l, ctx); err != nil {
+				return nil, err
+			}
+
+			f, ok := unbang(l.Type()).(*syntax.ForallType)
+			if !ok {
+				return nil, typeErrAt(x, nil, l.Type(), "Can't instantiate '%s' with '%s': not a ∀ type",
+					l.Type(), u)
+			}
+			if isLinear(u) {
+				return nil, syntax.ErrAt(x, "Can't instantiate '%s' with '%s': type variables range over non-linear types",
+					l.Type(), u)
+			}
+
+			x.SetType(applySubst(f.T, Subst{f.Name: u}))
+			x.(*syntax.TypeAppExpr).Left = l
+
+		default:
+			panic("assert")
+		}
+
+		return x, nil
+	}
+
+	// Check x against t (⇐), where t may be (partially) unknown:
+	// t is pushed down to abstractions, whose annotations can then
+	// be omitted, to products' components, injections, and to the
+	// bodies of lets, ifs, matches. Other expressions are
+	// synthesized, and their type compared to t.
+	check = func(x syntax.Expr, t syntax.Type, ctx Ctx) (syntax.Expr, error) {
+		var err error
+
+		// promotion: x is checked against A, and is then
+		// duplicable (see checkLinear())
+		if u, ok := t.(*syntax.BangType); ok {
+			if x, err = check(x, u.T, ctx); err != nil {
+				return nil, err
+			}
+			x.SetType(bang(x.Type()))
+			return x, nil
+		}
+
+		switch x.(type) {
+		// t's kind of arrow (→, ⊸) is kept
+		case *syntax.AbsExpr:
+			dom, cod, ok := arrowSides(t)
+			if !ok {
+				// unannotated: can't be synthesized
+				if _, ok := x.(*syntax.AbsExpr).Typ.(*syntax.UnknownType); ok && isTyped(t) {
+					return nil, typeErrAt(x, t, nil, "Expecting '%s', got an abstraction", t)
+				}
+				break
+			}
+			n := x.(*syntax.AbsExpr).Name
+			if err = boundTypeVars(x, x.(*syntax.AbsExpr).Typ, tvs); err != nil {
+				return nil, err
+			}
+			// the annotation may be a supertype of dom (e.g.
+			// λx:int. x where !int → int is expected)
+			a := x.(*syntax.AbsExpr).Typ
+			if syntax.Known(a) {
+				ok = mode.subType(dom, a)
+			} else {
+				a = dom
+			}
+			if !ok {
+				return nil, typeErrAt(x, dom, x.(*syntax.AbsExpr).Typ, "'%s' declared as '%s', expecting '%s'",
+					n, x.(*syntax.AbsExpr).Typ, dom)
+			}
+			if _, ok := a.(*syntax.UnknownType); ok {
+				return nil, syntax.ErrAt(x, "Can't infer the type of '%s'; annotate it or add an ascription", n)
+			}
+			r := x.(*syntax.AbsExpr).Right
+
+			t2, ok := ctx[n]
+			ctx[n] = a
+			r, err = check(r, cod, ctx)
+			if ok {
+				ctx[n] = t2
+			} else {
+				delete(ctx, n)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := t.(*syntax.LinArrowType); ok {
+				x.SetType(&syntax.LinArrowType{a, r.Type()})
+			} else {
+				x.SetType(&syntax.ArrowType{a, r.Type()})
+			}
+			x.(*syntax.AbsExpr).Typ = a
+			x.(*syntax.AbsExpr).Right = r
+			return x, nil
+
+		// t's kind of product (×, ⊗) is kept
+		case *syntax.ProductExpr:
+			tl, tr, ok := productSides(t)
+			if !ok {
+				break
+			}
+			l := x.(*syntax.ProductExpr).Left
+			r := x.(*syntax.ProductExpr).Right
+
+			if l, err = check(l, tl, ctx); err != nil {
+				return nil, err
+			}
+			if r, err = check(r, tr, ctx); err != nil {
+				return nil, err
+			}
+
+			if _, ok := t.(*syntax.TensorType); ok {
+				x.SetType(&syntax.TensorType{l.Type(), r.Type()})
+			} else {
+				x.SetType(&syntax.ProductType{l.Type(), r.Type()})
+			}
+			x.(*syntax.ProductExpr).Left = l
+			x.(*syntax.ProductExpr).Right = r
+			return x, nil
+
+		// The other side of the sum is t's
+		case *syntax.InjExpr:
+			u, ok := t.(*syntax.SumType)
+			if !ok {
+				break
+			}
+			r := x.(*syntax.InjExpr).Right
+
+			if x.(*syntax.InjExpr).Inr {
+				if r, err = check(r, u.Right, ctx); err != nil {
+					return nil, err
+				}
+				x.SetType(&syntax.SumType{u.Left, r.Type()})
+			} else {
+				if r, err = check(r, u.Left, ctx); err != nil {
+					return nil, err
+				}
+				x.SetType(&syntax.SumType{r.Type(), u.Right})
+			}
+			x.(*syntax.InjExpr).Right = r
+			return x, nil
+
+		// Against ∀b. U: the body is checked against U, where b
+		// is renamed to the abstraction's variable (the latter
+		// being renamed first, should it be captured).
+		case *syntax.TypeAbsExpr:
+			u, ok := t.(*syntax.ForallType)
+			if !ok {
+				break
+			}
+			avoid := ctxTypeVars(ctx)
+			if x.(*syntax.TypeAbsExpr).Name != u.Name {
+				freeTypeVars(u, avoid)
+			}
+			rebind(x.(*syntax.TypeAbsExpr), avoid)
+			n := x.(*syntax.TypeAbsExpr).Name
+			r := x.(*syntax.TypeAbsExpr).Right
+
+			tvs[n]++
+			r, err = check(r, applySubst(u.T, Subst{u.Name: &syntax.VarType{n}}), ctx)
+			tvs[n]--
+			if err != nil {
+				return nil, err
+			}
+
+			x.SetType(&syntax.ForallType{n, r.Type()})
+			x.(*syntax.TypeAbsExpr).Right = r
+			return x, nil
+
+		// The names are bound, by right's abstractions, to the
+		// components of left's type (× or ⊗, the components of a
+		// !-product being duplicable as well); the body's type is
+		// then right's, deprived of its n arguments.
+		case *syntax.LetProductExpr:
+			l := x.(*syntax.LetProductExpr).Left
+			r := x.(*syntax.LetProductExpr).Right
+			n := x.(*syntax.LetProductExpr).N
+
+			if l, err = synth(l, ctx); err != nil {
+				return nil, err
+			}
+
+			u, y := l.Type(), r
+			for i := 0; i < n; i++ {
+				v := u
+				if i < n-1 {
+					pl, pr, ok := productSides(unbang(u))
+					if !ok {
+						ns := x.(*syntax.LetProductExpr).Names()
+						return nil, typeErrAt(l, nil, l.Type(), "Can't match 〈%s〉 to '%s': expecting %d components",
+							strings.Join(ns, ", "), l.Type(), n)
+					}
+					if _, ok := u.(*syntax.BangType); ok {
+						pl, pr = bang(pl), bang(pr)
+					}
+					v, u = pl, pr
+				}
+				y.(*syntax.AbsExpr).Typ = v
+				y = y.(*syntax.AbsExpr).Right
+			}
+
+			// the abstractions are already annotated
+			u = t
+			for i := 0; i < n; i++ {
+				u = &syntax.ArrowType{&syntax.UnknownType{}, u}
+			}
+			if r, err = check(r, u, ctx); err != nil {
+				return nil, err
+			}
+
+			u = r.Type()
+			for i := 0; i < n; i++ {
+				u = u.(*syntax.ArrowType).Right
+			}
+			x.SetType(u)
+			x.(*syntax.LetProductExpr).Left = l
+			x.(*syntax.LetProductExpr).Right = r
+			return x, nil
+
+		// x is bound to left's type, left being checked against
+		// the annotation, if any (e.g. let b = inl * : unit ⊕ unit
+		// in ..., or let f = λx. x + 1 : int → int in ...)
+		case *syntax.LetExpr:
+			l := x.(*syntax.LetExpr).Left
+			r := x.(*syntax.LetExpr).Right
+			a := r.(*syntax.AbsExpr)
+
+			if err = boundTypeVars(x, a.Typ, tvs); err != nil {
+				return nil, err
+			}
+			if l, err = check(l, a.Typ, ctx); err != nil {
+				return nil, because(err, "in the definition of '%s'", a.Name)
+			}
+			a.Typ = l.Type()
+
+			if r, err = check(r, &syntax.ArrowType{&syntax.UnknownType{}, t}, ctx); err != nil {
+				return nil, err
+			}
+
+			x.SetType(r.Type().(*syntax.ArrowType).Right)
+			x.(*syntax.LetExpr).Left = l
+			x.(*syntax.LetExpr).Right = r
+			return x, nil
+
+		case *syntax.LetUnitExpr:
+			l := x.(*syntax.LetUnitExpr).Left
+			r := x.(*syntax.LetUnitExpr).Right
+
+			if l, err = synth(l, ctx); err != nil {
+				return nil, err
+			}
+			if _, ok := unbang(l.Type()).(*syntax.UnitType); !ok {
+				return nil, typeErrAt(l, &syntax.UnitType{}, l.Type(), "Can't match * to '%s'", l.Type())
+			}
+			if r, err = check(r, t, ctx); err != nil {
+				return nil, err
+			}
+
+			x.SetType(r.Type())
+			x.(*syntax.LetUnitExpr).Left = l
+			x.(*syntax.LetUnitExpr).Right = r
+			return x, nil
+
+		// bool condition, branches of the same type
+		case *syntax.IfExpr:
+			c := x.(*syntax.IfExpr).Cond
+			l := x.(*syntax.IfExpr).Left
+			r := x.(*syntax.IfExpr).Right
+
+			if c, err = synth(c, ctx); err != nil {
+				return nil, err
+			}
+			if _, ok := unbang(c.Type()).(*syntax.BoolType); !ok {
+				return nil, typeErrAt(c, &syntax.BoolType{}, c.Type(), "if's condition should be 'bool', got '%s'", c.Type())
+			}
+			if l, err = check(l, t, ctx); err != nil {
+				return nil, err
+			}
+			if r, err = check(r, t, ctx); err != nil {
+				return nil, err
+			}
+			u, ok := branchType(l.Type(), r.Type(), mode)
+			if !ok {
+				return nil, typeErrAt(x, l.Type(), r.Type(), "if's branches have different types: '%s' and '%s'",
+					l.Type(), r.Type())
+			}
+
+			x.SetType(u)
+			x.(*syntax.IfExpr).Cond = c
+			x.(*syntax.IfExpr).Left = l
+			x.(*syntax.IfExpr).Right = r
+			return x, nil
+
+		// The components of a sum are bound, by the branches'
+		// abstractions, to the names; branches of the same type.
+		case *syntax.MatchExpr:
+			m := x.(*syntax.MatchExpr).X
+			l := x.(*syntax.MatchExpr).Left
+			r := x.(*syntax.MatchExpr).Right
+
+			if m, err = synth(m, ctx); err != nil {
+				return nil, err
+			}
+			u, ok := unbang(m.Type()).(*syntax.SumType)
+			if !ok {
+				return nil, typeErrAt(m, nil, m.Type(), "Can't match inl/inr against '%s'", m.Type())
+			}
+
+			// !(A ⊕ B)'s components are duplicable as well
+			l.(*syntax.AbsExpr).Typ = u.Left
+			r.(*syntax.AbsExpr).Typ = u.Right
+			if _, ok := m.Type().(*syntax.BangType); ok {
+				l.(*syntax.AbsExpr).Typ = bang(u.Left)
+				r.(*syntax.AbsExpr).Typ = bang(u.Right)
+			}
+
+			if l, err = check(l, &syntax.ArrowType{&syntax.UnknownType{}, t}, ctx); err != nil {
+				return nil, err
+			}
+			if r, err = check(r, &syntax.ArrowType{&syntax.UnknownType{}, t}, ctx); err != nil {
+				return nil, err
+			}
+
+			lt, rt := l.Type().(*syntax.ArrowType).Right, r.Type().(*syntax.ArrowType).Right
+			v, ok := branchType(lt, rt, mode)
+			if !ok {
+				return nil, typeErrAt(x, lt, rt, "match's branches have different types: '%s' and '%s'",
+					lt, rt)
+			}
+
+			x.SetType(v)
+			x.(*syntax.MatchExpr).X = m
+			x.(*syntax.MatchExpr).Left = l
+			x.(*syntax.MatchExpr).Right = r
+			return x, nil
+		}
+
+		if x, err = synth(x, ctx); err != nil {
+			return nil, err
+		}
+		u, ok := joinType(t, x.Type())
+
+		// e.g. !A where A is expected; t is then
+		// kept, unless it's partially unknown
+		if !ok && mode.subType(x.Type(), t) {
+			u, ok = t, true
+			if !isTyped(t) {
+				u = x.Type()
+			}
+		}
+		if !ok {
+			return nil, typeErrAt(x, t, x.Type(), "Expecting '%s', got '%s'", t, x.Type())
+		}
+		x.SetType(u)
+		return x, nil
+	}
+
+	err := boundTypeVars(x, t, tvs)
+	if err == nil {
+		x, err = check(x, t, ctx)
+	}
+	if err == nil {
+		err = checkLinear(x, mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// true if t is fully known (e.g. the other side of an
+// injection's sum is typed with a syntax.UnknownType)
+func isTyped(t syntax.Type) bool {
+	switch t.(type) {
+	case *syntax.ArrowType:
+		return isTyped(t.(*syntax.ArrowType).Left) && isTyped(t.(*syntax.ArrowType).Right)
+	case *syntax.ProductType:
+		return isTyped(t.(*syntax.ProductType).Left) && isTyped(t.(*syntax.ProductType).Right)
+	case *syntax.SumType:
+		return isTyped(t.(*syntax.SumType).Left) && isTyped(t.(*syntax.SumType).Right)
+	case *syntax.LinArrowType:
+		return isTyped(t.(*syntax.LinArrowType).Left) && isTyped(t.(*syntax.LinArrowType).Right)
+	case *syntax.TensorType:
+		return isTyped(t.(*syntax.TensorType).Left) && isTyped(t.(*syntax.TensorType).Right)
+	case *syntax.BangType:
+		return isTyped(t.(*syntax.BangType).T)
+	case *syntax.ForallType:
+		return isTyped(t.(*syntax.ForallType).T)
+	case *syntax.UnknownType:
+		return false
+	}
+	return t != nil
+}
+
+// a and b, where the unknown parts of either (e.g. the other
+// side of an injection's sum, typed by syntax.UnknownType) are
+// taken from the other; false if they otherwise differ.
+func joinType(a, b syntax.Type) (syntax.Type, bool) {
+	if _, ok := a.(*syntax.UnknownType); ok {
+		return b, true
+	}
+	if _, ok := b.(*syntax.UnknownType); ok {
+		return a, true
+	}
+
+	switch a.(type) {
+	case *syntax.ArrowType:
+		c, ok := b.(*syntax.ArrowType)
+		if !ok {
+			return nil, false
+		}
+		l, lok := joinType(a.(*syntax.ArrowType).Left, c.Left)
+		r, rok := joinType(a.(*syntax.ArrowType).Right, c.Right)
+		return &syntax.ArrowType{l, r}, lok && rok
+
+	case *syntax.ProductType:
+		c, ok := b.(*syntax.ProductType)
+		if !ok {
+			return nil, false
+		}
+		l, lok := joinType(a.(*syntax.ProductType).Left, c.Left)
+		r, rok := joinType(a.(*syntax.ProductType).Right, c.Right)
+		return &syntax.ProductType{l, r}, lok && rok
+
+	case *syntax.SumType:
+		c, ok := b.(*syntax.SumType)
+		if !ok {
+			return nil, false
+		}
+		l, lok := joinType(a.(*syntax.SumType).Left, c.Left)
+		r, rok := joinType(a.(*syntax.SumType).Right, c.Right)
+		return &syntax.SumType{l, r}, lok && rok
+
+	case *syntax.LinArrowType:
+		c, ok := b.(*syntax.LinArrowType)
+		if !ok {
+			return nil, false
+		}
+		l, lok := joinType(a.(*syntax.LinArrowType).Left, c.Left)
+		r, rok := joinType(a.(*syntax.LinArrowType).Right, c.Right)
+		return &syntax.LinArrowType{l, r}, lok && rok
+
+	case *syntax.TensorType:
+		c, ok := b.(*syntax.TensorType)
+		if !ok {
+			return nil, false
+		}
+		l, lok := joinType(a.(*syntax.TensorType).Left, c.Left)
+		r, rok := joinType(a.(*syntax.TensorType).Right, c.Right)
+		return &syntax.TensorType{l, r}, lok && rok
+
+	case *syntax.BangType:
+		c, ok := b.(*syntax.BangType)
+		if !ok {
+			return nil, false
+		}
+		u, ok := joinType(a.(*syntax.BangType).T, c.T)
+		return &syntax.BangType{u}, ok
+
+	// up to the bound variables' names
+	case *syntax.ForallType:
+		c, ok := b.(*syntax.ForallType)
+		if !ok {
+			return nil, false
+		}
+		n, l, r := forallBodies(a.(*syntax.ForallType), c)
+		u, ok := joinType(l, r)
+		return &syntax.ForallType{n, u}, ok
+	}
+
+	return a, syntax.TypeEqual(a, b)
+}
+
+// The type of an if/match with branches of types a and b:
+// joinType(a, b), or the most general of a and b, if one is a
+// subtype of the other (e.g. int ⊗ int for int × int and int ⊗ int).
+func branchType(a, b syntax.Type, l Linearity) (syntax.Type, bool) {
+	if u, ok := joinType(a, b); ok {
+		return u, true
+	}
+	if l.subType(a, b) {
+		return b, true
+	}
+	if l.subType(b, a) {
+		return a, true
+	}
+	return nil, false
+}
@@ -0,0 +1,730 @@
+package types_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mbivert/ftests"
+
+	"github.com/mbivert/golc/internal/testutil"
+	"github.com/mbivert/golc/syntax"
+	. "github.com/mbivert/golc/types"
+)
+
+func TestSTypingInferSTypeSingle(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"x",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("x")},
+			[]any{
+				nil,
+				fmt.Errorf("'x' isn't bounded!"),
+			},
+		},
+		{
+			"42",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("42")},
+			[]any{
+				&syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, 42},
+				nil,
+			},
+		},
+		{
+			"true",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("true")},
+			[]any{
+				&syntax.BoolExpr{syntax.Node{&syntax.BoolType{}, nil}, true},
+				nil,
+			},
+		},
+		{
+			"42.42",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("42.42")},
+			[]any{
+				&syntax.FloatExpr{syntax.Node{&syntax.FloatType{}, nil}, 42.42},
+				nil,
+			},
+		},
+	})
+}
+
+func TestSTypingInferSTypeTypedLambdas(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"λx:bool.*",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("λx:bool.*")},
+			[]any{
+				&syntax.AbsExpr{syntax.Node{&syntax.ArrowType{
+					&syntax.BoolType{},
+					&syntax.UnitType{},
+				}, nil},
+					&syntax.BoolType{},
+					"x",
+					&syntax.UnitExpr{syntax.Node{&syntax.UnitType{}, nil}},
+				},
+				nil,
+			},
+		},
+		{
+			"λx:bool.x",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("λx:bool.x")},
+			[]any{
+				&syntax.AbsExpr{syntax.Node{&syntax.ArrowType{
+					&syntax.BoolType{},
+					&syntax.BoolType{},
+				}, nil},
+					&syntax.BoolType{},
+					"x",
+					&syntax.VarExpr{syntax.Node{&syntax.BoolType{}, nil}, "x"},
+				},
+				nil,
+			},
+		},
+		{
+			"λx:bool.y",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("λx:bool.y")},
+			[]any{
+				nil,
+				fmt.Errorf("'y' isn't bounded!"),
+			},
+		},
+	})
+}
+
+/*
+
+// TODO: in the parsing, we'll want to allow types to
+// be unspecified ("MissingType"). variable MissingType
+// can be recovered e.g. by binary applications. Think:
+//
+//	λx. x+3 can be typed as int → int; the type of x
+//	can be infered when typing x+3
+//
+func TestSTypingInferSTypeUntypedLambdas(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"λx.*",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("λx.*")},
+			[]any{
+				&syntax.AbsExpr{syntax.Node{&syntax.ArrowType{
+					&syntax.MissingType{},
+					&syntax.UnitType{},
+				}, nil},
+					&syntax.BoolType{},
+					"x",
+					&syntax.UnitExpr{syntax.Node{&syntax.UnitType{}, nil}},
+				},
+				nil,
+			},
+		},
+	})
+}
+*/
+
+func TestSTypingInferSTypeApps(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"42 42",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("42 42")},
+			[]any{
+				nil,
+				fmt.Errorf("Trying to apply to non-arrow: 'int'"),
+			},
+		},
+		{
+			"(λx:bool.x) true",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("(λx:bool.x) true")},
+			[]any{
+				&syntax.AppExpr{syntax.Node{&syntax.BoolType{}, nil},
+					&syntax.AbsExpr{syntax.Node{&syntax.ArrowType{
+						&syntax.BoolType{},
+						&syntax.BoolType{},
+					}, nil},
+						&syntax.BoolType{},
+						"x",
+						&syntax.VarExpr{syntax.Node{&syntax.BoolType{}, nil}, "x"},
+					},
+					&syntax.BoolExpr{syntax.Node{&syntax.BoolType{}, nil}, true},
+				},
+				nil,
+			},
+		},
+		{
+			"(λx:bool.x) 42",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("(λx:bool.x) 42")},
+			[]any{
+				nil,
+				fmt.Errorf("Expecting 'bool', got 'int'"),
+			},
+		},
+		// this one's a nightmare; highlights the fact that the left
+		// side of an application isn't always an AbsExpr, just something
+		// which has an ArrowType
+		{
+			"λf:int→int.x:int. f (x+3)",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("λf:int→int.x:int. f (x+3)")},
+			[]any{
+				&syntax.AbsExpr{syntax.Node{&syntax.ArrowType{
+						&syntax.ArrowType{&syntax.IntType{}, &syntax.IntType{}},
+						&syntax.ArrowType{&syntax.IntType{}, &syntax.IntType{}},
+					}, nil},
+					&syntax.ArrowType{&syntax.IntType{}, &syntax.IntType{}},
+					"f",
+					&syntax.AbsExpr{syntax.Node{&syntax.ArrowType{
+							&syntax.IntType{},
+							&syntax.IntType{},
+						}, nil},
+						&syntax.IntType{},
+						"x",
+						&syntax.AppExpr{syntax.Node{&syntax.IntType{}, nil},
+							&syntax.VarExpr{syntax.Node{&syntax.ArrowType{&syntax.IntType{}, &syntax.IntType{}}, nil},
+								"f",
+							},
+						&syntax.BinaryExpr{syntax.Node{&syntax.IntType{}, nil},
+							syntax.TokenPlus,
+							&syntax.VarExpr{syntax.Node{&syntax.IntType{}, nil}, "x"},
+							&syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, 3},
+						},
+					}},
+				},
+				nil,
+			},
+		},
+	})
+}
+
+// TODO: far from extensive
+func TestSTypingInferSTypeExprs(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"3+true",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("3+true")},
+			[]any{
+				nil,
+				fmt.Errorf("+ : (int×int) → int; got (int×bool)"),
+			},
+		},
+		{
+			"3+3",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("3+3")},
+			[]any{
+				&syntax.BinaryExpr{syntax.Node{&syntax.IntType{}, nil},
+					syntax.TokenPlus,
+					&syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, 3},
+					&syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, 3},
+				},
+				nil,
+			},
+		},
+		{
+			"3-.true",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("3-.true")},
+			[]any{
+				nil,
+				fmt.Errorf("-. : (float×float) → float; got (int×bool)"),
+			},
+		},
+		{
+			"3.-.5.",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("3.-.5.")},
+			[]any{
+				&syntax.BinaryExpr{syntax.Node{&syntax.FloatType{}, nil},
+					syntax.TokenFMinus,
+					&syntax.FloatExpr{syntax.Node{&syntax.FloatType{}, nil}, 3.},
+					&syntax.FloatExpr{syntax.Node{&syntax.FloatType{}, nil}, 5.},
+				},
+				nil,
+			},
+		},
+		{
+			"3.&&5.",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("3.&&5.")},
+			[]any{
+				nil,
+				fmt.Errorf("&& : (bool×bool) → bool; got (float×float)"),
+			},
+		},
+		{
+			"true&& false",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("true&& false")},
+			[]any{
+				&syntax.BinaryExpr{syntax.Node{&syntax.BoolType{}, nil},
+					syntax.TokenAndAnd,
+					&syntax.BoolExpr{syntax.Node{&syntax.BoolType{}, nil}, true},
+					&syntax.BoolExpr{syntax.Node{&syntax.BoolType{}, nil}, false},
+				},
+				nil,
+			},
+		},
+		{
+			"3<5",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("3<5")},
+			[]any{
+				&syntax.BinaryExpr{syntax.Node{&syntax.BoolType{}, nil},
+					syntax.TokenLess,
+					&syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, 3},
+					&syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, 5},
+				},
+				nil,
+			},
+		},
+	})
+}
+
+func TestSTypingInferInferSTypeProduct(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"〈3, 3〉",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("〈3, 3〉")},
+			[]any{
+				&syntax.ProductExpr{syntax.Node{&syntax.ProductType{
+					&syntax.IntType{},
+					&syntax.IntType{},
+				}, nil},
+					&syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, 3},
+					&syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, 3},
+				},
+				nil,
+			},
+		},
+		{
+			"〈3, true〉",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("〈3, true〉")},
+			[]any{
+				&syntax.ProductExpr{syntax.Node{&syntax.ProductType{
+					&syntax.IntType{},
+					&syntax.BoolType{},
+				}, nil},
+					&syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, 3},
+					&syntax.BoolExpr{syntax.Node{&syntax.BoolType{}, nil}, true},
+				},
+				nil,
+			},
+		},
+		{
+			"〈3, true, 5.〉",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("〈3, true, 5.〉")},
+			[]any{
+				&syntax.ProductExpr{syntax.Node{&syntax.ProductType{
+					&syntax.IntType{},
+					&syntax.ProductType{
+						&syntax.BoolType{},
+						&syntax.FloatType{},
+					},
+				}, nil},
+					&syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, 3},
+					&syntax.ProductExpr{syntax.Node{&syntax.ProductType{
+						&syntax.BoolType{},
+						&syntax.FloatType{},
+					}, nil},
+						&syntax.BoolExpr{syntax.Node{&syntax.BoolType{}, nil}, true},
+						&syntax.FloatExpr{syntax.Node{&syntax.FloatType{}, nil}, 5.},
+					},
+				},
+				nil,
+			},
+		},
+	})
+}
+
+// TODO: again, not extensive
+func TestSTypingInferInferSTypeUnary(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"+true",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("+true")},
+			[]any{
+				nil,
+				fmt.Errorf("+ : int → int; got bool"),
+			},
+		},
+		{
+			"+.true",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("+.true")},
+			[]any{
+				nil,
+				fmt.Errorf("+. : float → float; got bool"),
+			},
+		},
+		{
+			"+3",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("+3")},
+			[]any{
+				&syntax.UnaryExpr{syntax.Node{&syntax.IntType{}, nil},
+					syntax.TokenPlus,
+					&syntax.IntExpr{syntax.Node{&syntax.IntType{}, nil}, 3},
+				},
+				nil,
+			},
+		},
+		{
+			"-.3.",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("-.3.")},
+			[]any{
+				&syntax.UnaryExpr{syntax.Node{&syntax.FloatType{}, nil},
+					syntax.TokenFMinus,
+					&syntax.FloatExpr{syntax.Node{&syntax.FloatType{}, nil}, 3.},
+				},
+				nil,
+			},
+		},
+		{
+			"!3",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("!3")},
+			[]any{
+				nil,
+				fmt.Errorf("! : bool → bool; got int"),
+			},
+		},
+		{
+			"!true",
+			inferSTypeMsg,
+			[]any{testutil.MustParse("!true")},
+			[]any{
+				&syntax.UnaryExpr{syntax.Node{&syntax.BoolType{}, nil},
+					syntax.TokenExcl,
+					&syntax.BoolExpr{syntax.Node{&syntax.BoolType{}, nil}, true},
+				},
+				nil,
+			},
+		},
+	})
+}
+
+// err's headline if it's a type error, so that tests can focus
+// on the messages (explanations are tested separately)
+func headline(err error) error {
+	if e, ok := err.(*TypeError); ok {
+		return errors.New(e.Message)
+	}
+	return err
+}
+
+// InferSType(), keeping only type errors' headlines
+func inferSTypeMsg(x syntax.Expr) (syntax.Expr, error) {
+	y, err := InferSType(x)
+	return y, headline(err)
+}
+
+// Type of s, as a string
+func sTypeOf(s string) (string, error) {
+	x, err := InferSType(testutil.MustParse(s))
+	if err != nil {
+		return "", headline(err)
+	}
+	return x.Type().String(), nil
+}
+
+func TestSTypingInferSTypeLet(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"type of the bound expression",
+			sTypeOf,
+			[]any{"let x = 1 in x + 1"},
+			[]any{"int", nil},
+		},
+		{
+			"bound function",
+			sTypeOf,
+			[]any{"let f = λx:int. x < 1 in f 2"},
+			[]any{"bool", nil},
+		},
+		{
+			"annotation completing the bound expression's type",
+			sTypeOf,
+			[]any{"let b = inl * : unit ⊕ int in b"},
+			[]any{"unit ⊕ int", nil},
+		},
+		{
+			"annotation mismatch",
+			sTypeOf,
+			[]any{"let x = 1 : bool in x"},
+			[]any{"", fmt.Errorf("Expecting 'bool', got 'int'")},
+		},
+	})
+}
+
+func TestSTypingInferSTypeLetPatterns(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"pair",
+			sTypeOf,
+			[]any{"let 〈x, y〉 = 〈1, true〉 in 〈y, x〉"},
+			[]any{"bool × int", nil},
+		},
+		{
+			"right-nested triple",
+			sTypeOf,
+			[]any{"λp:int × float × bool. let 〈x, y, z〉 = p in z"},
+			[]any{"int × float × bool → bool", nil},
+		},
+		{
+			"fewer names than components",
+			sTypeOf,
+			[]any{"let 〈x, y〉 = 〈1, true, 2.〉 in y"},
+			[]any{"bool × float", nil},
+		},
+		{
+			"names are local to the body",
+			sTypeOf,
+			[]any{"λx:float. 〈let 〈x, y〉 = 〈1, 2〉 in x + y, x〉"},
+			[]any{"float → int × float", nil},
+		},
+		{
+			"too many names",
+			sTypeOf,
+			[]any{"let 〈x, y, z〉 = 〈1, 2〉 in x"},
+			[]any{"", fmt.Errorf("Can't match 〈x, y, z〉 to 'int × int': expecting 3 components")},
+		},
+		{
+			"not a product",
+			sTypeOf,
+			[]any{"let 〈x, y〉 = 1 in x"},
+			[]any{"", fmt.Errorf("Can't match 〈x, y〉 to 'int': expecting 2 components")},
+		},
+		{
+			"let *",
+			sTypeOf,
+			[]any{"λu:unit. let * = u in 1"},
+			[]any{"unit → int", nil},
+		},
+		{
+			"let *, not a unit",
+			sTypeOf,
+			[]any{"let * = 〈*, *〉 in 1"},
+			[]any{"", fmt.Errorf("Can't match * to 'unit × unit'")},
+		},
+	})
+}
+
+func TestSTypingInferSTypeIf(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"if/then/else",
+			sTypeOf,
+			[]any{"λx:int. if x ≤ 0 then 〈x, true〉 else 〈-x, false〉"},
+			[]any{"int → int × bool", nil},
+		},
+		{
+			"condition isn't a bool",
+			sTypeOf,
+			[]any{"if 1 then 2 else 3"},
+			[]any{"", fmt.Errorf("if's condition should be 'bool', got 'int'")},
+		},
+		{
+			"branches of different types",
+			sTypeOf,
+			[]any{"if true then 1 else 1."},
+			[]any{"", fmt.Errorf("if's branches have different types: 'int' and 'float'")},
+		},
+	})
+}
+
+func TestSTypingInferSTypeSums(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"the other side of an injection is unknown",
+			sTypeOf,
+			[]any{"inr 〈1, true〉"},
+			[]any{"? ⊕ int × bool", nil},
+		},
+		{
+			"match",
+			sTypeOf,
+			[]any{"λs:int ⊕ bool. match s with inl n → n | inr b → if b then 1 else 0"},
+			[]any{"int ⊕ bool → int", nil},
+		},
+		{
+			"branches complete each other",
+			sTypeOf,
+			[]any{"λb:bool. if b then inl 1 else inr b"},
+			[]any{"bool → int ⊕ bool", nil},
+		},
+		{
+			"not a sum",
+			sTypeOf,
+			[]any{"match 1 with inl x → x | inr y → y"},
+			[]any{"", fmt.Errorf("Can't match inl/inr against 'int'")},
+		},
+		{
+			"branches of different types",
+			sTypeOf,
+			[]any{"λs:int ⊕ bool. match s with inl n → n | inr b → b"},
+			[]any{"", fmt.Errorf("match's branches have different types: 'int' and 'bool'")},
+		},
+	})
+}
+
+func TestSTypingCheck(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"ascription pushed into an abstraction",
+			sTypeOf,
+			[]any{"(λf. f 1 : (int → int) → int)"},
+			[]any{"(int → int) → int", nil},
+		},
+		{
+			"unannotated argument checked against the domain",
+			sTypeOf,
+			[]any{"(λf:int → int. f 1) (λx. x + 1)"},
+			[]any{"int", nil},
+		},
+		{
+			"arguments' types are compared structurally",
+			sTypeOf,
+			[]any{"λg:int → bool. (λf:int → int. f 1) g"},
+			[]any{"", fmt.Errorf("Expecting 'int → int', got 'int → bool'")},
+		},
+		{
+			"let's annotation pushed into the bound expression",
+			sTypeOf,
+			[]any{"let f = λx. x * 2 : int → int in f 2"},
+			[]any{"int", nil},
+		},
+		{
+			"pushed through products, ifs",
+			sTypeOf,
+			[]any{"(〈λx. x, λb. if b then λy. y else λy. 0〉 : (int → int) × (bool → int → int))"},
+			[]any{"(int → int) × (bool → int → int)", nil},
+		},
+		{
+			"pushed through matches",
+			sTypeOf,
+			[]any{"λs:int ⊕ bool. (match s with inl n → λx. x + n | inr b → λx. x * 2 : int → int)"},
+			[]any{"int ⊕ bool → int → int", nil},
+		},
+		{
+			"completing an injection",
+			sTypeOf,
+			[]any{"(inl 1 : int ⊕ bool)"},
+			[]any{"int ⊕ bool", nil},
+		},
+		{
+			"ascription mismatch",
+			sTypeOf,
+			[]any{"(1 : bool)"},
+			[]any{"", fmt.Errorf("Expecting 'bool', got 'int'")},
+		},
+		{
+			"annotation contradicting the expected type",
+			sTypeOf,
+			[]any{"(λx:bool. 1 : int → int)"},
+			[]any{"", fmt.Errorf("'x' declared as 'bool', expecting 'int'")},
+		},
+		{
+			"ascribed variable",
+			sTypeOf,
+			[]any{"λx:int. (x : int)"},
+			[]any{"int → int", nil},
+		},
+		{
+			"ascribed abstraction, whose body is a variable",
+			sTypeOf,
+			[]any{"(λx. x : int → int)"},
+			[]any{"int → int", nil},
+		},
+		{
+			"ascribed variable, in a checked abstraction",
+			sTypeOf,
+			[]any{"((λx. (x : int)) : int → int)"},
+			[]any{"int → int", nil},
+		},
+		{
+			"applied ascribed variable",
+			sTypeOf,
+			[]any{"λf:int → int. (f : int → int) 1"},
+			[]any{"(int → int) → int", nil},
+		},
+		{
+			"unannotated abstraction, applied",
+			sTypeOf,
+			[]any{"(λx. x) 1"},
+			[]any{"", fmt.Errorf("Can't infer the type of 'x'; annotate it or add an ascription")},
+		},
+		{
+			"unannotated abstraction, nothing to check it against",
+			sTypeOf,
+			[]any{"λx. x + 3"},
+			[]any{"", fmt.Errorf("Can't infer the type of 'x'; annotate it or add an ascription")},
+		},
+		{
+			"unannotated let-bound abstraction",
+			sTypeOf,
+			[]any{"let f = λx. x in f 1"},
+			[]any{"", fmt.Errorf("Can't infer the type of 'x'; annotate it or add an ascription")},
+		},
+		{
+			"abstraction where something else is expected",
+			sTypeOf,
+			[]any{"(λx. 1 : int)"},
+			[]any{"", fmt.Errorf("Expecting 'int', got an abstraction")},
+		},
+	})
+}
+
+// Simple type error for s, with its explanation
+func explainSType(s string) string {
+	_, err := InferSType(testutil.MustParse(s))
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestSTypingExplain(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"argument checked against the domain",
+			explainSType,
+			[]any{"(λx:int. x + 3) true"},
+			[]any{"Expecting 'int', got 'bool'\n" +
+				"\tnote: in 'true'\n" +
+				"\tnote: applying 'λx:int. x + 3' ('int → int') to 'true'"},
+		},
+		{
+			"ascription pushed down",
+			explainSType,
+			[]any{"((λx. x) : int → bool)"},
+			[]any{"Expecting 'bool', got 'int'\n" +
+				"\tnote: in 'x'\n" +
+				"\tnote: checking 'λx. x' against its ascription 'int → bool'"},
+		},
+		{
+			"within a definition",
+			explainSType,
+			[]any{"let f = (λx:int. x) true in f"},
+			[]any{"Expecting 'int', got 'bool'\n" +
+				"\tnote: in 'true'\n" +
+				"\tnote: applying 'λx:int. x' ('int → int') to 'true'\n" +
+				"\tnote: in the definition of 'f'"},
+		},
+	})
+}
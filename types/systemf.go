@@ -0,0 +1,260 @@
+/*
+ * System F, the polymorphic λ-calculus (Girard, Reynolds): types
+ * can be universally quantified (∀a. T), terms abstracted over
+ * types (Λa. M), and applied to types (M [T]).
+ *
+ * Typing is explicit, and checked by styping.go: Λa. M : ∀a. T
+ * if M : T, and M [U] : T[a := U] if M : ∀a. T. Instantiation is
+ * impredicative: U can be any type, including a ∀ type, e.g.
+ *
+ *	λx:∀a. a → a. x [∀a. a → a] x : (∀a. a → a) → (∀a. a → a)
+ *
+ * Type variables must be bound, by a Λ in scope; they range over
+ * non-linear types (see linear.go).
+ * Hindley–Milner inference (typing.go) doesn't handle System F.
+ *
+ * Types don't matter to evaluation: Λ and [T] are erased (see
+ * syntax.Erase()), as ascriptions are, along with the annotations
+ * referring to Λ-bound type variables (Λa. λx:a. x evaluates to
+ * λx. x). Note that System F is strongly normalizing: a fixed-point
+ * combinator (e.g. lib/church.lc's TFP = A A) can't be typed, while
+ * A itself can.
+ */
+package types
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mbivert/golc/syntax"
+)
+
+// m, augmented with t's free type variables
+func freeTypeVars(t syntax.Type, m map[string]bool) map[string]bool {
+	switch t.(type) {
+	case *syntax.VarType:
+		m[t.(*syntax.VarType).Name] = true
+	case *syntax.ArrowType:
+		freeTypeVars(t.(*syntax.ArrowType).Left, m)
+		freeTypeVars(t.(*syntax.ArrowType).Right, m)
+	case *syntax.ProductType:
+		freeTypeVars(t.(*syntax.ProductType).Left, m)
+		freeTypeVars(t.(*syntax.ProductType).Right, m)
+	case *syntax.SumType:
+		freeTypeVars(t.(*syntax.SumType).Left, m)
+		freeTypeVars(t.(*syntax.SumType).Right, m)
+	case *syntax.LinArrowType:
+		freeTypeVars(t.(*syntax.LinArrowType).Left, m)
+		freeTypeVars(t.(*syntax.LinArrowType).Right, m)
+	case *syntax.TensorType:
+		freeTypeVars(t.(*syntax.TensorType).Left, m)
+		freeTypeVars(t.(*syntax.TensorType).Right, m)
+	case *syntax.BangType:
+		freeTypeVars(t.(*syntax.BangType).T, m)
+	case *syntax.ForallType:
+		n := t.(*syntax.ForallType).Name
+		for v := range freeTypeVars(t.(*syntax.ForallType).T, map[string]bool{}) {
+			if v != n {
+				m[v] = true
+			}
+		}
+	}
+	return m
+}
+
+// Ensure that t's free type variables are bound, tvs counting
+// the Λs in scope binding each; t occurs in x.
+func boundTypeVars(x syntax.Expr, t syntax.Type, tvs map[string]int) error {
+	var vs []string
+	for v := range freeTypeVars(t, map[string]bool{}) {
+		if tvs[v] == 0 {
+			vs = append(vs, v)
+		}
+	}
+	if len(vs) == 0 {
+		return nil
+	}
+	sort.Strings(vs)
+	return syntax.ErrAt(x, "Type variable '%s' isn't bound (by a Λ)", vs[0])
+}
+
+// the free type variables of ctx's types
+func ctxTypeVars(ctx Ctx) map[string]bool {
+	m := map[string]bool{}
+	for _, t := range ctx {
+		freeTypeVars(t, m)
+	}
+	return m
+}
+
+// A type variable name, derived from n (a1, a2, etc.),
+// which isn't in any of ms.
+func freshTypeVar(n string, ms ...map[string]bool) string {
+	for i := 1; ; i++ {
+		s := fmt.Sprintf("%s%d", n, i)
+		for _, m := range ms {
+			if m[s] {
+				goto retry
+			}
+		}
+		return s
+
+	retry:
+	}
+}
+
+// a's and b's bodies, where their bound variables are renamed
+// to a common one (returned), so that they can be compared
+// (α-equivalence).
+func forallBodies(a, b *syntax.ForallType) (string, syntax.Type, syntax.Type) {
+	n := a.Name
+	if n != b.Name && freeTypeVars(b, map[string]bool{})[n] {
+		n = freshTypeVar(n, freeTypeVars(a.T, map[string]bool{}),
+			freeTypeVars(b.T, map[string]bool{}))
+	}
+	return n, applySubst(a.T, Subst{a.Name: &syntax.VarType{n}}),
+		applySubst(b.T, Subst{b.Name: &syntax.VarType{n}})
+}
+
+// true if t is, or contains, a ∀ type
+func isPolyType(t syntax.Type) bool {
+	switch t.(type) {
+	case *syntax.ForallType:
+		return true
+	case *syntax.ArrowType:
+		return isPolyType(t.(*syntax.ArrowType).Left) || isPolyType(t.(*syntax.ArrowType).Right)
+	case *syntax.ProductType:
+		return isPolyType(t.(*syntax.ProductType).Left) || isPolyType(t.(*syntax.ProductType).Right)
+	case *syntax.SumType:
+		return isPolyType(t.(*syntax.SumType).Left) || isPolyType(t.(*syntax.SumType).Right)
+	case *syntax.LinArrowType:
+		return isPolyType(t.(*syntax.LinArrowType).Left) || isPolyType(t.(*syntax.LinArrowType).Right)
+	case *syntax.TensorType:
+		return isPolyType(t.(*syntax.TensorType).Left) || isPolyType(t.(*syntax.TensorType).Right)
+	case *syntax.BangType:
+		return isPolyType(t.(*syntax.BangType).T)
+	}
+	return false
+}
+
+// m, augmented with the names of the type variables of x's
+// annotations, be they bound (Λ, ∀) or free.
+func typeVarsExpr(x syntax.Expr, m map[string]bool) map[string]bool {
+	add := func(t syntax.Type) {
+		for _, v := range typeVars(t, nil) {
+			m[v] = true
+		}
+	}
+
+	switch x.(type) {
+	case *syntax.AbsExpr:
+		add(x.(*syntax.AbsExpr).Typ)
+		typeVarsExpr(x.(*syntax.AbsExpr).Right, m)
+	case *syntax.DeBruijnAbsExpr:
+		add(x.(*syntax.DeBruijnAbsExpr).Typ)
+		typeVarsExpr(x.(*syntax.DeBruijnAbsExpr).Right, m)
+	case *syntax.TypeAbsExpr:
+		m[x.(*syntax.TypeAbsExpr).Name] = true
+		typeVarsExpr(x.(*syntax.TypeAbsExpr).Right, m)
+	case *syntax.TypeAppExpr:
+		add(x.(*syntax.TypeAppExpr).Typ)
+		typeVarsExpr(x.(*syntax.TypeAppExpr).Left, m)
+	case *syntax.AnnotExpr:
+		add(x.(*syntax.AnnotExpr).Typ)
+		typeVarsExpr(x.(*syntax.AnnotExpr).X, m)
+	case *syntax.AppExpr:
+		typeVarsExpr(x.(*syntax.AppExpr).Left, m)
+		typeVarsExpr(x.(*syntax.AppExpr).Right, m)
+	case *syntax.UnaryExpr:
+		typeVarsExpr(x.(*syntax.UnaryExpr).Right, m)
+	case *syntax.BinaryExpr:
+		typeVarsExpr(x.(*syntax.BinaryExpr).Left, m)
+		typeVarsExpr(x.(*syntax.BinaryExpr).Right, m)
+	case *syntax.ProductExpr:
+		typeVarsExpr(x.(*syntax.ProductExpr).Left, m)
+		typeVarsExpr(x.(*syntax.ProductExpr).Right, m)
+	case *syntax.LetProductExpr:
+		typeVarsExpr(x.(*syntax.LetProductExpr).Left, m)
+		typeVarsExpr(x.(*syntax.LetProductExpr).Right, m)
+	case *syntax.LetExpr:
+		typeVarsExpr(x.(*syntax.LetExpr).Left, m)
+		typeVarsExpr(x.(*syntax.LetExpr).Right, m)
+	case *syntax.LetUnitExpr:
+		typeVarsExpr(x.(*syntax.LetUnitExpr).Left, m)
+		typeVarsExpr(x.(*syntax.LetUnitExpr).Right, m)
+	case *syntax.IfExpr:
+		typeVarsExpr(x.(*syntax.IfExpr).Cond, m)
+		typeVarsExpr(x.(*syntax.IfExpr).Left, m)
+		typeVarsExpr(x.(*syntax.IfExpr).Right, m)
+	case *syntax.InjExpr:
+		typeVarsExpr(x.(*syntax.InjExpr).Right, m)
+	case *syntax.MatchExpr:
+		typeVarsExpr(x.(*syntax.MatchExpr).X, m)
+		typeVarsExpr(x.(*syntax.MatchExpr).Left, m)
+		typeVarsExpr(x.(*syntax.MatchExpr).Right, m)
+
+	// *VarExpr
+	// *IntExpr
+	// etc.
+	default:
+	}
+	return m
+}
+
+// x[a := t]: the type variable a is replaced by t in x's
+// annotations, in place, up to the Λ's rebinding a. t's
+// variables are expected not to be bound within x (e.g. t
+// is a fresh type variable; see typeVarsExpr()).
+func substTypeExpr(x syntax.Expr, a string, t syntax.Type) syntax.Expr {
+	σ := Subst{a: t}
+
+	switch x.(type) {
+	case *syntax.AbsExpr:
+		x.(*syntax.AbsExpr).Typ = applySubst(x.(*syntax.AbsExpr).Typ, σ)
+		x.(*syntax.AbsExpr).Right = substTypeExpr(x.(*syntax.AbsExpr).Right, a, t)
+	case *syntax.DeBruijnAbsExpr:
+		x.(*syntax.DeBruijnAbsExpr).Typ = applySubst(x.(*syntax.DeBruijnAbsExpr).Typ, σ)
+		x.(*syntax.DeBruijnAbsExpr).Right = substTypeExpr(x.(*syntax.DeBruijnAbsExpr).Right, a, t)
+	case *syntax.TypeAbsExpr:
+		if x.(*syntax.TypeAbsExpr).Name != a {
+			x.(*syntax.TypeAbsExpr).Right = substTypeExpr(x.(*syntax.TypeAbsExpr).Right, a, t)
+		}
+	case *syntax.TypeAppExpr:
+		x.(*syntax.TypeAppExpr).Typ = applySubst(x.(*syntax.TypeAppExpr).Typ, σ)
+		x.(*syntax.TypeAppExpr).Left = substTypeExpr(x.(*syntax.TypeAppExpr).Left, a, t)
+	case *syntax.AnnotExpr:
+		x.(*syntax.AnnotExpr).Typ = applySubst(x.(*syntax.AnnotExpr).Typ, σ)
+		x.(*syntax.AnnotExpr).X = substTypeExpr(x.(*syntax.AnnotExpr).X, a, t)
+	case *syntax.AppExpr:
+		x.(*syntax.AppExpr).Left = substTypeExpr(x.(*syntax.AppExpr).Left, a, t)
+		x.(*syntax.AppExpr).Right = substTypeExpr(x.(*syntax.AppExpr).Right, a, t)
+	case *syntax.UnaryExpr:
+		x.(*syntax.UnaryExpr).Right = substTypeExpr(x.(*syntax.UnaryExpr).Right, a, t)
+	case *syntax.BinaryExpr:
+		x.(*syntax.BinaryExpr).Left = substTypeExpr(x.(*syntax.BinaryExpr).Left, a, t)
+		x.(*syntax.BinaryExpr).Right = substTypeExpr(x.(*syntax.BinaryExpr).Right, a, t)
+	case *syntax.ProductExpr:
+		x.(*syntax.ProductExpr).Left = substTypeExpr(x.(*syntax.ProductExpr).Left, a, t)
+		x.(*syntax.ProductExpr).Right = substTypeExpr(x.(*syntax.ProductExpr).Right, a, t)
+	case *syntax.LetProductExpr:
+		x.(*syntax.LetProductExpr).Left = substTypeExpr(x.(*syntax.LetProductExpr).Left, a, t)
+		x.(*syntax.LetProductExpr).Right = substTypeExpr(x.(*syntax.LetProductExpr).Right, a, t)
+	case *syntax.LetExpr:
+		x.(*syntax.LetExpr).Left = substTypeExpr(x.(*syntax.LetExpr).Left, a, t)
+		x.(*syntax.LetExpr).Right = substTypeExpr(x.(*syntax.LetExpr).Right, a, t)
+	case *syntax.LetUnitExpr:
+		x.(*syntax.LetUnitExpr).Left = substTypeExpr(x.(*syntax.LetUnitExpr).Left, a, t)
+		x.(*syntax.LetUnitExpr).Right = substTypeExpr(x.(*syntax.LetUnitExpr).Right, a, t)
+	case *syntax.IfExpr:
+		x.(*syntax.IfExpr).Cond = substTypeExpr(x.(*syntax.IfExpr).Cond, a, t)
+		x.(*syntax.IfExpr).Left = substTypeExpr(x.(*syntax.IfExpr).Left, a, t)
+		x.(*syntax.IfExpr).Right = substTypeExpr(x.(*syntax.IfExpr).Right, a, t)
+	case *syntax.InjExpr:
+		x.(*syntax.InjExpr).Right = substTypeExpr(x.(*syntax.InjExpr).Right, a, t)
+	case *syntax.MatchExpr:
+		x.(*syntax.MatchExpr).X = substTypeExpr(x.(*syntax.MatchExpr).X, a, t)
+		x.(*syntax.MatchExpr).Left = substTypeExpr(x.(*syntax.MatchExpr).Left, a, t)
+		x.(*syntax.MatchExpr).Right = substTypeExpr(x.(*syntax.MatchExpr).Right, a, t)
+	}
+	return x
+}
@@ -0,0 +1,204 @@
+package types_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mbivert/ftests"
+
+	"github.com/mbivert/golc/internal/testutil"
+	"github.com/mbivert/golc/syntax"
+	. "github.com/mbivert/golc/types"
+)
+
+// Church numerals, System F style
+const sysFNat = "let zero = Λa. λf:a → a. λx:a. x in " +
+	"let succ = λn:∀a. (a → a) → a → a. Λa. λf:a → a. λx:a. f (n [a] f x) in "
+
+// s, parsed as a type
+func mustParseType(s string) syntax.Type {
+	return testutil.MustParse("λx:" + s + ". x").(*syntax.AbsExpr).Typ
+}
+
+func sysFEval(s string) string {
+	return testutil.MustEval(testutil.MustSTypeParse(s)).String()
+}
+
+func TestSystemFSType(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"polymorphic identity",
+			sTypeOf,
+			[]any{"Λa. λx:a. x"},
+			[]any{"∀a. a → a", nil},
+		},
+		{
+			"instantiation",
+			sTypeOf,
+			[]any{"(Λa. λx:a. x) [int] 1"},
+			[]any{"int", nil},
+		},
+		{
+			"nested quantifiers, instantiated in order",
+			sTypeOf,
+			[]any{"λx:∀a. ∀b. a → b. x [int] [bool]"},
+			[]any{"(∀a. ∀b. a → b) → int → bool", nil},
+		},
+		{
+			"let-bound polymorphic value, used at two types",
+			sTypeOf,
+			[]any{"let id = Λa. λx:a. x in 〈id [int] 1, id [bool] true〉"},
+			[]any{"int × bool", nil},
+		},
+		{
+			"impredicative self-application",
+			sTypeOf,
+			[]any{"λx:∀a. a → a. x [∀a. a → a] x"},
+			[]any{"(∀a. a → a) → (∀a. a → a)", nil},
+		},
+		{
+			"lib/church.lc's A (TFP = A A can't be typed)",
+			sTypeOf,
+			[]any{"λx:∀a. a → (int → int) → int. λy:int → int. y (x [∀a. a → (int → int) → int] x y)"},
+			[]any{"(∀a. a → (int → int) → int) → (int → int) → int", nil},
+		},
+		{
+			"Church numerals",
+			sTypeOf,
+			[]any{sysFNat + "succ (succ zero)"},
+			[]any{"∀a. (a → a) → a → a", nil},
+		},
+		{
+			"a Λ doesn't capture the context's type variables",
+			sTypeOf,
+			[]any{"Λa. λx:a. Λa. x"},
+			[]any{"∀a. a → (∀a1. a)", nil},
+		},
+		{
+			"ascription pushed into a Λ, up to ∀'s bound name",
+			sTypeOf,
+			[]any{"((Λa. λx. x) : ∀b. b → b)"},
+			[]any{"∀a. a → a", nil},
+		},
+		{
+			"ascription pushed into a Λ, not capturing",
+			sTypeOf,
+			[]any{"Λb. λz:b. ((Λb. λx:b. z) : ∀a. a → b)"},
+			[]any{"∀b. b → (∀b1. b1 → b)", nil},
+		},
+		{
+			"type variables are opaque",
+			sTypeOf,
+			[]any{"Λa. λx:a. x + 1"},
+			[]any{"", fmt.Errorf("+ : (int×int) → int; got (a×int)")},
+		},
+		{
+			"unbound type variable",
+			sTypeOf,
+			[]any{"λx:a. x"},
+			[]any{"", fmt.Errorf("Type variable 'a' isn't bound (by a Λ)")},
+		},
+		{
+			"unbound type variable, in a Λ",
+			sTypeOf,
+			[]any{"Λa. λx:a. λy:b. x"},
+			[]any{"", fmt.Errorf("Type variable 'b' isn't bound (by a Λ)")},
+		},
+		{
+			"unbound type variable, in an ascription",
+			sTypeOf,
+			[]any{"(λx. x : a → a)"},
+			[]any{"", fmt.Errorf("Type variable 'a' isn't bound (by a Λ)")},
+		},
+		{
+			"unbound type variable, in a let's annotation",
+			sTypeOf,
+			[]any{"let f = λx. x : a → a in f"},
+			[]any{"", fmt.Errorf("Type variable 'a' isn't bound (by a Λ)")},
+		},
+		{
+			"unbound type variable, in a type application",
+			sTypeOf,
+			[]any{"(Λa. λx:a. x) [b]"},
+			[]any{"", fmt.Errorf("Type variable 'b' isn't bound (by a Λ)")},
+		},
+		{
+			"bound by ∀ only within the ∀",
+			sTypeOf,
+			[]any{"λx:(∀a. a) → a. x"},
+			[]any{"", fmt.Errorf("Type variable 'a' isn't bound (by a Λ)")},
+		},
+		{
+			"instantiating a non-∀ type",
+			sTypeOf,
+			[]any{"(λx:int. x) [int]"},
+			[]any{"", fmt.Errorf("Can't instantiate 'int → int' with 'int': not a ∀ type")},
+		},
+		{
+			"instantiating with a linear type",
+			sTypeOf,
+			[]any{"(Λa. λx:a. x) [int ⊸ int]"},
+			[]any{"", fmt.Errorf("Can't instantiate '∀a. a → a' with 'int ⊸ int': type variables range over non-linear types")},
+		},
+	})
+}
+
+func TestSystemFTypeEqual(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"∀ types are equal up to their bound names",
+			syntax.TypeEqual,
+			[]any{mustParseType("∀a. a → a"), mustParseType("∀b. b → b")},
+			[]any{true},
+		},
+		{
+			"bound vs. free",
+			syntax.TypeEqual,
+			[]any{mustParseType("∀a. a → b"), mustParseType("∀b. b → b")},
+			[]any{false},
+		},
+		{
+			"substitution avoids capture",
+			func(s string, u string) string {
+				return ApplySubst(mustParseType(s), Subst{"b": mustParseType(u)}).String()
+			},
+			[]any{"∀a. a → b", "a"},
+			[]any{"∀a1. a1 → a"},
+		},
+	})
+}
+
+func TestSystemFEval(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"Λ and [T] are erased",
+			sysFEval,
+			[]any{"(Λa. λx:a. x) [int] 1"},
+			[]any{"1"},
+		},
+		{
+			"no free type variables are left",
+			sysFEval,
+			[]any{"Λa. λx:a. λy:int. x"},
+			[]any{"λx. λy:int. x"},
+		},
+		{
+			"nor captured ones",
+			sysFEval,
+			[]any{"Λb. (Λa. Λb. λx:a. λy:b. x) [b]"},
+			[]any{"λx. λy. x"},
+		},
+		{
+			"bound by a ∀, they stay",
+			sysFEval,
+			[]any{"(Λa. λf:∀b. b → b. f [a]) [int]"},
+			[]any{"λf:∀b. b → b. f"},
+		},
+		{
+			"Church numerals",
+			sysFEval,
+			[]any{sysFNat + "succ (succ zero) [int] (λx:int. x + 1) 0"},
+			[]any{"2"},
+		},
+	})
+}
@@ -0,0 +1,851 @@
+/*
+ * Hindley–Milner type inference (Algorithm W), with let-polymorphism.
+ *
+ * Abstractions' annotations are optional: unknown types are fresh
+ * type variables (VarType), which unification (mgu()) progressively
+ * substitutes. let-bound variables are generalized over the type
+ * variables which aren't free in the context, and instantiated
+ * with fresh ones on each use.
+ *
+ * System F's explicit polymorphism (∀a. T, Λa. M, M [T]; see
+ * systemf.go) can't be inferred: it's checked by styping.go.
+ */
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mbivert/golc/syntax"
+)
+
+type Subst map[string]syntax.Type
+
+// NOTE/TODO: for now, applySubst operates on types, but we may
+// have to act on typed expressions later on. If so, then perhaps
+// the parsing should pre-fill the types with something reasonable.
+
+// apply the substitution s to x in-place; returns updated x
+func applySubst(t syntax.Type, σ Subst) syntax.Type {
+	switch t.(type) {
+	case *syntax.VarType:
+		v, ok := σ[t.(*syntax.VarType).Name]
+		if ok {
+			return v
+		}
+
+	case *syntax.ArrowType:
+		return &syntax.ArrowType{
+			applySubst(t.(*syntax.ArrowType).Left, σ),
+			applySubst(t.(*syntax.ArrowType).Right, σ),
+		}
+
+	case *syntax.ProductType:
+		return &syntax.ProductType{
+			applySubst(t.(*syntax.ProductType).Left, σ),
+			applySubst(t.(*syntax.ProductType).Right, σ),
+		}
+
+	case *syntax.SumType:
+		return &syntax.SumType{
+			applySubst(t.(*syntax.SumType).Left, σ),
+			applySubst(t.(*syntax.SumType).Right, σ),
+		}
+
+	case *syntax.LinArrowType:
+		return &syntax.LinArrowType{
+			applySubst(t.(*syntax.LinArrowType).Left, σ),
+			applySubst(t.(*syntax.LinArrowType).Right, σ),
+		}
+
+	case *syntax.TensorType:
+		return &syntax.TensorType{
+			applySubst(t.(*syntax.TensorType).Left, σ),
+			applySubst(t.(*syntax.TensorType).Right, σ),
+		}
+
+	case *syntax.BangType:
+		return &syntax.BangType{applySubst(t.(*syntax.BangType).T, σ)}
+
+	// σ doesn't apply to the bound variable, which is renamed
+	// should it capture one of the substituted types' variables.
+	case *syntax.ForallType:
+		n, u := t.(*syntax.ForallType).Name, t.(*syntax.ForallType).T
+		fv := freeTypeVars(u, map[string]bool{})
+
+		τ, ns := make(Subst), map[string]bool{}
+		for m, v := range σ {
+			if m != n && fv[m] {
+				τ[m] = v
+				freeTypeVars(v, ns)
+			}
+		}
+		if ns[n] {
+			m := freshTypeVar(n, ns, fv)
+			τ[n] = &syntax.VarType{m}
+			n = m
+		}
+		return &syntax.ForallType{n, applySubst(u, τ)}
+
+	// "iotas" (unit / primitive types)
+	case *syntax.UnitType:
+	case *syntax.BoolType:
+	case *syntax.IntType:
+	case *syntax.FloatType:
+
+	// yet unknown (see styping.go)
+	case *syntax.UnknownType:
+
+	default:
+		panic("O__o")
+	}
+
+	return t
+}
+
+// τ ∘ ρ, at least for some substitutions
+func composeSubst(τ, ρ Subst) Subst {
+	σ := make(Subst)
+
+	// Import substitution from ρ to σ. In case
+	// of substitutions from ρ which will be altered
+	// by τ, import that of τ directly.
+	for n, t := range ρ {
+		σ[n] = t
+		if v, ok := t.(*syntax.VarType); ok {
+			if w, ok := τ[v.Name]; ok {
+				σ[n] = w
+				continue
+			}
+		}
+
+		// We need to apply τ to the types involved
+		// in ρ to perform the composition
+		if v, ok := t.(*syntax.ArrowType); ok {
+			σ[n] = &syntax.ArrowType{
+				applySubst(v.Left, τ),
+				applySubst(v.Right, τ),
+			}
+		} else if v, ok := t.(*syntax.ProductType); ok {
+			σ[n] = &syntax.ProductType{
+				applySubst(v.Left, τ),
+				applySubst(v.Right, τ),
+			}
+		} else if v, ok := t.(*syntax.SumType); ok {
+			σ[n] = &syntax.SumType{
+				applySubst(v.Left, τ),
+				applySubst(v.Right, τ),
+			}
+		} else {
+			σ[n] = t
+		}
+	}
+
+	for n, t := range τ {
+		_, ok := ρ[n]
+
+		// that substitution doesn't exist in ρ:
+		if !ok {
+			σ[n] = t
+			continue
+		}
+
+		// tricky case: this substitution exists
+		// in ρ and in τ.
+		//
+		// TODO/XXX: let's see how relevant that case
+		// practically is; I fear we'll have to rename/introduce
+		// dummy "free" type names
+		panic("assert")
+	}
+
+	return σ
+}
+
+// returns true if n -- assumed to be a VarType's name --
+// occurs in t
+func occursIn(t syntax.Type, n string) bool {
+	switch t.(type) {
+	case *syntax.VarType:
+		return t.(*syntax.VarType).Name == n
+
+	case *syntax.ArrowType:
+		return occursIn(t.(*syntax.ArrowType).Left, n) ||
+			occursIn(t.(*syntax.ArrowType).Right, n)
+
+	case *syntax.ProductType:
+		return occursIn(t.(*syntax.ProductType).Left, n) ||
+			occursIn(t.(*syntax.ProductType).Right, n)
+
+	case *syntax.SumType:
+		return occursIn(t.(*syntax.SumType).Left, n) ||
+			occursIn(t.(*syntax.SumType).Right, n)
+
+	// "iotas" (unit / primitive types)
+	case *syntax.UnitType:
+	case *syntax.BoolType:
+	case *syntax.IntType:
+	case *syntax.FloatType:
+
+	default:
+		panic("X_x")
+	}
+
+	return false
+}
+
+func mguVarType(t syntax.Type, n string) (Subst, error) {
+	if !occursIn(t, n) {
+		// case 2 / 4
+		return Subst{n: t}, nil
+	} else {
+		// case 3 / 5
+		return nil, typeErrAt(nil, t, &syntax.VarType{n}, "%s occurs in %s", n, t)
+	}
+}
+
+// space shuttle style / DO NOT ATTEMPT TO SIMPLIFY THIS CODE
+func mgu1(a, b syntax.Type) (Subst, error) {
+	if av, ok := a.(*syntax.VarType); ok {
+		if bv, ok := b.(*syntax.VarType); ok {
+			if av.Name == bv.Name {
+				// case 1.
+				// no entry: id() assumed
+				return Subst{}, nil
+			}
+		}
+
+		// case 2 / 3
+		return mguVarType(b, av.Name)
+	}
+
+	if bv, ok := b.(*syntax.VarType); ok {
+		// case 4 / 5
+		return mguVarType(a, bv.Name)
+	}
+
+	if _, ok := a.(*syntax.BoolType); ok {
+		if _, ok := b.(*syntax.BoolType); ok {
+			// case 6
+			return Subst{}, nil
+		}
+	}
+	if _, ok := a.(*syntax.IntType); ok {
+		if _, ok := b.(*syntax.IntType); ok {
+			// case 6
+			return Subst{}, nil
+		}
+	}
+	if _, ok := a.(*syntax.FloatType); ok {
+		if _, ok := b.(*syntax.FloatType); ok {
+			// case 6
+			return Subst{}, nil
+		}
+	}
+
+	if av, ok := a.(*syntax.ArrowType); ok {
+		if bv, ok := b.(*syntax.ArrowType); ok {
+			// case 7
+			σ, err := mgu(
+				[]syntax.Type{av.Left, av.Right},
+				[]syntax.Type{bv.Left, bv.Right},
+			)
+			return σ, because(err, "while unifying '%s' with '%s'", a, b)
+		}
+	}
+	if av, ok := a.(*syntax.ProductType); ok {
+		if bv, ok := b.(*syntax.ProductType); ok {
+			// case 8
+			σ, err := mgu(
+				[]syntax.Type{av.Left, av.Right},
+				[]syntax.Type{bv.Left, bv.Right},
+			)
+			return σ, because(err, "while unifying '%s' with '%s'", a, b)
+		}
+	}
+	if av, ok := a.(*syntax.SumType); ok {
+		if bv, ok := b.(*syntax.SumType); ok {
+			// case 8, for sums
+			σ, err := mgu(
+				[]syntax.Type{av.Left, av.Right},
+				[]syntax.Type{bv.Left, bv.Right},
+			)
+			return σ, because(err, "while unifying '%s' with '%s'", a, b)
+		}
+	}
+
+	// case 9
+	if _, ok := a.(*syntax.UnitType); ok {
+		if _, ok := b.(*syntax.UnitType); ok {
+			return Subst{}, nil
+		}
+	}
+
+	return nil, typeErrAt(nil, a, b, "Cannot unify '%s' with '%s'", a, b)
+}
+
+// Most General Unifier; we're closely following the algorithm
+// description, being verbose on purpose/to reflect it.
+//
+// space shuttle style / DO NOT ATTEMPT TO SIMPLIFY THIS CODE
+func mgu(as, bs []syntax.Type) (Subst, error) {
+	if len(as) != len(bs) {
+		panic("assert")
+	}
+
+	if len(as) == 0 {
+		return Subst{}, nil
+	} else if len(as) == 1 {
+		return mgu1(as[0], bs[0])
+	}
+
+	ρ, err := mgu(as[1:], bs[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	τ, err := mgu1(
+		applySubst(as[0], ρ),
+		applySubst(bs[0], ρ),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return composeSubst(τ, ρ), nil
+}
+
+// A type scheme ∀vars. typ; plain types have no vars.
+// def is the let-bound expression typ comes from, if any
+// (to explain type errors).
+type scheme struct {
+	vars []string
+	typ  syntax.Type
+	def  syntax.Expr
+}
+
+// map a bound variable name to its type scheme
+type schemes map[string]*scheme
+
+// a copy of env, where n is bound to s
+func (env schemes) with(n string, s *scheme) schemes {
+	env2 := make(schemes, len(env)+1)
+	for m, t := range env {
+		env2[m] = t
+	}
+	env2[n] = s
+	return env2
+}
+
+// apply σ to env's types, but not to the schemes' own variables
+func applySubstEnv(env schemes, σ Subst) schemes {
+	env2 := make(schemes, len(env))
+	for n, s := range env {
+		τ := σ
+		if len(s.vars) > 0 {
+			τ = make(Subst)
+			for m, t := range σ {
+				τ[m] = t
+			}
+			for _, v := range s.vars {
+				delete(τ, v)
+			}
+		}
+		env2[n] = &scheme{s.vars, applySubst(s.typ, τ), s.def}
+	}
+	return env2
+}
+
+// append t's type variables, in order of first occurrence,
+// to vs, unless they're already there
+func typeVars(t syntax.Type, vs []string) []string {
+	switch t.(type) {
+	case *syntax.VarType:
+		for _, v := range vs {
+			if v == t.(*syntax.VarType).Name {
+				return vs
+			}
+		}
+		return append(vs, t.(*syntax.VarType).Name)
+
+	case *syntax.ArrowType:
+		return typeVars(t.(*syntax.ArrowType).Right, typeVars(t.(*syntax.ArrowType).Left, vs))
+
+	case *syntax.ProductType:
+		return typeVars(t.(*syntax.ProductType).Right, typeVars(t.(*syntax.ProductType).Left, vs))
+
+	case *syntax.SumType:
+		return typeVars(t.(*syntax.SumType).Right, typeVars(t.(*syntax.SumType).Left, vs))
+
+	case *syntax.LinArrowType:
+		return typeVars(t.(*syntax.LinArrowType).Right, typeVars(t.(*syntax.LinArrowType).Left, vs))
+
+	case *syntax.TensorType:
+		return typeVars(t.(*syntax.TensorType).Right, typeVars(t.(*syntax.TensorType).Left, vs))
+
+	case *syntax.BangType:
+		return typeVars(t.(*syntax.BangType).T, vs)
+
+	// bound variables included (see freeTypeVars())
+	case *syntax.ForallType:
+		return typeVars(t.(*syntax.ForallType).T, typeVars(&syntax.VarType{t.(*syntax.ForallType).Name}, vs))
+	}
+
+	return vs
+}
+
+// the free type variables of env
+func envVars(env schemes) map[string]bool {
+	m := make(map[string]bool)
+	for _, s := range env {
+		for _, v := range typeVars(s.typ, nil) {
+			m[v] = true
+		}
+		for _, v := range s.vars {
+			delete(m, v)
+		}
+	}
+	return m
+}
+
+// ∀-quantify t over its type variables which aren't free in env
+func generalize(env schemes, t syntax.Type) *scheme {
+	fv := envVars(env)
+
+	var vs []string
+	for _, v := range typeVars(t, nil) {
+		if !fv[v] {
+			vs = append(vs, v)
+		}
+	}
+	return &scheme{vs, t, nil}
+}
+
+// Algorithm W's state; fresh type variables are named t0, t1, etc.
+type inferrer struct {
+	n int
+}
+
+func (w *inferrer) fresh() syntax.Type {
+	w.n++
+	return &syntax.VarType{fmt.Sprintf("t%d", w.n-1)}
+}
+
+// s's type, where its variables are replaced by fresh ones
+func (w *inferrer) instantiate(s *scheme) syntax.Type {
+	σ := make(Subst)
+	for _, v := range s.vars {
+		σ[v] = w.fresh()
+	}
+	return applySubst(s.typ, σ)
+}
+
+// an abstraction's annotation, where the unknown parts (e.g.
+// missing annotation) are replaced by fresh type variables.
+//
+// Linearity is ignored: ⊸, ⊗ and !A are read as →, × and A
+// (see styping.go and linear.go for the linear checks).
+func (w *inferrer) annot(t syntax.Type) syntax.Type {
+	switch t.(type) {
+	case *syntax.ArrowType:
+		return &syntax.ArrowType{w.annot(t.(*syntax.ArrowType).Left), w.annot(t.(*syntax.ArrowType).Right)}
+	case *syntax.ProductType:
+		return &syntax.ProductType{w.annot(t.(*syntax.ProductType).Left), w.annot(t.(*syntax.ProductType).Right)}
+	case *syntax.SumType:
+		return &syntax.SumType{w.annot(t.(*syntax.SumType).Left), w.annot(t.(*syntax.SumType).Right)}
+	case *syntax.LinArrowType:
+		return &syntax.ArrowType{w.annot(t.(*syntax.LinArrowType).Left), w.annot(t.(*syntax.LinArrowType).Right)}
+	case *syntax.TensorType:
+		return &syntax.ProductType{w.annot(t.(*syntax.TensorType).Left), w.annot(t.(*syntax.TensorType).Right)}
+	case *syntax.BangType:
+		return w.annot(t.(*syntax.BangType).T)
+	case *syntax.UnknownType, nil:
+		return w.fresh()
+	}
+	return t
+}
+
+// System F's ∀ types, e.g. in x's annotation t, can't be
+// inferred (see styping.go).
+func monoAnnot(x syntax.Expr, t syntax.Type) error {
+	if isPolyType(t) {
+		return syntax.ErrAt(x, "Can't infer System F types ('%s'), only check them", t)
+	}
+	return nil
+}
+
+// mgu(a; b), errors being located at x; a is the expected
+// type (e.g. a function's), b the actual one (its argument's).
+func unify(x syntax.Expr, a, b syntax.Type) (Subst, error) {
+	σ, err := mgu([]syntax.Type{a}, []syntax.Type{b})
+	if err != nil {
+		return nil, err.(*TypeError).at(x)
+	}
+	return σ, nil
+}
+
+// mgu()'s error err, located at x, under the headline m (args):
+// expected and actual are the types given to mgu(). err's own
+// headline is kept in the trace if it's about their sub-types.
+func unifyErr(err error, x syntax.Expr, expected, actual syntax.Type, m string, args ...interface{}) error {
+	e := typeErrAt(x, expected, actual, m, args...)
+	if u := err.(*TypeError); len(u.Trace) > 0 {
+		e.Trace = append([]string{u.Message}, u.Trace...)
+		e.explain()
+	}
+	return e
+}
+
+// operand and result types of a unary operator
+func unaryOpType(op syntax.TokenKind) (syntax.Type, syntax.Type) {
+	switch op {
+	case syntax.TokenMinus, syntax.TokenPlus:
+		return &syntax.IntType{}, &syntax.IntType{}
+	case syntax.TokenFMinus, syntax.TokenFPlus:
+		return &syntax.FloatType{}, &syntax.FloatType{}
+	case syntax.TokenExcl:
+		return &syntax.BoolType{}, &syntax.BoolType{}
+	}
+	panic("assert")
+}
+
+// operands and result types of a binary operator
+func binaryOpType(op syntax.TokenKind) (syntax.Type, syntax.Type) {
+	switch op {
+	case syntax.TokenMinus, syntax.TokenPlus, syntax.TokenStar, syntax.TokenSlash:
+		return &syntax.IntType{}, &syntax.IntType{}
+	case syntax.TokenLessEq, syntax.TokenMoreEq, syntax.TokenLess, syntax.TokenMore:
+		return &syntax.IntType{}, &syntax.BoolType{}
+	case syntax.TokenFMinus, syntax.TokenFPlus, syntax.TokenFStar, syntax.TokenFSlash:
+		return &syntax.FloatType{}, &syntax.FloatType{}
+	case syntax.TokenFLessEq, syntax.TokenFMoreEq, syntax.TokenFLess, syntax.TokenFMore:
+		return &syntax.FloatType{}, &syntax.BoolType{}
+	case syntax.TokenOrOr, syntax.TokenAndAnd:
+		return &syntax.BoolType{}, &syntax.BoolType{}
+	}
+	panic("assert")
+}
+
+// Algorithm W: the substitution σ and type t such that x : t
+// in σ(env). Each returned type has already been substituted.
+func (w *inferrer) infer(x syntax.Expr, env schemes) (Subst, syntax.Type, error) {
+	switch x.(type) {
+	case *syntax.IntExpr:
+		return Subst{}, &syntax.IntType{}, nil
+	case *syntax.FloatExpr:
+		return Subst{}, &syntax.FloatType{}, nil
+	case *syntax.BoolExpr:
+		return Subst{}, &syntax.BoolType{}, nil
+	case *syntax.UnitExpr:
+		return Subst{}, &syntax.UnitType{}, nil
+
+	case *syntax.VarExpr:
+		s, ok := env[x.(*syntax.VarExpr).Name]
+		if !ok {
+			return nil, nil, syntax.ErrAt(x, "'%s' isn't bounded!", x.(*syntax.VarExpr).Name)
+		}
+		return Subst{}, w.instantiate(s), nil
+
+	case *syntax.AbsExpr:
+		if err := monoAnnot(x, x.(*syntax.AbsExpr).Typ); err != nil {
+			return nil, nil, err
+		}
+		a := w.annot(x.(*syntax.AbsExpr).Typ)
+		σ, t, err := w.infer(x.(*syntax.AbsExpr).Right, env.with(x.(*syntax.AbsExpr).Name, &scheme{nil, a, nil}))
+		if err != nil {
+			return nil, nil, err
+		}
+		return σ, &syntax.ArrowType{applySubst(a, σ), t}, nil
+
+	case *syntax.AppExpr:
+		σ1, t1, err := w.infer(x.(*syntax.AppExpr).Left, env)
+		if err != nil {
+			return nil, nil, err
+		}
+		σ2, t2, err := w.infer(x.(*syntax.AppExpr).Right, applySubstEnv(env, σ1))
+		if err != nil {
+			return nil, nil, err
+		}
+		t1, β := applySubst(t1, σ2), w.fresh()
+		σ3, err := unify(x, t1, &syntax.ArrowType{t2, β})
+		if err != nil {
+			l := x.(*syntax.AppExpr).Left
+			err = because(err, "applying '%s' ('%s') to '%s' ('%s')",
+				brief(l), t1, brief(x.(*syntax.AppExpr).Right), t2)
+			if v, ok := l.(*syntax.VarExpr); ok && env[v.Name] != nil && env[v.Name].def != nil {
+				err = because(err, "'%s' defined as '%s'%s", v.Name,
+					brief(env[v.Name].def), located(env[v.Name].def))
+			}
+			return nil, nil, err
+		}
+		return composeSubst(σ3, composeSubst(σ2, σ1)), applySubst(β, σ3), nil
+
+	case *syntax.UnaryExpr:
+		op := x.(*syntax.UnaryExpr).Op
+		σ1, t, err := w.infer(x.(*syntax.UnaryExpr).Right, env)
+		if err != nil {
+			return nil, nil, err
+		}
+		a, r := unaryOpType(op)
+		σ2, err := mgu([]syntax.Type{t}, []syntax.Type{a})
+		if err != nil {
+			return nil, nil, unifyErr(err, x, a, t, "%s : %s → %s; got %s", op, a, r, t)
+		}
+		return composeSubst(σ2, σ1), r, nil
+
+	case *syntax.BinaryExpr:
+		op := x.(*syntax.BinaryExpr).Op
+		σ1, t1, err := w.infer(x.(*syntax.BinaryExpr).Left, env)
+		if err != nil {
+			return nil, nil, err
+		}
+		σ2, t2, err := w.infer(x.(*syntax.BinaryExpr).Right, applySubstEnv(env, σ1))
+		if err != nil {
+			return nil, nil, err
+		}
+		t1 = applySubst(t1, σ2)
+		a, r := binaryOpType(op)
+		σ3, err := mgu([]syntax.Type{t1, t2}, []syntax.Type{a, a})
+		if err != nil {
+			return nil, nil, unifyErr(err, x, &syntax.ProductType{a, a}, &syntax.ProductType{t1, t2},
+				"%s : (%s×%s) → %s; got (%s×%s)", op, a, a, r, t1, t2)
+		}
+		return composeSubst(σ3, composeSubst(σ2, σ1)), r, nil
+
+	case *syntax.ProductExpr:
+		σ1, t1, err := w.infer(x.(*syntax.ProductExpr).Left, env)
+		if err != nil {
+			return nil, nil, err
+		}
+		σ2, t2, err := w.infer(x.(*syntax.ProductExpr).Right, applySubstEnv(env, σ1))
+		if err != nil {
+			return nil, nil, err
+		}
+		return composeSubst(σ2, σ1), &syntax.ProductType{applySubst(t1, σ2), t2}, nil
+
+	// left must be a (right-nested) product of n components; right,
+	// λx1. ... λxn. M, is then applied to them
+	case *syntax.LetProductExpr:
+		l, n := x.(*syntax.LetProductExpr).Left, x.(*syntax.LetProductExpr).N
+		σ1, t1, err := w.infer(l, env)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		vs := make([]syntax.Type, n)
+		for i := range vs {
+			vs[i] = w.fresh()
+		}
+		p := vs[n-1]
+		for i := n - 2; i >= 0; i-- {
+			p = &syntax.ProductType{vs[i], p}
+		}
+		σ2, err := mgu([]syntax.Type{t1}, []syntax.Type{p})
+		if err != nil {
+			ns := x.(*syntax.LetProductExpr).Names()
+			return nil, nil, unifyErr(err, l, p, t1, "Can't match 〈%s〉 to '%s': expecting %d components",
+				strings.Join(ns, ", "), t1, n)
+		}
+		σ := composeSubst(σ2, σ1)
+
+		σ3, t3, err := w.infer(x.(*syntax.LetProductExpr).Right, applySubstEnv(env, σ))
+		if err != nil {
+			return nil, nil, err
+		}
+		σ = composeSubst(σ3, σ)
+
+		β := w.fresh()
+		q := β
+		for i := n - 1; i >= 0; i-- {
+			q = &syntax.ArrowType{applySubst(vs[i], σ), q}
+		}
+		σ4, err := unify(x, q, t3)
+		if err != nil {
+			return nil, nil, err
+		}
+		return composeSubst(σ4, σ), applySubst(β, σ4), nil
+
+	// x is bound to left's generalized type: this is where
+	// polymorphism comes from (let-polymorphism)
+	case *syntax.LetExpr:
+		l, a := x.(*syntax.LetExpr).Left, x.(*syntax.LetExpr).Right.(*syntax.AbsExpr)
+		σ1, t1, err := w.infer(l, env)
+		if err != nil {
+			return nil, nil, because(err, "in the definition of '%s'", a.Name)
+		}
+		if err := monoAnnot(x, a.Typ); err != nil {
+			return nil, nil, err
+		}
+		b := w.annot(a.Typ)
+		σ2, err := mgu([]syntax.Type{b}, []syntax.Type{t1})
+		if err != nil {
+			return nil, nil, unifyErr(err, l, b, t1, "'%s' declared as '%s', got '%s'", a.Name, a.Typ, t1)
+		}
+		σ := composeSubst(σ2, σ1)
+
+		env = applySubstEnv(env, σ)
+		s := generalize(env, applySubst(t1, σ2))
+		s.def = l
+		σ3, t, err := w.infer(a.Right, env.with(a.Name, s))
+		if err != nil {
+			return nil, nil, err
+		}
+		return composeSubst(σ3, σ), t, nil
+
+	case *syntax.LetUnitExpr:
+		l := x.(*syntax.LetUnitExpr).Left
+		σ1, t1, err := w.infer(l, env)
+		if err != nil {
+			return nil, nil, err
+		}
+		σ2, err := mgu([]syntax.Type{t1}, []syntax.Type{&syntax.UnitType{}})
+		if err != nil {
+			return nil, nil, unifyErr(err, l, &syntax.UnitType{}, t1, "Can't match * to '%s'", t1)
+		}
+		σ := composeSubst(σ2, σ1)
+
+		σ3, t, err := w.infer(x.(*syntax.LetUnitExpr).Right, applySubstEnv(env, σ))
+		if err != nil {
+			return nil, nil, err
+		}
+		return composeSubst(σ3, σ), t, nil
+
+	case *syntax.IfExpr:
+		c := x.(*syntax.IfExpr).Cond
+		σ1, t1, err := w.infer(c, env)
+		if err != nil {
+			return nil, nil, err
+		}
+		σ2, err := mgu([]syntax.Type{t1}, []syntax.Type{&syntax.BoolType{}})
+		if err != nil {
+			return nil, nil, unifyErr(err, c, &syntax.BoolType{}, t1, "if's condition should be 'bool', got '%s'", t1)
+		}
+		σ := composeSubst(σ2, σ1)
+
+		σ3, t3, err := w.infer(x.(*syntax.IfExpr).Left, applySubstEnv(env, σ))
+		if err != nil {
+			return nil, nil, err
+		}
+		σ = composeSubst(σ3, σ)
+
+		σ4, t4, err := w.infer(x.(*syntax.IfExpr).Right, applySubstEnv(env, σ))
+		if err != nil {
+			return nil, nil, err
+		}
+		σ = composeSubst(σ4, σ)
+
+		t3 = applySubst(t3, σ4)
+		σ5, err := mgu([]syntax.Type{t3}, []syntax.Type{t4})
+		if err != nil {
+			return nil, nil, unifyErr(err, x, t3, t4, "if's branches have different types: '%s' and '%s'", t3, t4)
+		}
+		return composeSubst(σ5, σ), applySubst(t4, σ5), nil
+
+	// The other side of the sum is a fresh type variable
+	case *syntax.InjExpr:
+		σ, t, err := w.infer(x.(*syntax.InjExpr).Right, env)
+		if err != nil {
+			return nil, nil, err
+		}
+		if x.(*syntax.InjExpr).Inr {
+			return σ, &syntax.SumType{w.fresh(), t}, nil
+		}
+		return σ, &syntax.SumType{t, w.fresh()}, nil
+
+	case *syntax.AnnotExpr:
+		σ1, t1, err := w.infer(x.(*syntax.AnnotExpr).X, env)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := monoAnnot(x, x.(*syntax.AnnotExpr).Typ); err != nil {
+			return nil, nil, err
+		}
+		a := w.annot(x.(*syntax.AnnotExpr).Typ)
+		σ2, err := mgu([]syntax.Type{t1}, []syntax.Type{a})
+		if err != nil {
+			return nil, nil, unifyErr(err, x, a, t1, "Expecting '%s', got '%s'", x.(*syntax.AnnotExpr).Typ, t1)
+		}
+		return composeSubst(σ2, σ1), applySubst(t1, σ2), nil
+
+	// The branches, λy. N and λz. P, are applied to the
+	// sum's components, and must return the same type.
+	case *syntax.MatchExpr:
+		m := x.(*syntax.MatchExpr).X
+		σ1, t1, err := w.infer(m, env)
+		if err != nil {
+			return nil, nil, err
+		}
+		a, b := w.fresh(), w.fresh()
+		σ2, err := mgu([]syntax.Type{t1}, []syntax.Type{&syntax.SumType{a, b}})
+		if err != nil {
+			return nil, nil, unifyErr(err, m, &syntax.SumType{a, b}, t1, "Can't match inl/inr against '%s'", t1)
+		}
+		σ := composeSubst(σ2, σ1)
+
+		σ3, t3, err := w.infer(x.(*syntax.MatchExpr).Left, applySubstEnv(env, σ))
+		if err != nil {
+			return nil, nil, err
+		}
+		σ = composeSubst(σ3, σ)
+
+		σ4, t4, err := w.infer(x.(*syntax.MatchExpr).Right, applySubstEnv(env, σ))
+		if err != nil {
+			return nil, nil, err
+		}
+		σ = composeSubst(σ4, σ)
+
+		β := w.fresh()
+		σ5, err := mgu(
+			[]syntax.Type{
+				&syntax.ArrowType{applySubst(a, σ), β},
+				&syntax.ArrowType{applySubst(b, σ), β},
+			},
+			[]syntax.Type{applySubst(t3, σ4), t4},
+		)
+		if err != nil {
+			return nil, nil, err.(*TypeError).at(x)
+		}
+		return composeSubst(σ5, σ), applySubst(β, σ5), nil
+
+	// likewise for System F's type abstractions/applications
+	case *syntax.TypeAbsExpr, *syntax.TypeAppExpr:
+		return nil, nil, syntax.ErrAt(x, "Can't infer System F terms ('%s'), only check them", brief(x))
+	}
+
+	panic("assert")
+}
+
+// t, where the type variables are renamed a, b, etc., in
+// order of first occurrence.
+func normalizeType(t syntax.Type) syntax.Type {
+	σ := make(Subst)
+	for i, v := range typeVars(t, nil) {
+		n := string(rune('a' + i%26))
+		if i >= 26 {
+			n += fmt.Sprint(i / 26)
+		}
+		σ[v] = &syntax.VarType{n}
+	}
+	return applySubst(t, σ)
+}
+
+// Infer x's principal type (Hindley–Milner, Algorithm W);
+// annotations are optional. For instance:
+//
+//	x                : error: 'x' isn't bounded!
+//	x+3              : error, likewise
+//	λx. x+3          : int → int
+//	λx:int. x+3      : int → int
+//	λx:float. x+3    : error: + : (int×int) → int; got (float×int)
+//	λf. λx. f (f x)  : (a → a) → a → a
+//	let id = λx. x in 〈id 1, id true〉 : int × bool
+//
+// x is left untouched.
+func inferType(x syntax.Expr) (syntax.Type, error) {
+	σ, t, err := (&inferrer{}).infer(x, schemes{})
+	if err != nil {
+		return nil, err
+	}
+	return normalizeType(applySubst(t, σ)), nil
+}
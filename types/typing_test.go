@@ -0,0 +1,931 @@
+package types_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mbivert/ftests"
+
+	"github.com/mbivert/golc/internal/testutil"
+	"github.com/mbivert/golc/syntax"
+	. "github.com/mbivert/golc/types"
+)
+
+func TestTypingApplySubst(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"VarType, empty substitution (id)",
+			ApplySubst,
+			[]any{
+				&syntax.VarType{"A"},
+				Subst{},
+			},
+			[]any{
+				&syntax.VarType{"A"},
+			},
+		},
+		{
+			"VarType, non-empty, non-matching substitution",
+			ApplySubst,
+			[]any{
+				&syntax.VarType{"A"},
+				Subst{"B": &syntax.VarType{"C"}},
+			},
+			[]any{
+				&syntax.VarType{"A"},
+			},
+		},
+		{
+			"VarType, non-empty, matching substitution",
+			ApplySubst,
+			[]any{
+				&syntax.VarType{"A"},
+				Subst{"A": &syntax.VarType{"C"}},
+			},
+			[]any{
+				&syntax.VarType{"C"},
+			},
+		},
+		{
+			"Multi-level ArrowType",
+			ApplySubst,
+			[]any{
+				&syntax.ArrowType{
+					&syntax.VarType{"A"},
+					&syntax.ArrowType{
+						&syntax.VarType{"A"},
+						&syntax.VarType{"B"},
+					},
+				},
+				Subst{"A": &syntax.VarType{"C"}},
+			},
+			[]any{
+				&syntax.ArrowType{
+					&syntax.VarType{"C"},
+					&syntax.ArrowType{
+						&syntax.VarType{"C"},
+						&syntax.VarType{"B"},
+					},
+				},
+			},
+		},
+		{
+			"Multi-level ProductType/ArrowType",
+			ApplySubst,
+			[]any{
+				&syntax.ProductType{
+					&syntax.VarType{"A"},
+					&syntax.ArrowType{
+						&syntax.VarType{"A"},
+						&syntax.VarType{"B"},
+					},
+				},
+				Subst{"A": &syntax.VarType{"C"}},
+			},
+			[]any{
+				&syntax.ProductType{
+					&syntax.VarType{"C"},
+					&syntax.ArrowType{
+						&syntax.VarType{"C"},
+						&syntax.VarType{"B"},
+					},
+				},
+			},
+		},
+		{
+			"Multi-level ProductType/ArrowType, double-substitution",
+			ApplySubst,
+			[]any{
+				&syntax.ProductType{
+					&syntax.VarType{"A"},
+					&syntax.ArrowType{
+						&syntax.VarType{"A"},
+						&syntax.ProductType{
+							&syntax.VarType{"B"},
+							&syntax.VarType{"D"},
+						},
+					},
+				},
+				Subst{
+					"A": &syntax.VarType{"C"},
+					"B": &syntax.VarType{"E"},
+				},
+			},
+			[]any{
+				&syntax.ProductType{
+					&syntax.VarType{"C"},
+					&syntax.ArrowType{
+						&syntax.VarType{"C"},
+						&syntax.ProductType{
+							&syntax.VarType{"E"},
+							&syntax.VarType{"D"},
+						},
+					},
+				},
+			},
+		},
+		{
+			"UnitType: unchanged",
+			ApplySubst,
+			[]any{
+				&syntax.UnitType{},
+				Subst{"A": &syntax.VarType{"C"}},
+			},
+			[]any{
+				&syntax.UnitType{},
+			},
+		},
+		{
+			"BoolType: unchanged",
+			ApplySubst,
+			[]any{
+				&syntax.BoolType{},
+				Subst{"A": &syntax.VarType{"C"}},
+			},
+			[]any{
+				&syntax.BoolType{},
+			},
+		},
+		{
+			"IntType: unchanged",
+			ApplySubst,
+			[]any{
+				&syntax.IntType{},
+				Subst{"A": &syntax.VarType{"C"}},
+			},
+			[]any{
+				&syntax.IntType{},
+			},
+		},
+		{
+			"FloatType: unchanged",
+			ApplySubst,
+			[]any{
+				&syntax.FloatType{},
+				Subst{"A": &syntax.VarType{"C"}},
+			},
+			[]any{
+				&syntax.FloatType{},
+			},
+		},
+	})
+}
+
+func TestTypingOccursIn(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"VarType, no match",
+			OccursIn,
+			[]any{
+				&syntax.VarType{"A"},
+				"B",
+			},
+			[]any{false},
+		},
+		{
+			"VarType, match",
+			OccursIn,
+			[]any{
+				&syntax.VarType{"A"},
+				"A",
+			},
+			[]any{true},
+		},
+		{
+			"ArrowType, match",
+			OccursIn,
+			[]any{
+				&syntax.ArrowType{
+					&syntax.VarType{"A"},
+					&syntax.VarType{"B"},
+				},
+				"A",
+			},
+			[]any{true},
+		},
+		{
+			"ArrowType, no match",
+			OccursIn,
+			[]any{
+				&syntax.ArrowType{
+					&syntax.VarType{"A"},
+					&syntax.VarType{"B"},
+				},
+				"C",
+			},
+			[]any{false},
+		},
+		{
+			"ProductType, match",
+			OccursIn,
+			[]any{
+				&syntax.ProductType{
+					&syntax.VarType{"A"},
+					&syntax.VarType{"B"},
+				},
+				"A",
+			},
+			[]any{true},
+		},
+		{
+			"ProductType, no match",
+			OccursIn,
+			[]any{
+				&syntax.ProductType{
+					&syntax.VarType{"A"},
+					&syntax.VarType{"B"},
+				},
+				"C",
+			},
+			[]any{false},
+		},
+		{
+			"UnitType: never matched",
+			OccursIn,
+			[]any{
+				&syntax.UnitType{},
+				"A",
+			},
+			[]any{false},
+		},
+		{
+			"BoolType: never matched",
+			OccursIn,
+			[]any{
+				&syntax.BoolType{},
+				"A",
+			},
+			[]any{false},
+		},
+		{
+			"IntType: never matched",
+			OccursIn,
+			[]any{
+				&syntax.IntType{},
+				"A",
+			},
+			[]any{false},
+		},
+		{
+			"FloatType: never matched",
+			OccursIn,
+			[]any{
+				&syntax.FloatType{},
+				"A",
+			},
+			[]any{false},
+		},
+	})
+}
+
+// testing mgu1(), but via MGU()
+func TestTypingMgu1(t *testing.T) {
+	var nilSubst Subst
+
+	ftests.Run(t, []ftests.Test{
+		{
+			"Empty input",
+			mguMsg,
+			[]any{[]syntax.Type{}, []syntax.Type{}},
+			[]any{Subst{}, nil},
+		},
+		{
+			"case 1: MGU(X; X) = id",
+			mguMsg,
+			[]any{[]syntax.Type{&syntax.VarType{"X"}}, []syntax.Type{&syntax.VarType{"X"}}},
+			[]any{Subst{}, nil},
+		},
+		{
+			"case 2: MGU(X; B) = [X ↦ B] if X ∉ B",
+			mguMsg,
+			[]any{
+				[]syntax.Type{&syntax.VarType{"X"}},
+				[]syntax.Type{&syntax.VarType{"B"}},
+			},
+			[]any{Subst{
+				"X": &syntax.VarType{"B"},
+			}, nil},
+		},
+		{
+			"case 2: MGU(X; B) = [X ↦ B] if X ∉ B (B is →)",
+			mguMsg,
+			[]any{
+				[]syntax.Type{&syntax.VarType{"X"}},
+				[]syntax.Type{
+					&syntax.ArrowType{
+						&syntax.VarType{"Y"},
+						&syntax.VarType{"Z"},
+					},
+				},
+			},
+			[]any{Subst{
+				"X": &syntax.ArrowType{
+					&syntax.VarType{"Y"},
+					&syntax.VarType{"Z"},
+				},
+			}, nil},
+		},
+		{
+			"case 2: MGU(X; B) = [X ↦ B] if X ∉ B (B is ×, contains →)",
+			mguMsg,
+			[]any{
+				[]syntax.Type{&syntax.VarType{"X"}},
+				[]syntax.Type{
+					&syntax.ProductType{
+						&syntax.VarType{"Y"},
+						&syntax.ArrowType{
+							&syntax.VarType{"Z"},
+							&syntax.VarType{"Z"},
+						},
+					},
+				},
+			},
+			[]any{Subst{
+				"X": &syntax.ProductType{
+					&syntax.VarType{"Y"},
+					&syntax.ArrowType{
+						&syntax.VarType{"Z"},
+						&syntax.VarType{"Z"},
+					},
+				},
+			}, nil},
+		},
+		{
+			"case 2: MGU(X; B) = [X ↦ B] if X ∉ B (B is ι)",
+			mguMsg,
+			[]any{
+				[]syntax.Type{&syntax.VarType{"X"}},
+				[]syntax.Type{&syntax.BoolType{}},
+			},
+			[]any{Subst{
+				"X": &syntax.BoolType{},
+			}, nil},
+		},
+		{
+			"case 3: MGU(X; B) fails if X ∈ B (B is →)",
+			mguMsg,
+			[]any{
+				[]syntax.Type{&syntax.VarType{"X"}},
+				[]syntax.Type{
+					&syntax.ArrowType{
+						&syntax.VarType{"Y"},
+						&syntax.VarType{"X"},
+					},
+				},
+			},
+			[]any{nilSubst, fmt.Errorf("X occurs in Y → X")},
+		},
+		{
+			"case 3: MGU(X; B) fails if X ∈ B (B is ×, contains →)",
+			mguMsg,
+			[]any{
+				[]syntax.Type{&syntax.VarType{"X"}},
+				[]syntax.Type{
+					&syntax.ProductType{
+						&syntax.VarType{"Y"},
+						&syntax.ArrowType{
+							&syntax.VarType{"Z"},
+							&syntax.VarType{"X"},
+						},
+					},
+				},
+			},
+			[]any{nilSubst, fmt.Errorf("X occurs in Y × (Z → X)")},
+		},
+		{
+			"case 4: MGU(A, Y) = [Y ↦ A] if Y ∉ A (A is →)",
+			mguMsg,
+			[]any{
+				[]syntax.Type{
+					&syntax.ArrowType{
+						&syntax.VarType{"Y"},
+						&syntax.VarType{"Z"},
+					},
+				},
+				[]syntax.Type{&syntax.VarType{"A"}},
+			},
+			[]any{Subst{
+				"A": &syntax.ArrowType{
+					&syntax.VarType{"Y"},
+					&syntax.VarType{"Z"},
+				},
+			}, nil},
+		},
+		{
+			"case 4: MGU(A; Y) = [Y ↦ A] if Y ∉ A (A is ×, contains →)",
+			mguMsg,
+			[]any{
+				[]syntax.Type{
+					&syntax.ProductType{
+						&syntax.VarType{"Y"},
+						&syntax.ArrowType{
+							&syntax.VarType{"Z"},
+							&syntax.VarType{"Z"},
+						},
+					},
+				},
+				[]syntax.Type{&syntax.VarType{"A"}},
+			},
+			[]any{Subst{
+				"A": &syntax.ProductType{
+					&syntax.VarType{"Y"},
+					&syntax.ArrowType{
+						&syntax.VarType{"Z"},
+						&syntax.VarType{"Z"},
+					},
+				},
+			}, nil},
+		},
+		{
+			"case 4: MGU(A; Y) = [Y ↦ A] if Y ∉ A (A is ι)",
+			mguMsg,
+			[]any{
+				[]syntax.Type{&syntax.BoolType{}},
+				[]syntax.Type{&syntax.VarType{"A"}},
+			},
+			[]any{Subst{
+				"A": &syntax.BoolType{},
+			}, nil},
+		},
+		{
+			"case 5: MGU(A; Y) fails if Y ∈ A (A is →)",
+			mguMsg,
+			[]any{
+				[]syntax.Type{
+					&syntax.ArrowType{
+						&syntax.VarType{"Y"},
+						&syntax.VarType{"X"},
+					},
+				},
+				[]syntax.Type{&syntax.VarType{"Y"}},
+			},
+			[]any{nilSubst, fmt.Errorf("Y occurs in Y → X")},
+		},
+		{
+			"case 5: MGU(A; Y) fails if Y ∈ A (A is ×, contains →)",
+			mguMsg,
+			[]any{
+				[]syntax.Type{
+					&syntax.ProductType{
+						&syntax.VarType{"Y"},
+						&syntax.ArrowType{
+							&syntax.VarType{"Z"},
+							&syntax.VarType{"X"},
+						},
+					},
+				},
+				[]syntax.Type{&syntax.VarType{"Y"}},
+			},
+			[]any{nilSubst, fmt.Errorf("Y occurs in Y × (Z → X)")},
+		},
+		{
+			"case 6: MGU(bool; bool) = id (ι)",
+			mguMsg,
+			[]any{[]syntax.Type{&syntax.BoolType{}}, []syntax.Type{&syntax.BoolType{}}},
+			[]any{Subst{}, nil},
+		},
+		{
+			"case 6: MGU(int; int) = id (ι)",
+			mguMsg,
+			[]any{[]syntax.Type{&syntax.IntType{}}, []syntax.Type{&syntax.IntType{}}},
+			[]any{Subst{}, nil},
+		},
+		{
+			"case 6: MGU(float; float) = id (ι)",
+			mguMsg,
+			[]any{[]syntax.Type{&syntax.FloatType{}}, []syntax.Type{&syntax.FloatType{}}},
+			[]any{Subst{}, nil},
+		},
+		{
+			"case 9: MGU(*; *) = id (ι)",
+			mguMsg,
+			[]any{[]syntax.Type{&syntax.UnitType{}}, []syntax.Type{&syntax.UnitType{}}},
+			[]any{Subst{}, nil},
+		},
+	})
+}
+
+// TODO: incomplete
+func TestTypingMguFails(t *testing.T) {
+	var nilSubst Subst
+
+	ftests.Run(t, []ftests.Test{
+		{
+			"case 10: MGU(ι, A→B)",
+			mguMsg,
+			[]any{
+				[]syntax.Type{&syntax.BoolType{}},
+				[]syntax.Type{&syntax.ArrowType{
+					&syntax.VarType{"A"},
+					&syntax.VarType{"B"},
+				}},
+			},
+			[]any{nilSubst, fmt.Errorf("Cannot unify 'bool' with 'A → B'")},
+		},
+	})
+}
+
+func TestTypingMgu(t *testing.T) {
+	//	var nilSubst Subst
+
+	ftests.Run(t, []ftests.Test{
+		{
+			"case 7: MGU(bool → B, A → B)",
+			mguMsg,
+			[]any{
+				[]syntax.Type{&syntax.ArrowType{
+					&syntax.BoolType{},
+					&syntax.VarType{"B"},
+				}},
+				[]syntax.Type{&syntax.ArrowType{
+					&syntax.VarType{"A"},
+					&syntax.VarType{"B"},
+				}},
+			},
+			[]any{Subst{"A": &syntax.BoolType{}}, nil},
+		},
+		{
+			"case 7: MGU(X → (X → Y), (Y → Z) → W) (p84)",
+			mguMsg,
+			[]any{
+				[]syntax.Type{&syntax.ArrowType{
+					&syntax.VarType{"X"},
+					&syntax.ArrowType{
+						&syntax.VarType{"X"},
+						&syntax.VarType{"Y"},
+					},
+				}},
+				[]syntax.Type{&syntax.ArrowType{
+					&syntax.ArrowType{
+						&syntax.VarType{"Y"},
+						&syntax.VarType{"Z"},
+					},
+					&syntax.VarType{"W"},
+				}},
+			},
+			[]any{Subst{
+				"X": &syntax.ArrowType{
+					&syntax.VarType{"Y"},
+					&syntax.VarType{"Z"},
+				},
+				"W": &syntax.ArrowType{
+					&syntax.ArrowType{
+						&syntax.VarType{"Y"},
+						&syntax.VarType{"Z"},
+					},
+					&syntax.VarType{"Y"},
+				},
+			}, nil},
+		},
+		{
+			"case 7/8: MGU(X × (X × Y), (Y → Z) × W) (p84, tweaked)",
+			mguMsg,
+			[]any{
+				[]syntax.Type{&syntax.ProductType{
+					&syntax.VarType{"X"},
+					&syntax.ProductType{
+						&syntax.VarType{"X"},
+						&syntax.VarType{"Y"},
+					},
+				}},
+				[]syntax.Type{&syntax.ProductType{
+					&syntax.ArrowType{
+						&syntax.VarType{"Y"},
+						&syntax.VarType{"Z"},
+					},
+					&syntax.VarType{"W"},
+				}},
+			},
+			[]any{Subst{
+				"X": &syntax.ArrowType{
+					&syntax.VarType{"Y"},
+					&syntax.VarType{"Z"},
+				},
+				"W": &syntax.ProductType{
+					&syntax.ArrowType{
+						&syntax.VarType{"Y"},
+						&syntax.VarType{"Z"},
+					},
+					&syntax.VarType{"Y"},
+				},
+			}, nil},
+		},
+		// https://stackoverflow.com/q/65766823
+		//	XXX/TODO: this seems to contradict what was highlighted
+		//	by the previous example; Selinger doesn't talk about
+		//	"simultaneous substitutions" either.
+		{
+			"case 7: MGU(X → (Y → Z), Z → (P → bool)",
+			mguMsg,
+			[]any{
+				[]syntax.Type{&syntax.ArrowType{
+					&syntax.VarType{"X"},
+					&syntax.ArrowType{
+						&syntax.VarType{"Y"},
+						&syntax.VarType{"Z"},
+					},
+				}},
+				[]syntax.Type{&syntax.ArrowType{
+					&syntax.VarType{"Z"},
+					&syntax.ArrowType{
+						&syntax.VarType{"P"},
+						&syntax.BoolType{},
+					},
+				}},
+			},
+			[]any{Subst{
+				"Z": &syntax.BoolType{},
+				"Y": &syntax.VarType{"P"},
+				"X": &syntax.BoolType{},
+			}, nil},
+		},
+	})
+}
+
+// MGU(), keeping only type errors' headlines
+func mguMsg(as, bs []syntax.Type) (Subst, error) {
+	σ, err := MGU(as, bs)
+	return σ, headline(err)
+}
+
+func typeOf(s string) (string, error) {
+	t, err := InferType(testutil.MustParse(s))
+	if err != nil {
+		return "", headline(err)
+	}
+	return t.String(), nil
+}
+
+func TestTypingInferType(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"unbound variable",
+			typeOf,
+			[]any{"x"},
+			[]any{"", fmt.Errorf("'x' isn't bounded!")},
+		},
+		{
+			"identity",
+			typeOf,
+			[]any{"λx. x"},
+			[]any{"a → a", nil},
+		},
+		{
+			"operator constraining its operand",
+			typeOf,
+			[]any{"λx. x+3"},
+			[]any{"int → int", nil},
+		},
+		{
+			"annotation contradicting an operator",
+			typeOf,
+			[]any{"λx:float. x+3"},
+			[]any{"", fmt.Errorf("+ : (int×int) → int; got (float×int)")},
+		},
+		{
+			"K",
+			typeOf,
+			[]any{"λx. λy. x"},
+			[]any{"a → b → a", nil},
+		},
+		{
+			"S",
+			typeOf,
+			[]any{"λx. λy. λz. x z (y z)"},
+			[]any{"(a → b → c) → (a → b) → a → c", nil},
+		},
+		{
+			"self-application",
+			typeOf,
+			[]any{"λx. x x"},
+			[]any{"", fmt.Errorf("t0 occurs in t0 → t1")},
+		},
+		{
+			"applying a non-function",
+			typeOf,
+			[]any{"1 2"},
+			[]any{"", fmt.Errorf("Cannot unify 'int' with 'int → t0'")},
+		},
+		{
+			"products",
+			typeOf,
+			[]any{"λx. λy. 〈y, x, 1〉"},
+			[]any{"a → b → b × a × int", nil},
+		},
+		{
+			"taking products apart",
+			typeOf,
+			[]any{"λp. let 〈x, y〉 = p in 〈y, x〉"},
+			[]any{"a × b → b × a", nil},
+		},
+		{
+			"fewer names than components",
+			typeOf,
+			[]any{"let 〈x, y〉 = 〈1, true, 2.〉 in y"},
+			[]any{"bool × float", nil},
+		},
+		{
+			"not a product",
+			typeOf,
+			[]any{"let 〈x, y〉 = 1 in x"},
+			[]any{"", fmt.Errorf("Can't match 〈x, y〉 to 'int': expecting 2 components")},
+		},
+		{
+			"let *",
+			typeOf,
+			[]any{"λu. let * = u in u"},
+			[]any{"unit → unit", nil},
+		},
+		{
+			"if/then/else",
+			typeOf,
+			[]any{"λb. λx. λy. if b then x else y"},
+			[]any{"bool → a → a → a", nil},
+		},
+		{
+			"branches of different types",
+			typeOf,
+			[]any{"if true then 1 else 1."},
+			[]any{"", fmt.Errorf("if's branches have different types: 'int' and 'float'")},
+		},
+		{
+			"injections",
+			typeOf,
+			[]any{"λx. inl x"},
+			[]any{"a → a ⊕ b", nil},
+		},
+		{
+			"match",
+			typeOf,
+			[]any{"λs. match s with inl n → n + 1 | inr b → if b then 1 else 0"},
+			[]any{"int ⊕ bool → int", nil},
+		},
+		{
+			"ascription",
+			typeOf,
+			[]any{"λx. ((x) : int)"},
+			[]any{"int → int", nil},
+		},
+		{
+			"linearity is ignored",
+			typeOf,
+			[]any{"λf:!(int ⊸ int). λp:int ⊗ int. f (f 1)"},
+			[]any{"(int → int) → int × int → int", nil},
+		},
+		{
+			"ascription mismatch",
+			typeOf,
+			[]any{"(λx. x + 1 : bool → int)"},
+			[]any{"", fmt.Errorf("Expecting 'bool → int', got 'int → int'")},
+		},
+		{
+			"not a sum",
+			typeOf,
+			[]any{"match 1 with inl x → x | inr y → y"},
+			[]any{"", fmt.Errorf("Can't match inl/inr against 'int'")},
+		},
+		{
+			"System F terms are only checked",
+			typeOf,
+			[]any{"(Λa. λx:a. x) [int] 1"},
+			[]any{"", fmt.Errorf("Can't infer System F terms ('(Λa. λx:a. x) [int]'), only check them")},
+		},
+		{
+			"System F types are only checked",
+			typeOf,
+			[]any{"λx:∀a. a → a. x"},
+			[]any{"", fmt.Errorf("Can't infer System F types ('∀a. a → a'), only check them")},
+		},
+	})
+}
+
+func TestTypingInferTypeLet(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"let-bound identity is polymorphic",
+			typeOf,
+			[]any{"let id = λx. x in 〈id 1, id true〉"},
+			[]any{"int × bool", nil},
+		},
+		{
+			"λ-bound identity isn't",
+			typeOf,
+			[]any{"(λid. 〈id 1, id true〉) (λx. x)"},
+			[]any{"", fmt.Errorf("Cannot unify 'int' with 'bool'")},
+		},
+		{
+			"variables free in the context aren't generalized",
+			typeOf,
+			[]any{"λy. let f = λx. y in 〈f 1, f true〉"},
+			[]any{"a → a × a", nil},
+		},
+		{
+			"annotation",
+			typeOf,
+			[]any{"let x = inl * : unit ⊕ int in x"},
+			[]any{"unit ⊕ int", nil},
+		},
+		{
+			"annotation mismatch",
+			typeOf,
+			[]any{"let x = 1 : bool in x"},
+			[]any{"", fmt.Errorf("'x' declared as 'bool', got 'int'")},
+		},
+	})
+}
+
+func churchTypeOf(s string) (string, error) {
+	t, err := InferType(testutil.Church.Bind(testutil.MustParse(s)))
+	if err != nil {
+		return "", headline(err)
+	}
+	return t.String(), nil
+}
+
+func TestTypingInferTypeChurch(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"booleans",
+			churchTypeOf,
+			[]any{"T"},
+			[]any{"a → b → a", nil},
+		},
+		{
+			"numerals",
+			churchTypeOf,
+			[]any{"two"},
+			[]any{"(a → a) → a → a", nil},
+		},
+		{
+			"successor",
+			churchTypeOf,
+			[]any{"succ"},
+			[]any{"((a → b) → c → a) → (a → b) → c → b", nil},
+		},
+		{
+			"numerals, used at different types",
+			churchTypeOf,
+			[]any{"add two (mult three four)"},
+			[]any{"(a → a) → a → a", nil},
+		},
+		{
+			"fixed-point combinator",
+			churchTypeOf,
+			[]any{"TFP"},
+			[]any{"", fmt.Errorf("t0 occurs in t0 → t2")},
+		},
+	})
+}
+
+// W's type error for s, where Church encodings are available,
+// with its explanation
+func explainType(s string) string {
+	_, err := InferType(testutil.Church.Bind(testutil.MustParse(s)))
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestTypingExplain(t *testing.T) {
+	ftests.Run(t, []ftests.Test{
+		{
+			"unification steps, application, definition",
+			explainType,
+			[]any{"let f = λx. x + 1 in f (λy. y)"},
+			[]any{"Cannot unify 'int' with 't2 → t2'\n" +
+				"\tnote: in 'f (λy. y)'\n" +
+				"\tnote: while unifying 'int → int' with '(t2 → t2) → t3'\n" +
+				"\tnote: applying 'f' ('int → int') to 'λy. y' ('t2 → t2')\n" +
+				"\tnote: 'f' defined as 'λx. x + 1'"},
+		},
+		{
+			"within a Church encoding",
+			explainType,
+			[]any{"succ true"},
+			[]any{"Cannot unify '(t7 → t8) → t9 → t7' with 'bool'\n" +
+				"\tnote: in 'succ true'\n" +
+				"\tnote: while unifying '((t7 → t8) → t9 → t7) → (t7 → t8) → t9 → t8' with 'bool → t10'\n" +
+				"\tnote: applying 'succ' ('((t7 → t8) → t9 → t7) → (t7 → t8) → t9 → t8') to 'true' ('bool')\n" +
+				"\tnote: 'succ' defined as 'λn. λf. λx. f (n f x)'"},
+		},
+		{
+			"within a definition",
+			explainType,
+			[]any{"let g = λx. (x + 1) true in g"},
+			[]any{"Cannot unify 'int' with 'bool → t1'\n" +
+				"\tnote: in '(x + 1) true'\n" +
+				"\tnote: applying 'x + 1' ('int') to 'true' ('bool')\n" +
+				"\tnote: in the definition of 'g'"},
+		},
+		{
+			"ascriptions keep the unification steps",
+			explainType,
+			[]any{"(〈1, 2〉 : int × bool)"},
+			[]any{"Expecting 'int × bool', got 'int × int'\n" +
+				"\tnote: in '(〈1, 2〉 : int × bool)'\n" +
+				"\tnote: Cannot unify 'int' with 'bool'\n" +
+				"\tnote: while unifying 'int × int' with 'int × bool'"},
+		},
+	})
+}